@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timeparse"
+)
+
+var (
+	addFrom         string
+	addTo           string
+	addDuration     string
+	addAt           string
+	addTask         string
+	addComment      string
+	addTags         string
+	addAllowOverlap bool
+)
+
+var addCmd = &cobra.Command{
+	Use:   "add <project>",
+	Short: "Add a completed time entry retroactively",
+	Long: `Add a completed time entry for work that was not tracked live.
+
+The interval is given either by --from and --to, or by --duration combined
+with one of --from, --to or --at. Clock times such as 09:00 refer to the day
+given by --at (default today). Times accept natural forms such as 9am,
+"yesterday 14:30", "mon 9am" or now-15m.`,
+	Example: `  ttt add ECM --from 09:00 --to 10:30
+  ttt add ECM --duration 1h30m --at yesterday
+  ttt add ECM --duration 45m --at "2026-02-27 14:00" --task "Code review"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAdd,
+}
+
+func init() {
+	addCmd.Flags().StringVar(&addFrom, "from", "", "Start time, e.g. 09:00, 9am or \"mon 9am\"")
+	addCmd.Flags().StringVar(&addTo, "to", "", "End time, e.g. 10:30, 5pm or \"yesterday 17:00\"")
+	addCmd.Flags().StringVar(&addDuration, "duration", "", "Duration, e.g. 1h30m, 90m, 1.5h or 1:30")
+	addCmd.Flags().StringVar(&addAt, "at", "", "Day (yesterday, mon, YYYY-MM-DD) with optional start time; a bare day with --duration ends at the current time of day")
+	addCmd.Flags().StringVar(&addTask, "task", "", "Task description")
+	addCmd.Flags().StringVar(&addComment, "comment", "", "Optional comment")
+	addCmd.Flags().StringVar(&addTags, "tags", "", "Comma-separated tags")
+	addCmd.Flags().BoolVar(&addAllowOverlap, "allow-overlap", false, "Allow the entry to overlap existing entries")
+}
+
+func runAdd(cmd *cobra.Command, args []string) error {
+	project := args[0]
+	now := time.Now()
+
+	start, end, err := addInterval(now)
+	if err != nil {
+		fail(1, err)
+	}
+
+	store, err := openStore()
+	if err != nil {
+		fail(2, err)
+	}
+
+	project, registered, err := resolveProject(store, project)
+	if err != nil {
+		fail(exitCode(err), err)
+	}
+
+	if !addAllowOverlap {
+		existing, err := store.LoadRange(timecalc.StartOfDay(start), timecalc.EndOfDay(end))
+		if err != nil {
+			fail(2, err)
+		}
+		if conflicts := overlapping(existing, start, end, now); len(conflicts) > 0 {
+			var b strings.Builder
+			b.WriteString("entry overlaps existing entries:")
+			for _, c := range conflicts {
+				endStr := "ongoing"
+				if c.End != nil {
+					endStr = c.End.Format("15:04")
+				}
+				fmt.Fprintf(&b, "\n  %s %s–%s  %s  (%s)",
+					c.Start.Format("2006-01-02"), c.Start.Format("15:04"), endStr, c.Project, c.ID)
+			}
+			b.WriteString("\nUse --allow-overlap to add it anyway")
+			fail(1, errors.New(b.String()))
+		}
+	}
+
+	entry := model.Entry{
+		ID:      timecalc.GenerateID(start),
+		Project: project,
+		Tags:    defaultTags(registered),
+		Start:   start,
+		Source:  "manual",
+	}
+	if addTask != "" {
+		entry.Task = &addTask
+	}
+	if addComment != "" {
+		entry.Comment = &addComment
+	}
+	if addTags != "" {
+		entry.Tags = parseTags(addTags)
+	}
+
+	// stopEntry closes the entry and splits it at midnight when needed.
+	if err := stopEntry(store, &entry, start, end, nil); err != nil {
+		fail(2, err)
+	}
+
+	if structuredOutput() {
+		printOutput(entryOutput{Entry: entry})
+		return nil
+	}
+	fmt.Printf("Added entry for project %q: %s %s–%s (%s)\n",
+		project, start.Format("2006-01-02"), start.Format("15:04"), end.Format("15:04"),
+		timecalc.FormatDuration(int64(end.Sub(start).Seconds())))
+	return nil
+}
+
+// addInterval resolves the --from/--to/--duration/--at flags into a start
+// and end time.
+func addInterval(now time.Time) (time.Time, time.Time, error) {
+	day := timecalc.StartOfDay(now)
+	var atTime *time.Time
+	if addAt != "" {
+		if d, err := timeparse.Day(addAt, now); err == nil {
+			day = d
+		} else {
+			t, err := timeparse.Time(addAt, now)
+			if err != nil {
+				return time.Time{}, time.Time{}, fmt.Errorf("invalid --at: %w", err)
+			}
+			day = timecalc.StartOfDay(t)
+			atTime = &t
+		}
+	}
+
+	var start, end time.Time
+	var err error
+	if addFrom != "" {
+		if start, err = timeparse.TimeOn(addFrom, day, now); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --from: %w", err)
+		}
+	}
+	if addTo != "" {
+		if end, err = timeparse.TimeOn(addTo, day, now); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --to: %w", err)
+		}
+	}
+
+	var dur time.Duration
+	if addDuration != "" {
+		if dur, err = timeparse.Duration(addDuration); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --duration: %w", err)
+		}
+		if dur <= 0 {
+			return time.Time{}, time.Time{}, fmt.Errorf("--duration must be positive")
+		}
+	}
+
+	switch {
+	case addFrom != "" && addTo != "":
+		if addDuration != "" {
+			return time.Time{}, time.Time{}, fmt.Errorf("--duration cannot be combined with both --from and --to")
+		}
+	case addFrom != "" && addDuration != "":
+		end = start.Add(dur)
+	case addTo != "" && addDuration != "":
+		start = end.Add(-dur)
+	case addDuration != "":
+		if atTime != nil {
+			start = *atTime
+			end = start.Add(dur)
+		} else {
+			clock := now.Sub(timecalc.StartOfDay(now))
+			end = day.Add(clock)
+			start = end.Add(-dur)
+		}
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("specify --from and --to, or --duration")
+	}
+
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end %s must be after start %s",
+			end.Format("2006-01-02 15:04"), start.Format("2006-01-02 15:04"))
+	}
+	if end.After(now) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end %s is in the future", end.Format("2006-01-02 15:04"))
+	}
+	return start, end, nil
+}
+
+// overlapping returns the entries whose interval intersects [start, end).
+// Open entries are treated as running until now.
+func overlapping(entries []model.Entry, start, end, now time.Time) []model.Entry {
+	var out []model.Entry
+	for _, e := range entries {
+		eEnd := now
+		if e.End != nil {
+			eEnd = *e.End
+		}
+		if e.Start.Before(end) && start.Before(eEnd) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
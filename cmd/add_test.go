@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
+)
+
+func TestOverlapping(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2026, 2, 27, h, m, 0, 0, time.UTC) }
+	end := at(10, 0)
+	entries := []model.Entry{
+		{ID: "before", Start: at(8, 0), End: ptrTime(at(9, 0))},
+		{ID: "inside", Start: at(9, 15), End: ptrTime(at(9, 45))},
+		{ID: "open", Start: at(11, 0)},
+		{ID: "adjacent", Start: end, End: ptrTime(at(10, 30))},
+	}
+
+	got := overlapping(entries, at(9, 0), end, at(12, 0))
+	if len(got) != 1 || got[0].ID != "inside" {
+		t.Errorf("overlapping = %v, want only %q", got, "inside")
+	}
+
+	got = overlapping(entries, at(11, 30), at(11, 45), at(12, 0))
+	if len(got) != 1 || got[0].ID != "open" {
+		t.Errorf("overlapping with open entry = %v, want only %q", got, "open")
+	}
+}
+
+func TestStopEntrySplitsMultipleDays(t *testing.T) {
+	store := storage.NewMemoryStore()
+	start := time.Date(2026, 2, 26, 22, 0, 0, 0, time.UTC)
+	stop := time.Date(2026, 2, 28, 1, 0, 0, 0, time.UTC)
+	entry := model.Entry{ID: "e1", Project: "P", Tags: []string{}, Start: start, Source: "manual"}
+
+	if err := stopEntry(store, &entry, start, stop, nil); err != nil {
+		t.Fatalf("stopEntry: %v", err)
+	}
+
+	entries, err := store.LoadRange(timecalc.StartOfDay(start), stop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d segments, want 3", len(entries))
+	}
+	var total int64
+	for _, e := range entries {
+		total += *e.DurationSeconds
+	}
+	// 2h on the first day, a full day in the middle and 1h on the last day,
+	// minus one second per 23:59:59 cut.
+	if want := int64(27*3600 - 2); total != want {
+		t.Errorf("total duration = %d, want %d", total, want)
+	}
+}
+
+func ptrTime(t time.Time) *time.Time { return &t }
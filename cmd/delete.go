@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var deleteLast bool
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete [id|prefix]",
+	Short: "Delete a time entry (recoverable from the trash)",
+	Example: `  ttt delete 20260227-083210-x82ks
+  ttt delete --last`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDelete,
+}
+
+func init() {
+	deleteCmd.Flags().BoolVar(&deleteLast, "last", false, "Delete the most recently started entry")
+}
+
+func runDelete(cmd *cobra.Command, args []string) error {
+	var ref string
+	switch {
+	case deleteLast && len(args) == 0:
+		ref = "last"
+	case !deleteLast && len(args) == 1:
+		ref = args[0]
+	default:
+		fail(1, errors.New("specify either an entry ID or --last"))
+	}
+
+	store, err := openStore()
+	if err != nil {
+		fail(2, err)
+	}
+
+	loc, err := resolveEntry(store, ref)
+	if err != nil {
+		fail(exitCode(err), err)
+	}
+
+	if _, err := store.TrashEntry(loc.Day, loc.Entry.ID, time.Now()); err != nil {
+		fail(2, err)
+	}
+
+	if structuredOutput() {
+		printOutput(entryOutput{Entry: loc.Entry})
+		return nil
+	}
+	fmt.Printf("Deleted entry %s (%s, %s %s).\n", loc.Entry.ID, loc.Entry.Project,
+		loc.Entry.Start.Format("2006-01-02"), loc.Entry.Start.Format("15:04"))
+	fmt.Printf("Restore it with: ttt trash restore %s\n", loc.Entry.ID)
+	return nil
+}
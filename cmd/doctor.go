@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/doctor"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+)
+
+var (
+	doctorFix         bool
+	doctorInteractive bool
+	doctorJSON        bool
+)
+
+// doctorPasses bounds how often --fix re-checks, since one repair can reveal
+// another (for example moving an entry to a day where it has a duplicate ID).
+const doctorPasses = 5
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check stored data for inconsistencies",
+	Long: `Check the whole history for inconsistencies:
+
+  unparsable_file    a data file that is not valid JSON
+  temp_file          a temp file left behind by an interrupted write
+  corrupt_file       a backup of a file that failed to parse
+  wrong_day          an entry stored under a day other than its start day
+  duplicate_id       several entries of one day sharing an ID
+  duration_mismatch  duration_seconds differing from end - start - breaks
+  end_before_start   an entry ending before it starts
+  multiple_open      an open entry other than the running timer
+  overlap            entries sharing tracked time (see ttt overlaps)
+  unknown_source     a source other than manual or outlook
+
+--fix repairs everything with an unambiguous fix: temp files are deleted,
+unparsable files are moved aside to <name>.corrupt, entries are moved to
+their start day, duplicate IDs are renamed, durations are recomputed and
+stale open entries are stopped where the next entry starts. --interactive
+asks before each repair. The remaining issues have to be resolved by hand,
+e.g. with ttt edit.
+
+Exits with code 1 when unresolved issues remain.`,
+	Example: `  ttt doctor
+  ttt doctor --fix
+  ttt doctor --interactive
+  ttt doctor --json`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Repair all issues that have an automatic fix")
+	doctorCmd.Flags().BoolVarP(&doctorInteractive, "interactive", "i", false, "Ask before each repair")
+	doctorCmd.Flags().BoolVar(&doctorJSON, "json", false, "Output the report as JSON, like --output json")
+}
+
+// doctorReport is the structured output of ttt doctor.
+type doctorReport struct {
+	Issues []doctor.Issue `json:"issues"`
+	Fixed  int            `json:"fixed"`
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	store, err := openStore()
+	if err != nil {
+		fail(2, err)
+	}
+	defer closeStore(store)
+
+	var ask func(doctor.Issue) bool
+	if doctorInteractive {
+		in := bufio.NewReader(os.Stdin)
+		ask = func(i doctor.Issue) bool { return confirmRepair(in, os.Stderr, i) }
+	}
+
+	issues, fixed, err := diagnose(store, time.Now(), doctorFix || doctorInteractive, ask)
+	if err != nil {
+		fail(2, err)
+	}
+
+	if doctorJSON || structuredOutput() {
+		if issues == nil {
+			issues = []doctor.Issue{}
+		}
+		printOutput(doctorReport{Issues: issues, Fixed: fixed})
+	} else {
+		printDoctorReport(os.Stdout, issues, fixed)
+	}
+	if len(issues) > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// diagnose checks store and, when fix is set, repairs fixable issues and
+// checks again until nothing changes. ask, if not nil, confirms each repair;
+// declined issues are not offered again. It returns the remaining issues and
+// the number of repairs made.
+func diagnose(store storage.Store, now time.Time, fix bool, ask func(doctor.Issue) bool) ([]doctor.Issue, int, error) {
+	issues, err := doctor.Check(store, now)
+	if err != nil || !fix {
+		return issues, 0, err
+	}
+
+	fixed := 0
+	declined := map[string]bool{}
+	for pass := 0; pass < doctorPasses; pass++ {
+		// Repairs capture the entry as it was checked, so each entry is
+		// repaired at most once per pass and checked again before the next.
+		touched := map[string]bool{}
+		for _, i := range issues {
+			key := i.Kind + "\x00" + i.Path + "\x00" + i.Day + "\x00" + i.EntryID
+			target := i.Path + "\x00" + i.Day + "\x00" + i.EntryID
+			if !i.Fixable() || declined[key] || touched[target] {
+				continue
+			}
+			if ask != nil && !ask(i) {
+				declined[key] = true
+				continue
+			}
+			if err := i.Repair(); err != nil {
+				return nil, fixed, err
+			}
+			fixed++
+			touched[target] = true
+		}
+		if len(touched) == 0 {
+			break
+		}
+		if issues, err = doctor.Check(store, now); err != nil {
+			return nil, fixed, err
+		}
+	}
+	return issues, fixed, nil
+}
+
+// confirmRepair describes i on out and reads a yes/no answer from in.
+func confirmRepair(in *bufio.Reader, out io.Writer, i doctor.Issue) bool {
+	fmt.Fprintf(out, "%s\n  fix: %s? [y/N] ", describeIssue(i), i.Fix)
+	line, _ := in.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// describeIssue formats i as a single line naming where it was found.
+func describeIssue(i doctor.Issue) string {
+	where := i.Path
+	if i.EntryID != "" {
+		where = i.Day + " " + i.EntryID
+	}
+	return fmt.Sprintf("%-17s  %s  %s", i.Kind, where, i.Message)
+}
+
+func printDoctorReport(out io.Writer, issues []doctor.Issue, fixed int) {
+	if fixed > 0 {
+		fmt.Fprintf(out, "Fixed %d issue(s).\n", fixed)
+	}
+	if len(issues) == 0 {
+		fmt.Fprintln(out, "No problems found.")
+		return
+	}
+	fixable := 0
+	for _, i := range issues {
+		fmt.Fprintln(out, describeIssue(i))
+		if i.Fixable() {
+			fixable++
+		}
+	}
+	fmt.Fprintf(out, "\n%d issue(s) found", len(issues))
+	if fixable > 0 {
+		fmt.Fprintf(out, ", %d can be repaired with: ttt doctor --fix", fixable)
+	}
+	fmt.Fprintln(out, ".")
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/doctor"
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+)
+
+func TestDiagnoseRepairsAcrossPasses(t *testing.T) {
+	day := time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local)
+	next := day.AddDate(0, 0, 1)
+	end := next.Add(time.Hour)
+	wrong := int64(5)
+	// Filed under the wrong day and with a wrong duration: the duration can
+	// only be fixed once the entry has been moved.
+	e := model.Entry{ID: "a", Project: "P", Start: next, End: &end, DurationSeconds: &wrong, Source: "manual"}
+
+	newStore := func() storage.Store {
+		s := storage.NewMemoryStore()
+		if err := s.UpdateEntry(day, e); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	issues, fixed, err := diagnose(newStore(), end, false, nil)
+	if err != nil || fixed != 0 || len(issues) != 2 {
+		t.Fatalf("check only: %d issues, %d fixed, %v; want 2, 0", len(issues), fixed, err)
+	}
+
+	s := newStore()
+	issues, fixed, err = diagnose(s, end, true, nil)
+	if err != nil || fixed != 2 || len(issues) != 0 {
+		t.Fatalf("fix: %v issues, %d fixed, %v; want none, 2", issues, fixed, err)
+	}
+	df, _ := s.LoadDay(next)
+	if len(df.Entries) != 1 || *df.Entries[0].DurationSeconds != 3600 {
+		t.Errorf("entries on %s = %+v, want a with 3600s", next.Format("2006-01-02"), df.Entries)
+	}
+
+	asked := 0
+	decline := func(doctor.Issue) bool { asked++; return false }
+	issues, fixed, err = diagnose(newStore(), end, true, decline)
+	if err != nil || fixed != 0 || len(issues) != 2 || asked != 2 {
+		t.Errorf("declined: %d issues, %d fixed, asked %d times, %v; want 2, 0, 2", len(issues), fixed, asked, err)
+	}
+}
@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timeparse"
+)
+
+var (
+	editProject string
+	editTask    string
+	editComment string
+	editTags    string
+	editStart   string
+	editEnd     string
+	editEditor  bool
+)
+
+var editCmd = &cobra.Command{
+	Use:   "edit <id|prefix|last>",
+	Short: "Modify an existing time entry",
+	Long: `Modify an existing time entry, identified by its full ID, a unique ID
+prefix or "last" for the most recently started entry.
+
+Clock times given to --start and --end refer to the entry's current day.
+Pass an empty --task or --comment to clear the field. With --editor the
+entry is opened as JSON in $VISUAL or $EDITOR and validated on save.`,
+	Example: `  ttt edit last --project ECM
+  ttt edit 20260227-0832 --start 08:15 --end 09:00
+  ttt edit last --editor`,
+	Args: cobra.ExactArgs(1),
+	RunE: runEdit,
+}
+
+func init() {
+	editCmd.Flags().StringVar(&editProject, "project", "", "New project name")
+	editCmd.Flags().StringVar(&editTask, "task", "", "New task description (empty clears it)")
+	editCmd.Flags().StringVar(&editComment, "comment", "", "New comment (empty clears it)")
+	editCmd.Flags().StringVar(&editTags, "tags", "", "New comma-separated tags (empty clears them)")
+	editCmd.Flags().StringVar(&editStart, "start", "", "New start time, e.g. 08:15 or \"yesterday 9am\"")
+	editCmd.Flags().StringVar(&editEnd, "end", "", "New end time, e.g. 17:30 or 5:30pm")
+	editCmd.Flags().BoolVar(&editEditor, "editor", false, "Open the entry JSON in $EDITOR")
+}
+
+func runEdit(cmd *cobra.Command, args []string) error {
+	store, err := openStore()
+	if err != nil {
+		fail(2, err)
+	}
+
+	loc, err := resolveEntry(store, args[0])
+	if err != nil {
+		fail(exitCode(err), err)
+	}
+	entry := loc.Entry
+	day := timecalc.StartOfDay(entry.Start)
+
+	flags := cmd.Flags()
+	if flags.Changed("project") {
+		entry.Project = strings.TrimSpace(editProject)
+		if entry.Project != "" {
+			if entry.Project, _, err = resolveProject(store, entry.Project); err != nil {
+				fail(exitCode(err), err)
+			}
+		}
+	}
+	if flags.Changed("task") {
+		entry.Task = optionalString(editTask)
+	}
+	if flags.Changed("comment") {
+		entry.Comment = optionalString(editComment)
+	}
+	if flags.Changed("tags") {
+		entry.Tags = parseTags(editTags)
+	}
+	if flags.Changed("start") {
+		t, err := timeparse.TimeOn(editStart, day, time.Now())
+		if err != nil {
+			fail(1, fmt.Errorf("invalid --start: %w", err))
+		}
+		entry.Start = t
+	}
+	if flags.Changed("end") {
+		t, err := timeparse.TimeOn(editEnd, day, time.Now())
+		if err != nil {
+			fail(1, fmt.Errorf("invalid --end: %w", err))
+		}
+		entry.End = &t
+	}
+
+	if editEditor {
+		edited, err := editInEditor(entry)
+		if err != nil {
+			fail(1, err)
+		}
+		entry = edited
+	}
+
+	recomputeDuration(&entry)
+	if err := validateEntry(entry, loc.Entry.ID); err != nil {
+		fail(1, err)
+	}
+
+	if err := moveEntry(store, loc.Day, entry); err != nil {
+		fail(2, err)
+	}
+
+	if structuredOutput() {
+		printOutput(entryOutput{Entry: entry})
+		return nil
+	}
+	endStr := "ongoing"
+	if entry.End != nil {
+		endStr = entry.End.Format("15:04")
+	}
+	fmt.Printf("Updated entry %s: %s %s–%s  %s\n",
+		entry.ID, entry.Start.Format("2006-01-02"), entry.Start.Format("15:04"), endStr, entry.Project)
+	return nil
+}
+
+// resolveEntry looks up an entry by full ID, unique ID prefix or "last".
+// Lookup failures are returned as user errors.
+func resolveEntry(store storage.Store, ref string) (storage.Located, error) {
+	if ref == "last" {
+		e, day, err := storage.LastEntry(store)
+		if err != nil {
+			return storage.Located{}, err
+		}
+		if e == nil {
+			return storage.Located{}, userError{"no entries found"}
+		}
+		return storage.Located{Entry: *e, Day: day}, nil
+	}
+
+	matches, err := storage.FindByPrefix(store, ref)
+	if err != nil {
+		return storage.Located{}, err
+	}
+	switch len(matches) {
+	case 0:
+		return storage.Located{}, userError{fmt.Sprintf("no entry matches %q", ref)}
+	case 1:
+		return matches[0], nil
+	default:
+		var b strings.Builder
+		fmt.Fprintf(&b, "%q is ambiguous; it matches %d entries:", ref, len(matches))
+		for _, m := range matches {
+			fmt.Fprintf(&b, "\n  %s  %s", m.Entry.ID, m.Entry.Project)
+		}
+		return storage.Located{}, userError{b.String()}
+	}
+}
+
+// moveEntry writes entry to the day of its start time, removing it from
+// prevDay first when the start date changed.
+func moveEntry(store storage.Store, prevDay time.Time, entry model.Entry) error {
+	if !timecalc.SameDay(prevDay, entry.Start) {
+		if _, err := store.RemoveEntry(prevDay, entry.ID); err != nil {
+			return err
+		}
+	}
+	return store.UpdateEntry(entry.Start, entry)
+}
+
+// optionalString returns nil for an empty string and a pointer otherwise.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// recomputeDuration sets DurationSeconds from Start, End and breaks.
+func recomputeDuration(e *model.Entry) {
+	if e.End == nil {
+		e.DurationSeconds = nil
+		return
+	}
+	dur := model.WorkedSeconds(e.Start, *e.End, e.Breaks)
+	e.DurationSeconds = &dur
+}
+
+// validateEntry checks an edited entry before it is written back. The ID must
+// not change, because other entries and external syncs refer to it.
+func validateEntry(e model.Entry, originalID string) error {
+	if e.ID != originalID {
+		return fmt.Errorf("the entry ID cannot be changed (was %q, now %q)", originalID, e.ID)
+	}
+	if strings.TrimSpace(e.Project) == "" {
+		return fmt.Errorf("project must not be empty")
+	}
+	if e.Start.IsZero() {
+		return fmt.Errorf("start time is required")
+	}
+	if e.End != nil {
+		if !e.End.After(e.Start) {
+			return fmt.Errorf("end %s must be after start %s",
+				e.End.Format("2006-01-02 15:04"), e.Start.Format("2006-01-02 15:04"))
+		}
+		if !timecalc.SameDay(e.Start, *e.End) {
+			return fmt.Errorf("start and end must be on the same day; use ttt add for entries spanning midnight")
+		}
+	}
+	for i, b := range e.Breaks {
+		if b.Start.Before(e.Start) || (e.End != nil && b.Start.After(*e.End)) {
+			return fmt.Errorf("break %d starts outside the entry", i+1)
+		}
+		if b.End == nil {
+			if e.End != nil || i != len(e.Breaks)-1 {
+				return fmt.Errorf("break %d has no end", i+1)
+			}
+			continue
+		}
+		if !b.End.After(b.Start) || (e.End != nil && b.End.After(*e.End)) {
+			return fmt.Errorf("break %d must end after it starts and within the entry", i+1)
+		}
+	}
+	if e.Source == "" {
+		return fmt.Errorf("source must not be empty")
+	}
+	return nil
+}
+
+// editInEditor writes the entry as JSON to a temp file, opens it in the
+// user's editor and parses the result.
+func editInEditor(e model.Entry) (model.Entry, error) {
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return e, err
+	}
+
+	f, err := os.CreateTemp("", "ttt-entry-*.json")
+	if err != nil {
+		return e, fmt.Errorf("creating temp file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return e, fmt.Errorf("writing temp file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return e, fmt.Errorf("writing temp file: %w", err)
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	parts := strings.Fields(editor)
+	c := exec.Command(parts[0], append(parts[1:], f.Name())...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return e, fmt.Errorf("running editor %q: %w", editor, err)
+	}
+
+	edited, err := os.ReadFile(f.Name())
+	if err != nil {
+		return e, fmt.Errorf("reading edited entry: %w", err)
+	}
+	if bytes.Equal(bytes.TrimSpace(edited), bytes.TrimSpace(data)) {
+		return e, nil
+	}
+
+	var out model.Entry
+	dec := json.NewDecoder(bytes.NewReader(edited))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&out); err != nil {
+		return e, fmt.Errorf("edited entry is not valid JSON: %w", err)
+	}
+	if out.Tags == nil {
+		out.Tags = []string{}
+	}
+	// Keep times in the local zone so the day file is chosen consistently.
+	out.Start = out.Start.In(time.Local)
+	if out.End != nil {
+		end := out.End.In(time.Local)
+		out.End = &end
+	}
+	return out, nil
+}
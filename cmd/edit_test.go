@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+)
+
+func TestValidateEntry(t *testing.T) {
+	start := time.Date(2026, 2, 27, 9, 0, 0, 0, time.UTC)
+	valid := model.Entry{ID: "e1", Project: "P", Start: start, End: ptrTime(start.Add(time.Hour)), Source: "manual"}
+
+	if err := validateEntry(valid, "e1"); err != nil {
+		t.Errorf("validateEntry(valid): %v", err)
+	}
+
+	tests := map[string]func(e *model.Entry){
+		"changed ID":     func(e *model.Entry) { e.ID = "other" },
+		"empty project":  func(e *model.Entry) { e.Project = " " },
+		"end before":     func(e *model.Entry) { e.End = ptrTime(start.Add(-time.Minute)) },
+		"crosses day":    func(e *model.Entry) { e.End = ptrTime(start.Add(24 * time.Hour)) },
+		"missing source": func(e *model.Entry) { e.Source = "" },
+	}
+	for name, mutate := range tests {
+		e := valid
+		mutate(&e)
+		if err := validateEntry(e, "e1"); err == nil {
+			t.Errorf("validateEntry(%s): expected error, got nil", name)
+		}
+	}
+}
+
+func TestResolveEntry(t *testing.T) {
+	store := storage.NewMemoryStore()
+	day1 := time.Date(2026, 2, 26, 9, 0, 0, 0, time.Local)
+	day2 := time.Date(2026, 2, 27, 9, 0, 0, 0, time.Local)
+	for _, e := range []model.Entry{
+		{ID: "20260226-090000-aaaaa", Project: "A", Tags: []string{}, Start: day1, Source: "manual"},
+		{ID: "20260227-090000-bbbbb", Project: "B", Tags: []string{}, Start: day2, Source: "manual"},
+		{ID: "20260227-090000-bcccc", Project: "C", Tags: []string{}, Start: day2.Add(time.Hour), Source: "manual"},
+	} {
+		if err := store.UpdateEntry(e.Start, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := resolveEntry(store, "20260226")
+	if err != nil || got.Entry.Project != "A" {
+		t.Errorf("resolveEntry(prefix) = %+v, %v; want project A", got.Entry, err)
+	}
+
+	got, err = resolveEntry(store, "last")
+	if err != nil || got.Entry.Project != "C" {
+		t.Errorf("resolveEntry(last) = %+v, %v; want project C", got.Entry, err)
+	}
+
+	if _, err := resolveEntry(store, "20260227-090000-b"); err == nil || exitCode(err) != 1 {
+		t.Errorf("resolveEntry(ambiguous) err = %v, want user error", err)
+	}
+	if _, err := resolveEntry(store, "nope"); err == nil || exitCode(err) != 1 {
+		t.Errorf("resolveEntry(missing) err = %v, want user error", err)
+	}
+}
@@ -1,20 +1,20 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/Tiliavir/trivial-time-tracker/internal/model"
-	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
-	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
 )
 
-var exportFormat string
+var (
+	exportFormat string
+	exportRange  rangeFlags
+	exportFilter filterFlags
+)
 
 var exportCmd = &cobra.Command{
 	Use:   "export",
@@ -25,40 +25,53 @@ var exportCmd = &cobra.Command{
 
 func init() {
 	exportCmd.Flags().StringVar(&exportFormat, "format", "csv", "Output format: csv, json, md")
+	exportRange.register(exportCmd)
+	exportFilter.register(exportCmd.Flags())
 }
 
 func runExport(cmd *cobra.Command, args []string) error {
 	now := time.Now()
 
-	base, err := storage.BaseDir()
+	store, err := openStore()
+	if err != nil {
+		fail(2, err)
+	}
+
+	// Default to this week when no range is given.
+	r, err := exportRange.resolve(now, weekRange)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(2)
+		fail(1, err)
 	}
 
-	from, to := timecalc.WeekRange(now)
+	keep, err := exportFilter.build(now)
+	if err != nil {
+		fail(1, err)
+	}
 
-	entries, err := storage.LoadRange(base, from, to)
+	entries, err := loadEntries(store, r)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(2)
+		fail(2, err)
 	}
+	entries = filterEntries(entries, keep)
 
-	switch exportFormat {
+	printEntries(entries, formatFor(cmd, exportFormat))
+	return nil
+}
+
+// printEntries prints entries as json, md (the list layout) or csv. With
+// --output yaml, json is printed as YAML.
+func printEntries(entries []model.Entry, format string) {
+	switch format {
 	case "json":
-		data, err := json.MarshalIndent(entries, "", "  ")
-		if err != nil {
-			fmt.Fprintln(os.Stderr, "error encoding JSON:", err)
-			os.Exit(2)
+		if entries == nil {
+			entries = []model.Entry{}
 		}
-		fmt.Println(string(data))
+		printOutput(entries)
 	case "md":
 		printList(entries)
 	default: // csv
 		printCSV(entries)
 	}
-
-	return nil
 }
 
 func printCSV(entries []model.Entry) {
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/query"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timeparse"
+)
+
+// filterFlags holds the entry filters shared by list, report and export.
+type filterFlags struct {
+	projects    []string
+	task        string
+	tags        []string
+	tagMatch    string
+	notTags     []string
+	source      string
+	text        string
+	minDuration string
+	where       string
+}
+
+// entryPredicate reports whether an entry should be kept.
+type entryPredicate func(e model.Entry) bool
+
+// register adds the filter flags to fs.
+func (f *filterFlags) register(fs *pflag.FlagSet) {
+	fs.StringArrayVar(&f.projects, "project", nil, "Only entries of this project (repeatable)")
+	fs.StringVar(&f.task, "task", "", "Only entries whose task contains this text")
+	fs.StringArrayVar(&f.tags, "tag", nil, "Only entries with this tag (repeatable, comma-separated)")
+	fs.StringVar(&f.tagMatch, "tag-match", "any", "Whether entries need any or all of the --tag tags: any, all")
+	fs.StringArrayVar(&f.notTags, "not-tag", nil, "Exclude entries with this tag (repeatable, comma-separated)")
+	fs.StringVar(&f.source, "source", "", "Only entries from this source: manual, outlook")
+	fs.StringVar(&f.text, "text", "", "Only entries whose task or comment contains this text")
+	fs.StringVar(&f.minDuration, "min-duration", "", "Only entries at least this long, e.g. 15m; a running entry counts until now")
+	fs.StringVar(&f.where, "where", "", "Only entries matching a query, e.g. 'tags contains \"api\" and duration > 30m'; running entries have no duration")
+}
+
+// build validates the flags and returns a predicate matching entries that
+// pass every given filter. Text matches are case-insensitive. Running
+// entries count as lasting until now.
+func (f *filterFlags) build(now time.Time) (entryPredicate, error) {
+	var preds []entryPredicate
+
+	if len(f.projects) > 0 {
+		projects := f.projects
+		preds = append(preds, func(e model.Entry) bool {
+			for _, p := range projects {
+				if model.SameProject(e.Project, p) {
+					return true
+				}
+			}
+			return false
+		})
+	}
+
+	if f.task != "" {
+		task := strings.ToLower(f.task)
+		preds = append(preds, func(e model.Entry) bool {
+			return e.Task != nil && strings.Contains(strings.ToLower(*e.Task), task)
+		})
+	}
+
+	if tags := splitTagFlags(f.tags); len(tags) > 0 {
+		switch f.tagMatch {
+		case "any":
+			preds = append(preds, func(e model.Entry) bool {
+				for _, t := range tags {
+					if hasTag(e, t) {
+						return true
+					}
+				}
+				return false
+			})
+		case "all":
+			preds = append(preds, func(e model.Entry) bool {
+				for _, t := range tags {
+					if !hasTag(e, t) {
+						return false
+					}
+				}
+				return true
+			})
+		default:
+			return nil, userError{fmt.Sprintf("invalid --tag-match %q: expected any or all", f.tagMatch)}
+		}
+	}
+
+	if notTags := splitTagFlags(f.notTags); len(notTags) > 0 {
+		preds = append(preds, func(e model.Entry) bool {
+			for _, t := range notTags {
+				if hasTag(e, t) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+
+	switch f.source {
+	case "":
+	case "manual", "outlook":
+		source := f.source
+		preds = append(preds, func(e model.Entry) bool { return e.Source == source })
+	default:
+		return nil, userError{fmt.Sprintf("invalid --source %q: expected manual or outlook", f.source)}
+	}
+
+	if f.text != "" {
+		text := strings.ToLower(f.text)
+		preds = append(preds, func(e model.Entry) bool {
+			return (e.Task != nil && strings.Contains(strings.ToLower(*e.Task), text)) ||
+				(e.Comment != nil && strings.Contains(strings.ToLower(*e.Comment), text))
+		})
+	}
+
+	if f.minDuration != "" {
+		d, err := timeparse.Duration(f.minDuration)
+		if err != nil {
+			return nil, userError{fmt.Sprintf("invalid --min-duration: %v", err)}
+		}
+		min := int64(d.Seconds())
+		preds = append(preds, func(e model.Entry) bool { return entrySeconds(e, now) >= min })
+	}
+
+	if f.where != "" {
+		q, err := query.Compile(f.where, now)
+		if err != nil {
+			return nil, queryError("--where", err)
+		}
+		preds = append(preds, q.Match)
+	}
+
+	return func(e model.Entry) bool {
+		for _, p := range preds {
+			if !p(e) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// filterEntries returns the entries for which keep returns true.
+func filterEntries(entries []model.Entry, keep entryPredicate) []model.Entry {
+	var out []model.Entry
+	for _, e := range entries {
+		if keep(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// entrySeconds returns the worked duration of e, counting running entries
+// until now.
+func entrySeconds(e model.Entry, now time.Time) int64 {
+	if e.DurationSeconds != nil {
+		return *e.DurationSeconds
+	}
+	return model.WorkedSeconds(e.Start, now, e.Breaks)
+}
+
+// hasTag reports whether e carries tag, ignoring case.
+func hasTag(e model.Entry, tag string) bool {
+	for _, t := range e.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitTagFlags flattens repeated, comma-separated tag flag values.
+func splitTagFlags(values []string) []string {
+	var tags []string
+	for _, v := range values {
+		tags = append(tags, parseTags(v)...)
+	}
+	return tags
+}
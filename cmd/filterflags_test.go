@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+)
+
+func TestFilterFlags(t *testing.T) {
+	now := time.Date(2026, 2, 27, 12, 0, 0, 0, time.UTC)
+	comment := "Talked about the API gateway"
+	entries := []model.Entry{
+		reportEntry("ECM", "REST refactor", 23, 60, "backend", "api"),
+		reportEntry("ecm ", "Code review", 24, 10, "backend"),
+		reportEntry("Admin", "Timesheets", 24, 30),
+		{Project: "Meetings", Start: now.Add(-45 * time.Minute), Tags: []string{"outlook"}, Source: "outlook", Comment: &comment},
+	}
+	entries[0].Source = "manual"
+
+	ids := func(es []model.Entry) []string {
+		var out []string
+		for _, e := range es {
+			out = append(out, e.Project)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name  string
+		flags filterFlags
+		want  int
+	}{
+		{"none", filterFlags{tagMatch: "any"}, 4},
+		{"project folds case", filterFlags{projects: []string{"ECM"}, tagMatch: "any"}, 2},
+		{"task substring", filterFlags{task: "review", tagMatch: "any"}, 1},
+		{"any tag", filterFlags{tags: []string{"api,outlook"}, tagMatch: "any"}, 2},
+		{"all tags", filterFlags{tags: []string{"backend", "API"}, tagMatch: "all"}, 1},
+		{"not tag", filterFlags{notTags: []string{"backend"}, tagMatch: "any"}, 2},
+		{"source", filterFlags{source: "outlook", tagMatch: "any"}, 1},
+		{"text in comment", filterFlags{text: "gateway", tagMatch: "any"}, 1},
+		{"min duration counts running", filterFlags{minDuration: "30m", tagMatch: "any"}, 3},
+		{"combined", filterFlags{projects: []string{"ecm"}, tags: []string{"backend"}, minDuration: "15m", tagMatch: "any"}, 1},
+	}
+	for _, tt := range tests {
+		keep, err := tt.flags.build(now)
+		if err != nil {
+			t.Errorf("%s: build: %v", tt.name, err)
+			continue
+		}
+		if got := filterEntries(entries, keep); len(got) != tt.want {
+			t.Errorf("%s: kept %v, want %d entries", tt.name, ids(got), tt.want)
+		}
+	}
+}
+
+func TestFilterFlagsInvalid(t *testing.T) {
+	now := time.Now()
+	for _, f := range []filterFlags{
+		{tags: []string{"x"}, tagMatch: "some"},
+		{source: "jira", tagMatch: "any"},
+		{minDuration: "soon", tagMatch: "any"},
+	} {
+		if _, err := f.build(now); err == nil || exitCode(err) != 1 {
+			t.Errorf("build(%+v) error = %v, want user error", f, err)
+		}
+	}
+}
@@ -2,19 +2,17 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/Tiliavir/trivial-time-tracker/internal/model"
-	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
 	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
 )
 
 var (
-	listToday bool
-	listWeek  bool
+	listRange  rangeFlags
+	listFilter filterFlags
 )
 
 var listCmd = &cobra.Command{
@@ -25,39 +23,60 @@ var listCmd = &cobra.Command{
 }
 
 func init() {
-	listCmd.Flags().BoolVar(&listToday, "today", false, "Show today's entries")
-	listCmd.Flags().BoolVar(&listWeek, "week", false, "Show this week's entries")
+	listRange.register(listCmd)
+	listFilter.register(listCmd.Flags())
 }
 
 func runList(cmd *cobra.Command, args []string) error {
 	now := time.Now()
 
-	base, err := storage.BaseDir()
+	store, err := openStore()
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(2)
+		fail(2, err)
 	}
 
-	var from, to time.Time
-	switch {
-	case listWeek:
-		from, to = timecalc.WeekRange(now)
-	default:
-		// Default to today (covers --today and the bare command).
-		from = timecalc.StartOfDay(now)
-		to = timecalc.EndOfDay(now)
+	// Default to today when no range is given.
+	r, err := listRange.resolve(now, dayRange)
+	if err != nil {
+		fail(1, err)
 	}
 
-	entries, err := storage.LoadRange(base, from, to)
+	keep, err := listFilter.build(now)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(2)
+		fail(1, err)
 	}
 
+	entries, err := loadEntries(store, r)
+	if err != nil {
+		fail(2, err)
+	}
+	entries = filterEntries(entries, keep)
+
+	if structuredOutput() {
+		out := listOutput{periodOutput: newPeriodOutput(r), Entries: entries}
+		if out.Entries == nil {
+			out.Entries = []model.Entry{}
+		}
+		for _, e := range entries {
+			if e.DurationSeconds != nil {
+				out.TotalSeconds += *e.DurationSeconds
+			}
+		}
+		printOutput(out)
+		return nil
+	}
 	printList(entries)
 	return nil
 }
 
+// listOutput is the structured output of ttt list. TotalSeconds counts
+// stopped entries only.
+type listOutput struct {
+	periodOutput
+	Entries      []model.Entry `json:"entries"`
+	TotalSeconds int64         `json:"total_seconds"`
+}
+
 // printList groups entries by date and prints them.
 func printList(entries []model.Entry) {
 	if len(entries) == 0 {
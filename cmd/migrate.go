@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/config"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+)
+
+var migrateDryRun bool
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade data files to the current schema version",
+	Long: `Rewrite day files and ~/.ttt/config.json written by an older ttt in the
+current format. Older files are upgraded in memory whenever they are read, so
+this is optional; it only makes the files on disk match what ttt writes.
+Comments in config.json are kept.
+
+Files written by a newer ttt are never touched: ttt refuses to read them
+until it is upgraded. To move data to another storage backend, use
+ttt storage migrate instead.`,
+	Example: `  ttt migrate
+  ttt migrate --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runMigrate,
+}
+
+func init() {
+	migrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "List the files that would be upgraded")
+}
+
+// migrateOutput is the structured output of ttt migrate. Config is set when
+// the config file was upgraded; Days lists the upgraded day files.
+type migrateOutput struct {
+	DryRun bool     `json:"dry_run"`
+	Config bool     `json:"config"`
+	Days   []string `json:"days"`
+}
+
+func runMigrate(cmd *cobra.Command, args []string) error {
+	changed, err := config.Upgrade(ws.ConfigFile, migrateDryRun)
+	if err != nil {
+		fail(2, err)
+	}
+
+	store, err := openStore()
+	if err != nil {
+		fail(2, err)
+	}
+	defer closeStore(store)
+
+	var upgraded []time.Time
+	// Other backends keep their schema version in the database and upgrade
+	// it when opened.
+	if js, ok := store.(*storage.JSONStore); ok {
+		if upgraded, err = js.UpgradeFiles(migrateDryRun); err != nil {
+			fail(2, err)
+		}
+	}
+
+	if structuredOutput() {
+		out := migrateOutput{DryRun: migrateDryRun, Config: changed, Days: []string{}}
+		for _, d := range upgraded {
+			out.Days = append(out.Days, d.Format("2006-01-02"))
+		}
+		printOutput(out)
+		return nil
+	}
+
+	verb := "Upgraded"
+	if migrateDryRun {
+		verb = "Would upgrade"
+	}
+	if changed {
+		fmt.Printf("%s config.json to schema version %d.\n", verb, config.SchemaVersion)
+	}
+	for _, d := range upgraded {
+		fmt.Printf("%s %s\n", verb, d.Format("2006/01/02.json"))
+	}
+	if len(upgraded) > 0 {
+		fmt.Printf("%s %d day file(s) to schema version %d.\n", verb, len(upgraded), storage.SchemaVersion)
+	}
+	if !changed && len(upgraded) == 0 {
+		fmt.Println("Everything is up to date.")
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/outlook"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
+)
+
+var (
+	syncDate     string
+	syncFrom     string
+	syncTo       string
+	syncToday    bool
+	syncDryRun   bool
+	syncProject  string
+	syncTimezone string
+)
+
+var outlookCmd = &cobra.Command{
+	Use:   "outlook",
+	Short: "Outlook calendar integration",
+}
+
+var outlookSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Import Outlook calendar events as time entries",
+	Args:  cobra.NoArgs,
+	RunE:  runOutlookSync,
+}
+
+func init() {
+	outlookSyncCmd.Flags().StringVar(&syncDate, "date", "", "Sync a single day (YYYY-MM-DD)")
+	outlookSyncCmd.Flags().StringVar(&syncFrom, "from", "", "Range start (YYYY-MM-DD, required when --to is set)")
+	outlookSyncCmd.Flags().StringVar(&syncTo, "to", "", "Range end (YYYY-MM-DD, defaults to today)")
+	outlookSyncCmd.Flags().BoolVar(&syncToday, "today", false, "Sync today (default)")
+	outlookSyncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Print planned operations without writing")
+	outlookSyncCmd.Flags().StringVar(&syncProject, "project", "", "Project for imported events (default from config)")
+	outlookSyncCmd.Flags().StringVar(&syncTimezone, "timezone", "", "IANA timezone for event times (default from config)")
+	outlookCmd.AddCommand(outlookSyncCmd)
+}
+
+func runOutlookSync(cmd *cobra.Command, args []string) error {
+	tzName := cfg.Outlook.Timezone
+	if syncTimezone != "" {
+		tzName = syncTimezone
+	}
+	loc := time.UTC
+	if tzName != "" {
+		l, err := time.LoadLocation(tzName)
+		if err != nil {
+			fail(1, fmt.Errorf("invalid timezone %q: %v", tzName, err))
+		}
+		loc = l
+	}
+
+	project := cfg.Outlook.DefaultProject
+	if syncProject != "" {
+		project = syncProject
+	}
+	if project == "" {
+		project = "Meetings"
+	}
+
+	from, to, err := syncRange(time.Now().In(loc))
+	if err != nil {
+		fail(1, err)
+	}
+
+	store, err := openStore()
+	if err != nil {
+		fail(2, err)
+	}
+
+	ctx := context.Background()
+	auth := &outlook.Authenticator{
+		TenantID:  cfg.Outlook.TenantID,
+		ClientID:  cfg.Outlook.ClientID,
+		TokenPath: filepath.Join(ws.DataDir, "auth", "msgraph_tokens.json"),
+		Prompt:    os.Stderr,
+	}
+	token, err := auth.AccessToken(ctx)
+	if err != nil {
+		fail(1, fmt.Errorf("authentication failed: %w", err))
+	}
+
+	dryRunLabel := ""
+	if syncDryRun {
+		dryRunLabel = " [dry-run]"
+	}
+	// say prints progress in text mode; structured output is printed at the
+	// end.
+	say := func(format string, a ...any) {
+		if !structuredOutput() {
+			fmt.Printf(format, a...)
+		}
+	}
+	say("Syncing Outlook events (%s → %s)%s...\n\n",
+		from.Format("2006-01-02"), to.Format("2006-01-02"), dryRunLabel)
+
+	client := &outlook.Client{AccessToken: token}
+	events, err := client.CalendarView(ctx, from, timecalc.Midnight(to), tzName)
+	if err != nil {
+		fail(1, err)
+	}
+
+	existing, err := store.LoadRange(from, to)
+	if err != nil {
+		fail(2, err)
+	}
+
+	results, err := outlook.Plan(events, existing, project, loc)
+	if err != nil {
+		fail(1, err)
+	}
+
+	out := outlookSyncOutput{DryRun: syncDryRun, From: from.Format("2006-01-02"), To: to.Format("2006-01-02"), Events: []syncEvent{}}
+	for _, r := range results {
+		ev := syncEvent{Subject: r.Event.Subject}
+		switch r.Action {
+		case outlook.ActionImport:
+			out.Imported++
+			ev.Action, ev.Entry = "imported", &r.Entry
+			say("  ✓ Imported: %s (%s)\n", r.Event.Subject,
+				timecalc.FormatDuration(*r.Entry.DurationSeconds))
+			if !syncDryRun {
+				if err := store.UpdateEntry(r.Entry.Start, r.Entry); err != nil {
+					fail(2, err)
+				}
+			}
+		case outlook.ActionUpdate:
+			out.Updated++
+			ev.Action, ev.Entry = "updated", &r.Entry
+			var prevDur int64
+			if r.Previous.DurationSeconds != nil {
+				prevDur = *r.Previous.DurationSeconds
+			}
+			say("  ↑ Updated:  %s (%s → %s)\n", r.Event.Subject,
+				timecalc.FormatDuration(prevDur), timecalc.FormatDuration(*r.Entry.DurationSeconds))
+			if !syncDryRun {
+				if err := moveEntry(store, r.Previous.Start, r.Entry); err != nil {
+					fail(2, err)
+				}
+			}
+		default:
+			out.Skipped++
+			ev.Action, ev.Reason = "skipped", r.Reason
+			say("  – Skipped:  %s (%s)\n", r.Event.Subject, r.Reason)
+		}
+		out.Events = append(out.Events, ev)
+	}
+
+	if structuredOutput() {
+		printOutput(out)
+		return nil
+	}
+	fmt.Println()
+	fmt.Println("Summary:")
+	fmt.Printf("  %d imported\n", out.Imported)
+	fmt.Printf("  %d skipped\n", out.Skipped)
+	fmt.Printf("  %d updated\n", out.Updated)
+	return nil
+}
+
+// outlookSyncOutput is the structured output of ttt outlook sync.
+type outlookSyncOutput struct {
+	DryRun   bool        `json:"dry_run"`
+	From     string      `json:"from"`
+	To       string      `json:"to"`
+	Events   []syncEvent `json:"events"`
+	Imported int         `json:"imported"`
+	Skipped  int         `json:"skipped"`
+	Updated  int         `json:"updated"`
+}
+
+// syncEvent is the outcome for one calendar event: imported, updated or
+// skipped. Entry is the entry written, Reason why the event was skipped.
+type syncEvent struct {
+	Action  string       `json:"action"`
+	Subject string       `json:"subject"`
+	Reason  string       `json:"reason,omitempty"`
+	Entry   *model.Entry `json:"entry,omitempty"`
+}
+
+// syncRange resolves the --date/--from/--to/--today flags into an inclusive
+// day range in the location of now.
+func syncRange(now time.Time) (time.Time, time.Time, error) {
+	parse := func(flag, v string) (time.Time, error) {
+		return parseDateFlag(flag, v, now)
+	}
+
+	switch {
+	case syncDate != "":
+		if syncFrom != "" || syncTo != "" {
+			return time.Time{}, time.Time{}, fmt.Errorf("--date cannot be combined with --from/--to")
+		}
+		d, err := parse("date", syncDate)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		return timecalc.StartOfDay(d), timecalc.EndOfDay(d), nil
+	case syncFrom != "" || syncTo != "":
+		if syncFrom == "" {
+			return time.Time{}, time.Time{}, fmt.Errorf("--from is required when --to is set")
+		}
+		f, err := parse("from", syncFrom)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		t := now
+		if syncTo != "" {
+			if t, err = parse("to", syncTo); err != nil {
+				return time.Time{}, time.Time{}, err
+			}
+		}
+		if t.Before(f) {
+			return time.Time{}, time.Time{}, fmt.Errorf("--to must not be before --from")
+		}
+		return timecalc.StartOfDay(f), timecalc.EndOfDay(t), nil
+	default:
+		return timecalc.StartOfDay(now), timecalc.EndOfDay(now), nil
+	}
+}
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+)
+
+// Formats accepted by --output.
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputYAML = "yaml"
+)
+
+// output is the value of the global --output flag.
+var output = outputFormat(outputText)
+
+// outputFormat is a pflag.Value that rejects unknown formats while the
+// command line is parsed, before any command runs.
+type outputFormat string
+
+func (f *outputFormat) String() string { return string(*f) }
+
+func (f *outputFormat) Type() string { return "format" }
+
+func (f *outputFormat) Set(s string) error {
+	switch s {
+	case outputText, outputJSON, outputYAML:
+		*f = outputFormat(s)
+		return nil
+	}
+	return fmt.Errorf("invalid output format %q: use text, json or yaml", s)
+}
+
+// structuredOutput reports whether --output asks for json or yaml instead
+// of prose.
+func structuredOutput() bool {
+	return output == outputJSON || output == outputYAML
+}
+
+// errorOutput is the structured form of an error, written to stderr.
+type errorOutput struct {
+	Error errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	// Code is the exit code: 1 for user errors, 2 for storage errors.
+	Code int `json:"code"`
+	// Type is "user_error" or "storage_error".
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// fail reports err on stderr and exits with code, which is 1 for user
+// errors and 2 for storage errors. With --output json or yaml the error is
+// written as an errorOutput object.
+func fail(code int, err error) {
+	if structuredOutput() {
+		typ := "storage_error"
+		if code == 1 {
+			typ = "user_error"
+		}
+		if werr := writeOutput(os.Stderr, errorOutput{errorDetail{Code: code, Type: typ, Message: err.Error()}}); werr == nil {
+			os.Exit(code)
+		}
+	}
+	fmt.Fprintln(os.Stderr, "Error:", err)
+	os.Exit(code)
+}
+
+// warnings receives the warnings of commands. ttt ui collects them for its
+// status line instead of writing over the screen it draws.
+var warnings io.Writer = os.Stderr
+
+// warn writes a warning line to warnings.
+func warn(format string, args ...any) {
+	fmt.Fprintf(warnings, "Warning: "+format+"\n", args...)
+}
+
+// printOutput writes v to stdout as JSON, or as YAML with --output yaml.
+func printOutput(v any) {
+	if err := writeOutput(os.Stdout, v); err != nil {
+		fail(2, err)
+	}
+}
+
+// jsonObject is a JSON object that keeps its fields in order. Reports use
+// it because their keys depend on the grouping.
+type jsonObject []jsonField
+
+type jsonField struct {
+	Key   string
+	Value any
+}
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func writeOutput(w io.Writer, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding output: %w", err)
+	}
+	return writeJSON(w, data)
+}
+
+func writeJSON(w io.Writer, data []byte) error {
+	if output == outputYAML {
+		y, err := jsonToYAML(data)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(y)
+		return err
+	}
+	_, err := fmt.Fprintln(w, string(bytes.TrimRight(data, "\n")))
+	return err
+}
+
+// jsonToYAML converts a JSON document to block-style YAML. Going through
+// JSON keeps the field names and order of the json struct tags, so both
+// formats share one documented structure.
+func jsonToYAML(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("encoding YAML: %w", err)
+	}
+	var plain func(n *yaml.Node)
+	plain = func(n *yaml.Node) {
+		n.Style = 0
+		for _, c := range n.Content {
+			plain(c)
+		}
+	}
+	plain(&doc)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("encoding YAML: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encoding YAML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// entryOutput is the structured output of commands that create or change a
+// single entry.
+type entryOutput struct {
+	Entry model.Entry `json:"entry"`
+	// Stopped is the running timer auto-stopped by start or resume.
+	Stopped *model.Entry `json:"stopped,omitempty"`
+}
+
+// formatFor returns the format to use for a --format flag: json whenever
+// --output asks for structured output. Other formats cannot be combined
+// with it.
+func formatFor(cmd *cobra.Command, format string) string {
+	if !structuredOutput() {
+		return format
+	}
+	if cmd.Flags().Changed("format") && format != outputJSON {
+		fail(1, fmt.Errorf("--format %s cannot be combined with --output %s", format, output))
+	}
+	return outputJSON
+}
+
+// countOutput is the structured output of commands that only report how
+// many items they changed.
+type countOutput struct {
+	Count int `json:"count"`
+}
+
+// periodOutput describes the date range of a command in structured output,
+// with the same fields as the header of a JSON report.
+type periodOutput struct {
+	Week   string `json:"week,omitempty"`
+	Period string `json:"period"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+}
+
+func newPeriodOutput(r dateRange) periodOutput {
+	p := periodOutput{Week: r.Week, Period: r.Label}
+	if !r.All {
+		p.From, p.To = r.From.Format("2006-01-02"), r.To.Format("2006-01-02")
+	}
+	return p
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestOutputFormatSet(t *testing.T) {
+	var f outputFormat
+	for _, s := range []string{"text", "json", "yaml"} {
+		if err := f.Set(s); err != nil || string(f) != s {
+			t.Errorf("Set(%q) = %v, format %q", s, err, f)
+		}
+	}
+	for _, s := range []string{"", "JSON", "xml"} {
+		if err := f.Set(s); err == nil {
+			t.Errorf("Set(%q) succeeded", s)
+		}
+	}
+}
+
+func TestWriteOutput(t *testing.T) {
+	defer func(prev outputFormat) { output = prev }(output)
+	v := struct {
+		ID      string   `json:"id"`
+		Task    *string  `json:"task"`
+		Seconds int64    `json:"seconds"`
+		Tags    []string `json:"tags"`
+	}{ID: "20260227", Seconds: 90, Tags: []string{"a", "b"}}
+
+	tests := []struct {
+		format outputFormat
+		want   string
+	}{
+		{outputJSON, "{\n  \"id\": \"20260227\",\n  \"task\": null,\n  \"seconds\": 90,\n  \"tags\": [\n    \"a\",\n    \"b\"\n  ]\n}\n"},
+		// Strings that would read as numbers stay quoted.
+		{outputYAML, "id: \"20260227\"\ntask: null\nseconds: 90\ntags:\n  - a\n  - b\n"},
+	}
+	for _, tt := range tests {
+		output = tt.format
+		var buf bytes.Buffer
+		if err := writeOutput(&buf, v); err != nil {
+			t.Fatalf("%s: %v", tt.format, err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%s output:\n%s\nwant:\n%s", tt.format, buf.String(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/overlap"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
+)
+
+var (
+	overlapsRange    rangeFlags
+	overlapsResolve  string
+	overlapsPriority []string
+	overlapsDryRun   bool
+)
+
+// overlapsPasses bounds how often --resolve looks for conflicts again, since
+// an entry overlapping several others is changed once per pass.
+const overlapsPasses = 20
+
+var overlapsCmd = &cobra.Command{
+	Use:   "overlaps",
+	Short: "List and resolve entries covering the same time",
+	Long: `List pairs of entries whose tracked time overlaps, e.g. a manual entry
+and an imported Outlook meeting. Time within a break does not count.
+
+--resolve settles every conflict with one strategy:
+
+  trim-earlier  the earlier entry ends where the later one starts; any part
+                of it after the later entry is dropped
+  trim-later    the later entry starts where the earlier one ends
+  split         the earlier entry is cut around the later one, keeping the
+                parts before and after it
+  priority      the entry whose source comes first in --priority keeps its
+                time and the other one is cut around it
+
+An entry covered completely is moved to the trash. Conflicts with the
+running timer are skipped. Note that ttt outlook sync restores the times of
+trimmed Outlook entries, so prefer strategies that let them win.`,
+	Example: `  ttt overlaps --week
+  ttt overlaps --month --resolve priority
+  ttt overlaps --resolve priority --priority manual,outlook
+  ttt overlaps --resolve split --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runOverlaps,
+}
+
+func init() {
+	overlapsRange.register(overlapsCmd)
+	overlapsCmd.Flags().Lookup("week").Usage = "This week (default)"
+	overlapsCmd.Flags().StringVar(&overlapsResolve, "resolve", "", "Resolve conflicts: trim-earlier, trim-later, split, priority")
+	overlapsCmd.Flags().StringSliceVar(&overlapsPriority, "priority", overlap.DefaultPriority, "Source ranking for --resolve priority, highest first")
+	overlapsCmd.Flags().BoolVar(&overlapsDryRun, "dry-run", false, "Print planned changes without writing")
+}
+
+func runOverlaps(cmd *cobra.Command, args []string) error {
+	now := time.Now()
+
+	store, err := openStore()
+	if err != nil {
+		fail(2, err)
+	}
+
+	r, err := overlapsRange.resolve(now, weekRange)
+	if err != nil {
+		fail(1, err)
+	}
+
+	var strategy overlap.Strategy
+	if overlapsResolve != "" {
+		if strategy, err = overlap.ParseStrategy(overlapsResolve); err != nil {
+			fail(1, err)
+		}
+	} else if overlapsDryRun {
+		fail(1, errors.New("--dry-run requires --resolve"))
+	}
+
+	entries, err := loadEntries(store, r)
+	if err != nil {
+		fail(2, err)
+	}
+
+	if strategy == "" {
+		conflicts := overlap.Find(entries, now)
+		if structuredOutput() {
+			printOutput(newOverlapsOutput(r, conflicts))
+			return nil
+		}
+		printOverlaps(conflicts)
+		return nil
+	}
+	if err := resolveOverlaps(store, r, entries, strategy, now); err != nil {
+		fail(2, err)
+	}
+	return nil
+}
+
+// overlapsOutput is the structured output of ttt overlaps without
+// --resolve.
+type overlapsOutput struct {
+	periodOutput
+	Overlaps []conflictOutput `json:"overlaps"`
+	// SharedSeconds is the time counted more than once in total.
+	SharedSeconds int64 `json:"shared_seconds"`
+}
+
+// conflictOutput is one pair of overlapping entries. Start and End bound
+// the shared time.
+type conflictOutput struct {
+	Earlier       model.Entry `json:"earlier"`
+	Later         model.Entry `json:"later"`
+	Start         time.Time   `json:"start"`
+	End           time.Time   `json:"end"`
+	SharedSeconds int64       `json:"shared_seconds"`
+}
+
+func newOverlapsOutput(r dateRange, conflicts []overlap.Conflict) overlapsOutput {
+	out := overlapsOutput{periodOutput: newPeriodOutput(r), Overlaps: []conflictOutput{}}
+	for _, c := range conflicts {
+		shared := int64(c.Shared.Seconds())
+		out.Overlaps = append(out.Overlaps, conflictOutput{Earlier: c.Earlier, Later: c.Later, Start: c.Start, End: c.End, SharedSeconds: shared})
+		out.SharedSeconds += shared
+	}
+	return out
+}
+
+func printOverlaps(conflicts []overlap.Conflict) {
+	if len(conflicts) == 0 {
+		fmt.Println("No overlapping entries found.")
+		return
+	}
+
+	var shared time.Duration
+	var currentDay string
+	for _, c := range conflicts {
+		day := c.Start.Format("2006-01-02")
+		if day != currentDay {
+			fmt.Println(day)
+			currentDay = day
+		}
+		span := fmt.Sprintf("%s–%s  %s", c.Start.Format("15:04"), c.End.Format("15:04"),
+			timecalc.FormatDuration(int64(c.Shared.Seconds())))
+		fmt.Printf("  %-20s %s\n", span, describeEntry(c.Earlier))
+		fmt.Printf("  %-20s %s\n", "", describeEntry(c.Later))
+		shared += c.Shared
+	}
+	fmt.Printf("\n%d overlap(s), %s counted more than once.\n", len(conflicts), timecalc.FormatDuration(int64(shared.Seconds())))
+	fmt.Println("Resolve with: ttt overlaps --resolve <trim-earlier|trim-later|split|priority>")
+}
+
+// describeEntry formats e as "<id>  <project>  <task>".
+func describeEntry(e model.Entry) string {
+	s := e.ID + "  " + e.Project
+	if e.Task != nil && *e.Task != "" {
+		s += "  " + *e.Task
+	}
+	return s
+}
+
+// resolveOverlaps applies strategy to the conflicts among entries, which were
+// loaded from r, and repeats with freshly loaded entries until none can be
+// resolved. Each entry is changed at most once per pass, since the conflicts
+// found for it refer to its previous times.
+func resolveOverlaps(store storage.Store, r dateRange, entries []model.Entry, strategy overlap.Strategy, now time.Time) error {
+	if overlapsDryRun {
+		// Work on a copy, so the preview includes the changes of later passes.
+		mem := storage.NewMemoryStore()
+		for _, e := range entries {
+			if err := mem.UpdateEntry(e.Start, e); err != nil {
+				return err
+			}
+		}
+		store = mem
+	}
+
+	out := resolveOutput{DryRun: overlapsDryRun, Changes: []changeOutput{}, Skipped: []skipOutput{}}
+	skipped := map[string]bool{}
+	for pass := 0; pass < overlapsPasses; pass++ {
+		touched := map[string]bool{}
+		for _, c := range overlap.Find(entries, now) {
+			if touched[c.Earlier.ID] || touched[c.Later.ID] {
+				continue
+			}
+			ch, err := overlap.Resolve(c, strategy, overlapsPriority)
+			if err != nil {
+				key := c.Earlier.ID + "\x00" + c.Later.ID
+				if !skipped[key] {
+					skipped[key] = true
+					out.Skipped = append(out.Skipped, skipOutput{Earlier: c.Earlier.ID, Later: c.Later.ID, Reason: err.Error()})
+					if !structuredOutput() {
+						fmt.Printf("Skipped %s and %s: %v\n", c.Earlier.ID, c.Later.ID, err)
+					}
+				}
+				continue
+			}
+			if !structuredOutput() {
+				printChange(ch)
+			}
+			if err := applyChange(store, ch, now); err != nil {
+				return err
+			}
+			touched[c.Earlier.ID], touched[c.Later.ID] = true, true
+			out.Changes = append(out.Changes, newChangeOutput(ch))
+		}
+		if len(touched) == 0 {
+			break
+		}
+		var err error
+		if entries, err = loadEntries(store, r); err != nil {
+			return err
+		}
+	}
+
+	changes := len(out.Changes)
+	switch {
+	case structuredOutput():
+		printOutput(out)
+	case changes == 0:
+		fmt.Println("Nothing to resolve.")
+	case overlapsDryRun:
+		fmt.Printf("Would make %d change(s). Run without --dry-run to apply them.\n", changes)
+	default:
+		fmt.Printf("Made %d change(s).\n", changes)
+	}
+	return nil
+}
+
+// resolveOutput is the structured output of ttt overlaps --resolve.
+type resolveOutput struct {
+	DryRun  bool           `json:"dry_run"`
+	Changes []changeOutput `json:"changes"`
+	// Skipped lists the conflicts the strategy could not resolve.
+	Skipped []skipOutput `json:"skipped"`
+}
+
+// changeOutput is one resolution. Action is trashed, trimmed or split: Entry
+// was moved to the trash or replaced by the entries in Result, making room
+// for the entry with the ID Winner.
+type changeOutput struct {
+	Action string        `json:"action"`
+	Entry  model.Entry   `json:"entry"`
+	Result []model.Entry `json:"result"`
+	Winner string        `json:"winner"`
+}
+
+type skipOutput struct {
+	Earlier string `json:"earlier"`
+	Later   string `json:"later"`
+	Reason  string `json:"reason"`
+}
+
+func newChangeOutput(ch overlap.Change) changeOutput {
+	out := changeOutput{Action: "split", Entry: ch.Entry, Result: ch.Result, Winner: ch.Winner.ID}
+	switch len(ch.Result) {
+	case 0:
+		out.Action, out.Result = "trashed", []model.Entry{}
+	case 1:
+		out.Action = "trimmed"
+	}
+	return out
+}
+
+func printChange(ch overlap.Change) {
+	verb := func(s string) string {
+		if overlapsDryRun {
+			return "Would " + strings.ToLower(s[:1]) + s[1:]
+		}
+		return s
+	}
+	span := func(e model.Entry) string {
+		return e.Start.Format("15:04") + "–" + e.End.Format("15:04")
+	}
+	switch len(ch.Result) {
+	case 0:
+		fmt.Printf("%s %s to the trash (covered by %s)\n", verb("Moved"), ch.Entry.ID, ch.Winner.ID)
+	case 1:
+		fmt.Printf("%s %s from %s to %s\n", verb("Trimmed"), ch.Entry.ID, span(ch.Entry), span(ch.Result[0]))
+	default:
+		var parts []string
+		for _, p := range ch.Result {
+			parts = append(parts, span(p))
+		}
+		fmt.Printf("%s %s into %s around %s\n", verb("Split"), ch.Entry.ID, strings.Join(parts, " and "), ch.Winner.ID)
+	}
+}
+
+// applyChange stores the result of a resolution. Entries are filed under
+// the day of their start time, so a part starting on another day moves.
+func applyChange(store storage.Store, ch overlap.Change, now time.Time) error {
+	day := ch.Entry.Start
+	if len(ch.Result) == 0 {
+		_, err := store.TrashEntry(day, ch.Entry.ID, now)
+		return err
+	}
+	for _, e := range ch.Result {
+		if err := store.UpdateEntry(e.Start, e); err != nil {
+			return err
+		}
+	}
+	// Drop the old copy only once the result is stored on its new day.
+	if !timecalc.SameDay(ch.Result[0].Start, day) {
+		if _, err := store.RemoveEntry(day, ch.Entry.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
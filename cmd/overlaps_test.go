@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/overlap"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+)
+
+func TestResolveOverlapsSplitsAroundSeveralEntries(t *testing.T) {
+	day := time.Date(2026, 2, 27, 0, 0, 0, 0, time.Local)
+	at := func(h int) time.Time { return day.Add(time.Duration(h) * time.Hour) }
+	entry := func(id string, from, to int) model.Entry {
+		end := at(to)
+		dur := int64(end.Sub(at(from)).Seconds())
+		return model.Entry{ID: id, Project: "P", Start: at(from), End: &end, DurationSeconds: &dur, Source: "manual"}
+	}
+
+	store := storage.NewMemoryStore()
+	for _, e := range []model.Entry{entry("long", 8, 16), entry("a", 9, 10), entry("b", 12, 13)} {
+		if err := store.UpdateEntry(day, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := dayRange(day)
+	entries, _ := loadEntries(store, r)
+	if err := resolveOverlaps(store, r, entries, overlap.Split, at(18)); err != nil {
+		t.Fatalf("resolveOverlaps: %v", err)
+	}
+
+	entries, _ = loadEntries(store, r)
+	if c := overlap.Find(entries, at(18)); len(c) != 0 {
+		t.Errorf("%d overlaps left, want none", len(c))
+	}
+	var total int64
+	for _, e := range entries {
+		total += *e.DurationSeconds
+	}
+	if len(entries) != 5 || total != 8*3600 {
+		t.Errorf("got %d entries totalling %ds, want 5 covering 8h", len(entries), total)
+	}
+}
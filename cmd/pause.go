@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
+)
+
+var (
+	pauseAt    string
+	pauseAgo   string
+	unpauseAt  string
+	unpauseAgo string
+)
+
+var pauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Pause the running timer",
+	Args:  cobra.NoArgs,
+	RunE:  runPause,
+}
+
+var unpauseCmd = &cobra.Command{
+	Use:   "unpause",
+	Short: "Continue the paused timer",
+	Args:  cobra.NoArgs,
+	RunE:  runUnpause,
+}
+
+func init() {
+	pauseCmd.Flags().StringVar(&pauseAt, "at", "", "Pause time, e.g. 12:03 or now-5m")
+	pauseCmd.Flags().StringVar(&pauseAgo, "ago", "", "Pause this long ago, e.g. 5m")
+	unpauseCmd.Flags().StringVar(&unpauseAt, "at", "", "Continue time, e.g. 12:30 or now-5m")
+	unpauseCmd.Flags().StringVar(&unpauseAgo, "ago", "", "Continue this long ago, e.g. 5m")
+}
+
+func runPause(cmd *cobra.Command, args []string) error {
+	now, err := resolveAt(time.Now(), pauseAt, pauseAgo)
+	if err != nil {
+		fail(1, err)
+	}
+
+	store, err := openStore()
+	if err != nil {
+		fail(2, err)
+	}
+
+	active, activeDay, err := store.FindActiveEntry()
+	if err != nil {
+		fail(2, err)
+	}
+	if active == nil {
+		fail(1, errors.New("no active timer to pause"))
+	}
+	if b := openBreak(active); b != nil {
+		fail(1, fmt.Errorf("timer is already paused since %s", b.Start.Format("15:04")))
+	}
+	if err := checkPauseTime(active, now); err != nil {
+		fail(1, err)
+	}
+
+	active.Breaks = append(active.Breaks, model.Break{Start: now})
+	if err := store.UpdateEntry(activeDay, *active); err != nil {
+		fail(2, err)
+	}
+
+	if structuredOutput() {
+		printOutput(entryOutput{Entry: *active})
+		return nil
+	}
+	fmt.Printf("Paused timer for project %q at %s\n", active.Project, now.Format("15:04:05"))
+	return nil
+}
+
+// unpauseOutput is the structured output of ttt unpause.
+type unpauseOutput struct {
+	Entry        model.Entry `json:"entry"`
+	BreakSeconds int64       `json:"break_seconds"`
+}
+
+func runUnpause(cmd *cobra.Command, args []string) error {
+	now, err := resolveAt(time.Now(), unpauseAt, unpauseAgo)
+	if err != nil {
+		fail(1, err)
+	}
+
+	store, err := openStore()
+	if err != nil {
+		fail(2, err)
+	}
+
+	active, activeDay, err := store.FindActiveEntry()
+	if err != nil {
+		fail(2, err)
+	}
+	if active == nil {
+		fail(1, errors.New("no active timer"))
+	}
+	b := openBreak(active)
+	if b == nil {
+		fail(1, errors.New("timer is not paused"))
+	}
+	if err := checkContinueTime(b, now); err != nil {
+		fail(1, err)
+	}
+
+	end := now
+	b.End = &end
+	if err := store.UpdateEntry(activeDay, *active); err != nil {
+		fail(2, err)
+	}
+
+	breakSeconds := int64(now.Sub(b.Start).Seconds())
+	if structuredOutput() {
+		printOutput(unpauseOutput{Entry: *active, BreakSeconds: breakSeconds})
+		return nil
+	}
+	fmt.Printf("Continued timer for project %q at %s after a %s break\n", active.Project,
+		now.Format("15:04:05"), timecalc.FormatDuration(breakSeconds))
+	return nil
+}
+
+// openBreak returns the entry's ongoing break, or nil if it is not paused.
+func openBreak(e *model.Entry) *model.Break {
+	if n := len(e.Breaks); n > 0 && e.Breaks[n-1].End == nil {
+		return &e.Breaks[n-1]
+	}
+	return nil
+}
+
+// lastActivity returns the latest start or break end of an entry, before
+// which no new break may begin.
+func lastActivity(e *model.Entry) time.Time {
+	t := e.Start
+	for _, b := range e.Breaks {
+		if b.End != nil && b.End.After(t) {
+			t = *b.End
+		}
+	}
+	return t
+}
+
+// checkPauseTime rejects a pause that would begin before the timer's last
+// activity.
+func checkPauseTime(active *model.Entry, now time.Time) error {
+	if t := lastActivity(active); !now.After(t) {
+		return fmt.Errorf("pause time must be after %s", t.Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+// checkContinueTime rejects ending the open break b at or before its start.
+func checkContinueTime(b *model.Break, now time.Time) error {
+	if !now.After(b.Start) {
+		return fmt.Errorf("continue time must be after the pause at %s", b.Start.Format("2006-01-02 15:04"))
+	}
+	return nil
+}
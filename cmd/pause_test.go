@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
+)
+
+func TestStopEntrySplitsBreakAcrossMidnight(t *testing.T) {
+	store := storage.NewMemoryStore()
+	start := time.Date(2026, 2, 26, 22, 0, 0, 0, time.UTC)
+	stop := time.Date(2026, 2, 27, 2, 0, 0, 0, time.UTC)
+	entry := model.Entry{
+		ID: "e1", Project: "P", Tags: []string{}, Start: start, Source: "manual",
+		Breaks: []model.Break{{
+			Start: time.Date(2026, 2, 26, 23, 30, 0, 0, time.UTC),
+			End:   ptrTime(time.Date(2026, 2, 27, 0, 30, 0, 0, time.UTC)),
+		}},
+	}
+
+	if err := stopEntry(store, &entry, start, stop, nil); err != nil {
+		t.Fatalf("stopEntry: %v", err)
+	}
+
+	entries, err := store.LoadRange(timecalc.StartOfDay(start), stop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d segments, want 2", len(entries))
+	}
+	// Day 1: 22:00–23:59:59 minus 23:30–23:59:59 = 1h30m.
+	if got := *entries[0].DurationSeconds; got != 90*60 {
+		t.Errorf("first segment duration = %d, want %d", got, 90*60)
+	}
+	// Day 2: 00:00–02:00 minus 00:00–00:30 = 1h30m.
+	if got := *entries[1].DurationSeconds; got != 90*60 {
+		t.Errorf("second segment duration = %d, want %d", got, 90*60)
+	}
+	if len(entries[0].Breaks) != 1 || len(entries[1].Breaks) != 1 {
+		t.Errorf("breaks per segment = %d, %d; want 1, 1", len(entries[0].Breaks), len(entries[1].Breaks))
+	}
+}
+
+func TestStopEntryWhilePaused(t *testing.T) {
+	store := storage.NewMemoryStore()
+	start := time.Date(2026, 2, 27, 9, 0, 0, 0, time.UTC)
+	pausedAt := start.Add(time.Hour)
+	entry := model.Entry{
+		ID: "e1", Project: "P", Tags: []string{}, Start: start, Source: "manual",
+		Breaks: []model.Break{{Start: pausedAt}},
+	}
+
+	if err := stopEntry(store, &entry, start, start.Add(3*time.Hour), nil); err != nil {
+		t.Fatalf("stopEntry: %v", err)
+	}
+	if !entry.End.Equal(pausedAt) {
+		t.Errorf("end = %v, want pause start %v", entry.End, pausedAt)
+	}
+	if len(entry.Breaks) != 0 {
+		t.Errorf("breaks = %v, want the open break to be dropped", entry.Breaks)
+	}
+	if *entry.DurationSeconds != 3600 {
+		t.Errorf("duration = %d, want 3600", *entry.DurationSeconds)
+	}
+}
+
+func TestStopEntryBeforePause(t *testing.T) {
+	store := storage.NewMemoryStore()
+	start := time.Date(2026, 2, 27, 9, 0, 0, 0, time.UTC)
+	bEnd := start.Add(30 * time.Minute)
+	entry := model.Entry{
+		ID: "e1", Project: "P", Tags: []string{}, Start: start, Source: "manual",
+		Breaks: []model.Break{
+			{Start: start.Add(15 * time.Minute), End: &bEnd},
+			{Start: start.Add(2 * time.Hour)},
+		},
+	}
+
+	// ttt stop --at 10:00 on a timer paused at 11:00.
+	stop := start.Add(time.Hour)
+	if err := stopEntry(store, &entry, start, stop, nil); err != nil {
+		t.Fatalf("stopEntry: %v", err)
+	}
+	if !entry.End.Equal(stop) {
+		t.Errorf("end = %v, want the requested %v", entry.End, stop)
+	}
+	if len(entry.Breaks) != 1 || !entry.Breaks[0].End.Equal(bEnd) {
+		t.Errorf("breaks = %+v, want only the closed one before the end", entry.Breaks)
+	}
+	if *entry.DurationSeconds != 45*60 {
+		t.Errorf("duration = %d, want 45m", *entry.DurationSeconds)
+	}
+}
+
+func TestStartEntryWhilePaused(t *testing.T) {
+	store := storage.NewMemoryStore()
+	start := time.Date(2026, 2, 27, 9, 0, 0, 0, time.UTC)
+	paused := model.Entry{
+		ID: "e1", Project: "OLD", Tags: []string{}, Start: start, Source: "manual",
+		Breaks: []model.Break{{Start: start.Add(2 * time.Hour)}},
+	}
+	if err := store.UpdateEntry(start, paused); err != nil {
+		t.Fatal(err)
+	}
+	prev := warnings
+	warnings = io.Discard
+	t.Cleanup(func() { warnings = prev })
+
+	// ttt start NEW --at 10:00 while OLD is paused since 11:00.
+	at := start.Add(time.Hour)
+	entry, stopped, err := startEntry(store, model.Entry{Project: "NEW"}, at)
+	if err != nil {
+		t.Fatalf("startEntry: %v", err)
+	}
+	if stopped == nil || !stopped.End.Equal(at) || len(stopped.Breaks) != 0 {
+		t.Errorf("stopped = %+v, want OLD ended at 10:00 without breaks", stopped)
+	}
+	if !entry.Start.Equal(at) {
+		t.Errorf("new entry starts at %v, want %v", entry.Start, at)
+	}
+}
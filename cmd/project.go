@@ -0,0 +1,448 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
+)
+
+var (
+	projectDescription string
+	projectClient      string
+	projectTags        string
+	projectBillable    bool
+	projectColour      string
+	projectListAll     bool
+	projectArchiveUndo bool
+)
+
+var projectCmd = &cobra.Command{
+	Use:   "project",
+	Short: "Manage the project registry",
+	Long: `Manage the project registry in ~/.ttt/projects.json.
+
+Registered projects carry metadata such as client, billable flag and default
+tags. Project names are matched case-insensitively, so "ecm" resolves to a
+registered "ECM". With "strict_projects": true in the config, start, add and
+edit only accept registered, non-archived projects.`,
+}
+
+var projectAddCmd = &cobra.Command{
+	Use:     "add <name>",
+	Short:   "Register a project",
+	Example: `  ttt project add ECM --client ACME --billable --tags backend --colour "#1e90ff"`,
+	Args:    cobra.ExactArgs(1),
+	RunE:    runProjectAdd,
+}
+
+var projectListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List registered projects",
+	Args:  cobra.NoArgs,
+	RunE:  runProjectList,
+}
+
+var projectShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show a project's metadata and tracked time",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProjectShow,
+}
+
+var projectRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a project in the registry and in all stored entries",
+	Long: `Rename a project in the registry and in every stored entry, including
+deleted entries in the trash. Entries are matched case-insensitively and
+ignoring surrounding whitespace, so renaming "ecm" to "ECM" also merges
+entries recorded as "ECM ".`,
+	Example: `  ttt project rename ecm ECM
+  ttt project rename "Old name" "New name"`,
+	Args: cobra.ExactArgs(2),
+	RunE: runProjectRename,
+}
+
+var projectArchiveCmd = &cobra.Command{
+	Use:   "archive <name>",
+	Short: "Archive a project so it is hidden and no longer offered",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProjectArchive,
+}
+
+func init() {
+	projectAddCmd.Flags().StringVar(&projectDescription, "description", "", "Project description")
+	projectAddCmd.Flags().StringVar(&projectClient, "client", "", "Client the project is billed to")
+	projectAddCmd.Flags().StringVar(&projectTags, "tags", "", "Comma-separated tags applied to new entries without --tags")
+	projectAddCmd.Flags().BoolVar(&projectBillable, "billable", false, "Mark the project as billable")
+	projectAddCmd.Flags().StringVar(&projectColour, "colour", "", "Colour, e.g. #1e90ff or blue")
+	projectListCmd.Flags().BoolVar(&projectListAll, "all", false, "Include archived projects")
+	projectArchiveCmd.Flags().BoolVar(&projectArchiveUndo, "undo", false, "Unarchive the project")
+	projectCmd.AddCommand(projectAddCmd)
+	projectCmd.AddCommand(projectListCmd)
+	projectCmd.AddCommand(projectShowCmd)
+	projectCmd.AddCommand(projectRenameCmd)
+	projectCmd.AddCommand(projectArchiveCmd)
+}
+
+func runProjectAdd(cmd *cobra.Command, args []string) error {
+	name := strings.TrimSpace(args[0])
+	if name == "" {
+		fail(1, errors.New("project name must not be empty"))
+	}
+	if projectColour != "" && !validColour(projectColour) {
+		fail(1, fmt.Errorf("invalid --colour %q: expected #rgb, #rrggbb or a colour name such as blue", projectColour))
+	}
+
+	store, projects := loadProjectsOrExit()
+	if p := findProject(projects, name); p != nil {
+		fail(1, fmt.Errorf("project %q is already registered", p.Name))
+	}
+
+	p := model.Project{
+		Name:        name,
+		Description: projectDescription,
+		Client:      projectClient,
+		Billable:    projectBillable,
+		Colour:      strings.ToLower(projectColour),
+	}
+	if projectTags != "" {
+		p.DefaultTags = parseTags(projectTags)
+	}
+	if err := store.SaveProjects(append(projects, p)); err != nil {
+		fail(2, err)
+	}
+	if structuredOutput() {
+		printOutput(p)
+		return nil
+	}
+	fmt.Printf("Registered project %q.\n", name)
+	return nil
+}
+
+func runProjectList(cmd *cobra.Command, args []string) error {
+	_, projects := loadProjectsOrExit()
+
+	if structuredOutput() {
+		shown := []model.Project{}
+		for _, p := range projects {
+			if !p.Archived || projectListAll {
+				shown = append(shown, p)
+			}
+		}
+		printOutput(shown)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	shown := 0
+	for _, p := range projects {
+		if p.Archived && !projectListAll {
+			continue
+		}
+		if shown == 0 {
+			fmt.Fprintln(w, "NAME\tCLIENT\tBILLABLE\tTAGS\tDESCRIPTION")
+		}
+		name := p.Name
+		if p.Archived {
+			name += " (archived)"
+		}
+		billable := "no"
+		if p.Billable {
+			billable = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", name, p.Client, billable, strings.Join(p.DefaultTags, ","), p.Description)
+		shown++
+	}
+	if shown == 0 {
+		fmt.Println("No projects registered. Add one with: ttt project add <name>")
+		return nil
+	}
+	return w.Flush()
+}
+
+// projectShowOutput is the structured output of ttt project show: the
+// registry record and usage statistics.
+type projectShowOutput struct {
+	model.Project
+	Entries        int    `json:"entries"`
+	TrackedSeconds int64  `json:"tracked_seconds"`
+	FirstUsed      string `json:"first_used,omitempty"`
+	LastUsed       string `json:"last_used,omitempty"`
+}
+
+func runProjectShow(cmd *cobra.Command, args []string) error {
+	store, projects := loadProjectsOrExit()
+	p := findProject(projects, args[0])
+	if p == nil {
+		fail(1, unknownProjectError(projects, args[0]))
+	}
+
+	entries, err := storage.LoadAll(store)
+	if err != nil {
+		fail(2, err)
+	}
+	var count int
+	var total int64
+	var first, last *model.Entry
+	for i := range entries {
+		e := &entries[i]
+		if !model.SameProject(e.Project, p.Name) {
+			continue
+		}
+		count++
+		if e.DurationSeconds != nil {
+			total += *e.DurationSeconds
+		}
+		if first == nil || e.Start.Before(first.Start) {
+			first = e
+		}
+		if last == nil || e.Start.After(last.Start) {
+			last = e
+		}
+	}
+
+	if structuredOutput() {
+		out := projectShowOutput{Project: *p, Entries: count, TrackedSeconds: total}
+		if first != nil {
+			out.FirstUsed, out.LastUsed = first.Start.Format("2006-01-02"), last.Start.Format("2006-01-02")
+		}
+		printOutput(out)
+		return nil
+	}
+
+	billable := "no"
+	if p.Billable {
+		billable = "yes"
+	}
+	fmt.Printf("Project:     %s\n", p.Name)
+	if p.Archived {
+		fmt.Println("Status:      archived")
+	}
+	if p.Description != "" {
+		fmt.Printf("Description: %s\n", p.Description)
+	}
+	if p.Client != "" {
+		fmt.Printf("Client:      %s\n", p.Client)
+	}
+	fmt.Printf("Billable:    %s\n", billable)
+	if len(p.DefaultTags) > 0 {
+		fmt.Printf("Tags:        %s\n", strings.Join(p.DefaultTags, ", "))
+	}
+	if p.Colour != "" {
+		fmt.Printf("Colour:      %s\n", p.Colour)
+	}
+	fmt.Printf("Entries:     %d\n", count)
+	fmt.Printf("Tracked:     %s\n", timecalc.FormatDuration(total))
+	if first != nil {
+		fmt.Printf("First used:  %s\n", first.Start.Format("2006-01-02"))
+		fmt.Printf("Last used:   %s\n", last.Start.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// projectRenameOutput is the structured output of ttt project rename.
+// Entries counts the renamed entries, including those in the trash.
+type projectRenameOutput struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Entries int    `json:"entries"`
+}
+
+func runProjectRename(cmd *cobra.Command, args []string) error {
+	oldName, newName := strings.TrimSpace(args[0]), strings.TrimSpace(args[1])
+	if newName == "" {
+		fail(1, errors.New("new project name must not be empty"))
+	}
+
+	store, projects := loadProjectsOrExit()
+	from := findProject(projects, oldName)
+	to := findProject(projects, newName)
+	switch {
+	case from != nil && to != nil && from != to:
+		fail(1, fmt.Errorf("project %q is already registered; archive one of them first", to.Name))
+	case from != nil:
+		from.Name = newName
+	case to != nil:
+		// Folding an unregistered name into a registered project keeps the
+		// registered spelling.
+		newName = to.Name
+	}
+
+	n, err := store.RenameProject(oldName, newName)
+	if err != nil {
+		fail(2, err)
+	}
+	if from != nil {
+		if err := store.SaveProjects(projects); err != nil {
+			fail(2, err)
+		}
+	}
+	if from == nil && n == 0 {
+		fail(1, unknownProjectError(projects, oldName))
+	}
+
+	if structuredOutput() {
+		printOutput(projectRenameOutput{From: oldName, To: newName, Entries: n})
+	} else {
+		fmt.Printf("Renamed project %q to %q (%d entries updated).\n", oldName, newName, n)
+	}
+	if model.SameProject(cfg.Outlook.DefaultProject, oldName) {
+		warn("outlook.default_project in the config still refers to %q", cfg.Outlook.DefaultProject)
+	}
+	return nil
+}
+
+func runProjectArchive(cmd *cobra.Command, args []string) error {
+	store, projects := loadProjectsOrExit()
+	p := findProject(projects, args[0])
+	if p == nil {
+		fail(1, unknownProjectError(projects, args[0]))
+	}
+	p.Archived = !projectArchiveUndo
+	if err := store.SaveProjects(projects); err != nil {
+		fail(2, err)
+	}
+	if structuredOutput() {
+		printOutput(*p)
+		return nil
+	}
+	if p.Archived {
+		fmt.Printf("Archived project %q.\n", p.Name)
+	} else {
+		fmt.Printf("Unarchived project %q.\n", p.Name)
+	}
+	return nil
+}
+
+// loadProjectsOrExit returns the store and its project registry, exiting
+// with a storage error if either cannot be opened or read.
+func loadProjectsOrExit() (storage.Store, []model.Project) {
+	store, err := openStore()
+	if err != nil {
+		fail(2, err)
+	}
+	projects, err := store.LoadProjects()
+	if err != nil {
+		fail(2, err)
+	}
+	return store, projects
+}
+
+// resolveProject maps a project name given on the command line to its
+// registered spelling and returns the registry record, if any. With
+// strict_projects enabled, unknown and archived projects are user errors;
+// otherwise unknown names are used as given, minus surrounding whitespace.
+func resolveProject(store storage.Store, name string) (string, *model.Project, error) {
+	name = strings.TrimSpace(name)
+	projects, err := store.LoadProjects()
+	if err != nil {
+		return name, nil, err
+	}
+	p := findProject(projects, name)
+	switch {
+	case p != nil && p.Archived && cfg.StrictProjects:
+		return name, nil, userError{fmt.Sprintf("project %q is archived; unarchive it with: ttt project archive --undo %q", p.Name, p.Name)}
+	case p != nil:
+		if p.Archived {
+			warn("project %q is archived", p.Name)
+		}
+		return p.Name, p, nil
+	case cfg.StrictProjects:
+		return name, nil, userError{fmt.Sprintf("%v\nRegister it with: ttt project add %q", unknownProjectError(projects, name), name)}
+	}
+	return name, nil, nil
+}
+
+// defaultTags returns a copy of p's default tags, or an empty list if p is
+// nil.
+func defaultTags(p *model.Project) []string {
+	if p == nil {
+		return []string{}
+	}
+	return append([]string{}, p.DefaultTags...)
+}
+
+// findProject returns the registered project matching name, or nil.
+func findProject(projects []model.Project, name string) *model.Project {
+	for i := range projects {
+		if model.SameProject(projects[i].Name, name) {
+			return &projects[i]
+		}
+	}
+	return nil
+}
+
+// unknownProjectError reports an unregistered project name together with
+// the closest registered name, if there is a plausible one.
+func unknownProjectError(projects []model.Project, name string) error {
+	msg := fmt.Sprintf("unknown project %q", name)
+	if s := suggestProject(projects, name); s != "" {
+		msg += fmt.Sprintf(" (did you mean %q?)", s)
+	}
+	return userError{msg}
+}
+
+// suggestProject returns the non-archived project name closest to name by
+// edit distance, or "" if none is close enough to be a likely typo.
+func suggestProject(projects []model.Project, name string) string {
+	needle := strings.ToLower(strings.TrimSpace(name))
+	best, bestDist := "", -1
+	for _, p := range projects {
+		if p.Archived {
+			continue
+		}
+		d := editDistance(needle, strings.ToLower(p.Name))
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = p.Name, d
+		}
+	}
+	if bestDist < 0 || (bestDist > 2 && bestDist > len(needle)/3) {
+		return ""
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+var hexColourRe = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// validColour reports whether c is a hex colour or a basic colour name.
+func validColour(c string) bool {
+	if hexColourRe.MatchString(c) {
+		return true
+	}
+	switch strings.ToLower(c) {
+	case "black", "red", "green", "yellow", "blue", "magenta", "cyan", "white", "grey", "gray", "orange", "purple":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+)
+
+func TestSuggestProject(t *testing.T) {
+	projects := []model.Project{
+		{Name: "ECM"},
+		{Name: "Meetings"},
+		{Name: "Infrastructure"},
+		{Name: "Legacy", Archived: true},
+	}
+	tests := []struct {
+		name, want string
+	}{
+		{"EMC", "ECM"},
+		{"meeting", "Meetings"},
+		{"Infrastructur", "Infrastructure"},
+		{"Legacy", ""},
+		{"Holiday", ""},
+	}
+	for _, tt := range tests {
+		if got := suggestProject(projects, tt.name); got != tt.want {
+			t.Errorf("suggestProject(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResolveProject(t *testing.T) {
+	store := storage.NewMemoryStore()
+	t.Cleanup(func() { cfg.StrictProjects = false })
+
+	projects := []model.Project{
+		{Name: "ECM", DefaultTags: []string{"backend"}},
+		{Name: "Old", Archived: true},
+	}
+	if err := store.SaveProjects(projects); err != nil {
+		t.Fatal(err)
+	}
+
+	name, p, err := resolveProject(store, " ecm ")
+	if err != nil || name != "ECM" || p == nil || p.DefaultTags[0] != "backend" {
+		t.Errorf("resolveProject(ecm) = %q, %+v, %v; want registered ECM", name, p, err)
+	}
+	if name, _, err := resolveProject(store, "New "); err != nil || name != "New" {
+		t.Errorf("resolveProject(New) = %q, %v; want New accepted", name, err)
+	}
+
+	cfg.StrictProjects = true
+	for _, n := range []string{"EMC", "Old"} {
+		_, _, err := resolveProject(store, n)
+		if err == nil || exitCode(err) != 1 {
+			t.Errorf("strict resolveProject(%q) error = %v, want user error", n, err)
+		}
+	}
+}
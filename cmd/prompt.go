@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/prompt"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+)
+
+// defaultPromptFormat shows the project, the task if set, the elapsed time
+// and whether the timer is paused.
+const defaultPromptFormat = "{project}[ {task}] {elapsed}[ ({paused})]"
+
+var (
+	promptFormat  string
+	promptIdle    string
+	promptBar     string
+	promptRefresh bool
+)
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Print the running timer for shell prompts and status bars",
+	Long: `Print a one-line summary of the running timer, for use in shell prompts,
+tmux and status bars. It reads a small state file that every other ttt
+command keeps up to date, so it does not scan the stored entries.
+
+--format is a template with these placeholders:
+
+  {project} {task} {comment} {tags} {id}   fields of the active entry
+  {start}                                  its start time, HH:MM
+  {elapsed} {elapsed_seconds}              time worked on it, H:MM or seconds
+  {today} {today_seconds}                  time logged today, including it
+  {state}                                  running, paused or idle
+  {paused}                                 "paused" while on a break
+
+Text in [brackets] is left out when a placeholder inside it is empty, e.g.
+"{project}[: {task}]". A backslash escapes {, [ and ]. While no timer runs,
+--idle is printed instead, which is empty by default.
+
+--bar prints the JSON of the waybar custom module or i3bar protocol. Run
+ttt prompt init <target> for ready-made configuration snippets.`,
+	Example: `  ttt prompt
+  ttt prompt --format '{project}:{task} {elapsed}'
+  ttt prompt --idle 'idle, {today} today'
+  ttt prompt --bar waybar
+  ttt prompt init zsh`,
+	Args: cobra.NoArgs,
+	RunE: runPrompt,
+	// Prompts run constantly; the state file is only rebuilt when outdated.
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {},
+}
+
+var promptInitCmd = &cobra.Command{
+	Use:       "init <target>",
+	Short:     "Print a configuration snippet for a shell or status bar",
+	Long:      "Print a configuration snippet showing ttt prompt in one of: " + strings.Join(promptTargets(), ", ") + ".",
+	Example:   "  ttt prompt init bash >> ~/.bashrc",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: promptTargets(),
+	RunE:      runPromptInit,
+}
+
+func init() {
+	promptCmd.Flags().StringVar(&promptFormat, "format", defaultPromptFormat, "Template for a running timer")
+	promptCmd.Flags().StringVar(&promptIdle, "idle", "", "Template while no timer is running")
+	promptCmd.Flags().StringVar(&promptBar, "bar", "", "Print status bar JSON: waybar or i3bar")
+	promptCmd.Flags().BoolVar(&promptRefresh, "refresh", false, "Rebuild the state file from the stored entries")
+	promptCmd.AddCommand(promptInitCmd)
+}
+
+// promptOutput is the structured output of ttt prompt.
+type promptOutput struct {
+	Text string `json:"text"`
+	// State is running, paused or idle.
+	State          string       `json:"state"`
+	Entry          *model.Entry `json:"entry"`
+	ElapsedSeconds int64        `json:"elapsed_seconds"`
+	TodaySeconds   int64        `json:"today_seconds"`
+}
+
+func runPrompt(cmd *cobra.Command, args []string) error {
+	now := time.Now()
+
+	src := promptFormat
+	if src == "" {
+		src = defaultPromptFormat
+	}
+	tmpl, err := prompt.Compile(src)
+	if err != nil {
+		fail(1, fmt.Errorf("invalid --format: %w", err))
+	}
+	idle, err := prompt.Compile(promptIdle)
+	if err != nil {
+		fail(1, fmt.Errorf("invalid --idle: %w", err))
+	}
+	if promptBar != "" && promptBar != "waybar" && promptBar != "i3bar" {
+		fail(1, fmt.Errorf("invalid --bar %q: use waybar or i3bar", promptBar))
+	}
+
+	st, err := prompt.Load(promptStatePath())
+	if err != nil || !st.Current(now) || promptRefresh {
+		if st, err = buildPromptState(now); err != nil {
+			fail(2, err)
+		}
+	}
+
+	text := idle.Render(st, now)
+	if st.Active != nil {
+		text = tmpl.Render(st, now)
+	}
+	state := "idle"
+	switch {
+	case st.Paused():
+		state = "paused"
+	case st.Active != nil:
+		state = "running"
+	}
+
+	switch {
+	case promptBar != "":
+		printBar(text, state, st, now)
+	case structuredOutput():
+		printOutput(promptOutput{Text: text, State: state, Entry: st.Active,
+			ElapsedSeconds: st.ElapsedSeconds(now), TodaySeconds: st.TodaySeconds(now)})
+	default:
+		fmt.Println(text)
+	}
+	return nil
+}
+
+// printBar prints text as a waybar custom module or an i3bar block, with a
+// tooltip and the state as CSS class for waybar.
+func printBar(text, state string, st prompt.State, now time.Time) {
+	var v any
+	if promptBar == "waybar" {
+		tooltip := "No active timer."
+		if e := st.Active; e != nil {
+			tooltip = e.Project
+			if e.Task != nil {
+				tooltip += " – " + *e.Task
+			}
+			tooltip += fmt.Sprintf("\nStarted %s, %s", e.Start.Format("15:04"), state)
+		}
+		tooltip += fmt.Sprintf("\nToday: %s", formatElapsed(st.TodaySeconds(now)))
+		v = struct {
+			Text    string `json:"text"`
+			Alt     string `json:"alt"`
+			Tooltip string `json:"tooltip"`
+			Class   string `json:"class"`
+		}{text, state, tooltip, state}
+	} else {
+		v = struct {
+			Name     string `json:"name"`
+			FullText string `json:"full_text"`
+		}{"ttt", text}
+	}
+	// Both protocols expect one object per line.
+	data, err := json.Marshal(v)
+	if err != nil {
+		fail(2, err)
+	}
+	fmt.Println(string(data))
+}
+
+// promptStatePath returns the state file of the selected workspace.
+func promptStatePath() string {
+	return filepath.Join(ws.DataDir, prompt.FileName)
+}
+
+// buildPromptState reads the prompt state from the store and saves it.
+func buildPromptState(now time.Time) (prompt.State, error) {
+	store, err := openStore()
+	if err != nil {
+		return prompt.State{}, err
+	}
+	defer closeStore(store)
+	return savePromptState(store, now)
+}
+
+// savePromptState builds the prompt state from an open store and saves it.
+// Failing to save only costs speed on the next call, so it is ignored.
+func savePromptState(store storage.Store, now time.Time) (prompt.State, error) {
+	st, err := prompt.Build(store, now)
+	if err != nil {
+		return prompt.State{}, err
+	}
+	_ = prompt.Save(promptStatePath(), st)
+	return st, nil
+}
+
+// refreshPrompt rewrites the prompt state after a command ran, so ttt prompt
+// reflects its changes. The state is a cache, so errors are ignored.
+func refreshPrompt() {
+	// Workspace commands run without a selected workspace.
+	if ws.DataDir == "" {
+		return
+	}
+	_, _ = buildPromptState(time.Now())
+}
+
+func runPromptInit(cmd *cobra.Command, args []string) error {
+	snippet, ok := promptSnippets[args[0]]
+	if !ok {
+		fail(1, fmt.Errorf("unknown target %q: use one of %s", args[0], strings.Join(promptTargets(), ", ")))
+	}
+	if structuredOutput() {
+		printOutput(struct {
+			Target  string `json:"target"`
+			Snippet string `json:"snippet"`
+		}{args[0], snippet})
+		return nil
+	}
+	fmt.Print(snippet)
+	return nil
+}
+
+func promptTargets() []string {
+	targets := make([]string, 0, len(promptSnippets))
+	for t := range promptSnippets {
+		targets = append(targets, t)
+	}
+	sort.Strings(targets)
+	return targets
+}
+
+// promptSnippets are the configuration snippets printed by ttt prompt init.
+var promptSnippets = map[string]string{
+	"bash": `# ttt: show the running timer in the prompt (add to ~/.bashrc)
+__ttt_prompt() { TTT_PROMPT=$(ttt prompt 2>/dev/null); }
+PROMPT_COMMAND="__ttt_prompt${PROMPT_COMMAND:+; $PROMPT_COMMAND}"
+PS1='${TTT_PROMPT:+[$TTT_PROMPT] }'"$PS1"
+`,
+	"zsh": `# ttt: show the running timer in the right prompt (add to ~/.zshrc)
+setopt prompt_subst
+__ttt_prompt() { TTT_PROMPT=$(ttt prompt 2>/dev/null) }
+autoload -Uz add-zsh-hook
+add-zsh-hook precmd __ttt_prompt
+RPROMPT='${TTT_PROMPT}'
+`,
+	"fish": `# ttt: show the running timer in the right prompt
+# (save as ~/.config/fish/functions/fish_right_prompt.fish)
+function fish_right_prompt
+    ttt prompt 2>/dev/null
+end
+`,
+	"tmux": `# ttt: show the running timer in the status line (add to ~/.tmux.conf)
+set -g status-interval 15
+set -g status-right '#(ttt prompt --format "{project} {elapsed}" --idle "{today}") %H:%M'
+`,
+	"starship": `# ttt: show the running timer (add to ~/.config/starship.toml)
+[custom.ttt]
+command = "ttt prompt"
+when = true
+format = "[$output]($style) "
+style = "bold yellow"
+`,
+	"waybar": `// ttt: add "custom/ttt" to a modules list in ~/.config/waybar/config
+"custom/ttt": {
+    "exec": "ttt prompt --bar waybar --idle 'idle {today}'",
+    "return-type": "json",
+    "interval": 10,
+    "on-click": "ttt stop",
+    "on-click-right": "ttt resume"
+}
+`,
+	"i3status": `#!/usr/bin/env bash
+# ttt: prepend the running timer to i3status. Save as ~/.config/i3/ttt-status,
+# make it executable, set output_format = "i3bar" in the i3status config and
+# use in the i3 or sway config:  bar { status_command ~/.config/i3/ttt-status }
+i3status | (read -r line && echo "$line" && read -r line && echo "$line" && read -r line && echo "$line" && while :
+do
+  read -r line || exit 1
+  block=$(ttt prompt --bar i3bar --idle 'idle {today}')
+  echo "${line/[/[$block,}" || exit 1
+done)
+`,
+}
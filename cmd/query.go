@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/query"
+)
+
+var (
+	queryFormat string
+	queryRange  rangeFlags
+)
+
+var queryCmd = &cobra.Command{
+	Use:   "query <expression>",
+	Short: "Find entries matching a query expression",
+	Long: `Find entries matching a query expression, searching all stored entries
+unless a date range is given.
+
+A query combines field predicates with and, or, not and parentheses:
+
+  field = value, !=, <, <=, >, >=
+  field contains value        substring of a string, element of tags
+  field matches "regexp"      regular expression on a string
+  field [not] in (v1, v2)     any of the values
+  field is [not] null         unset task, comment, end or duration, or no tags
+
+Fields: ` + strings.Join(query.Fields(), ", ") + `.
+
+String comparisons ignore case. Times accept dates (a date covers the whole
+day), "YYYY-MM-DD HH:MM" and relative forms such as yesterday or now-2h;
+durations accept 30m, 1h30m or 1.5h. Running entries have no end and no
+duration, so duration comparisons never match them, unlike --min-duration
+of list, report and export, which counts them until now.`,
+	Example: `  ttt query 'project = "ECM" and tags contains "backend" and duration > 30m and start >= 2026-02-01'
+  ttt query 'task matches "^JIRA-[0-9]+" or comment contains "incident"' --month
+  ttt query 'end is null'`,
+	Args: cobra.ExactArgs(1),
+	RunE: runQuery,
+}
+
+func init() {
+	queryCmd.Flags().StringVar(&queryFormat, "format", "md", "Output format: md, csv, json")
+	queryRange.register(queryCmd)
+}
+
+func runQuery(cmd *cobra.Command, args []string) error {
+	now := time.Now()
+
+	q, err := query.Compile(args[0], now)
+	if err != nil {
+		fail(1, queryError("query", err))
+	}
+
+	store, err := openStore()
+	if err != nil {
+		fail(2, err)
+	}
+
+	// Default to every stored entry when no range is given.
+	r, err := queryRange.resolve(now, func(time.Time) dateRange {
+		return dateRange{All: true, Label: "All time"}
+	})
+	if err != nil {
+		fail(1, err)
+	}
+
+	entries, err := loadEntries(store, r)
+	if err != nil {
+		fail(2, err)
+	}
+
+	printEntries(filterEntries(entries, q.Match), formatFor(cmd, queryFormat))
+	return nil
+}
+
+// queryError turns a query compile error into a user error that shows the
+// query with a caret under the offending position.
+func queryError(what string, err error) error {
+	var qe *query.Error
+	if !errors.As(err, &qe) {
+		return userError{fmt.Sprintf("invalid %s: %v", what, err)}
+	}
+	caret := strings.ReplaceAll(qe.Caret(), "\n", "\n  ")
+	return userError{fmt.Sprintf("invalid %s: %v\n  %s", what, qe, caret)}
+}
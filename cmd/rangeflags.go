@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timeparse"
+)
+
+// currentPeriod is the value --month and --year take when given without an
+// argument.
+const currentPeriod = "current"
+
+// rangeFlags holds the date range selectors shared by list, report and export.
+type rangeFlags struct {
+	today     bool
+	yesterday bool
+	week      bool
+	lastWeek  bool
+	all       bool
+	date      string
+	from      string
+	to        string
+	month     string
+	year      string
+
+	monthFlag periodFlag
+	yearFlag  periodFlag
+}
+
+// dateRange is a resolved, inclusive range of days.
+type dateRange struct {
+	From time.Time
+	To   time.Time
+	// All selects every stored day; From and To are unset.
+	All bool
+	// Label describes the range in report headers, e.g. "Week 2026-W09".
+	Label string
+	// Week is the ISO week label when the range is exactly one week.
+	Week string
+}
+
+// register adds the range flags to cmd. A value given to --month or --year
+// after a space is taken back from the positional arguments before cmd
+// validates them and runs.
+func (r *rangeFlags) register(cmd *cobra.Command) {
+	fs := cmd.Flags()
+	fs.BoolVar(&r.today, "today", false, "Today")
+	fs.BoolVar(&r.yesterday, "yesterday", false, "Yesterday")
+	fs.BoolVar(&r.week, "week", false, "This week")
+	fs.BoolVar(&r.lastWeek, "last-week", false, "Last week")
+	fs.BoolVar(&r.all, "all", false, "All stored entries")
+	fs.StringVar(&r.date, "date", "", "A single day (YYYY-MM-DD, yesterday, mon, ...)")
+	fs.StringVar(&r.from, "from", "", "Range start day (YYYY-MM-DD, last mon, ...)")
+	fs.StringVar(&r.to, "to", "", "Range end day (defaults to today)")
+	// The month and year match loosely, so a malformed one still reaches
+	// resolve and its error message.
+	r.monthFlag = periodFlag{value: &r.month, fs: fs, re: regexp.MustCompile(`^\d{4}-\d{1,2}$`), at: -1}
+	r.yearFlag = periodFlag{value: &r.year, fs: fs, re: regexp.MustCompile(`^\d{4}$`), at: -1}
+	fs.Var(&r.monthFlag, "month", "A calendar month (--month YYYY-MM, or --month for this month)")
+	fs.Var(&r.yearFlag, "year", "A calendar year (--year YYYY, or --year for this year)")
+	fs.Lookup("month").NoOptDefVal = currentPeriod
+	fs.Lookup("year").NoOptDefVal = currentPeriod
+
+	validate, run := cmd.Args, cmd.RunE
+	if validate == nil {
+		validate = cobra.ArbitraryArgs
+	}
+	cmd.Args = func(cmd *cobra.Command, args []string) error {
+		return validate(cmd, r.takePeriodValues(args))
+	}
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return run(cmd, r.takePeriodValues(args))
+	}
+}
+
+// periodFlag is the value of --month or --year. Both flags may be given
+// without a value, so pflag leaves a value after a space behind as a
+// positional argument; periodFlag records where it would be.
+type periodFlag struct {
+	value *string
+	fs    *pflag.FlagSet
+	re    *regexp.Regexp
+	// at is the index of the positional argument following the flag when
+	// it was given without a value, or -1.
+	at int
+}
+
+func (p *periodFlag) String() string {
+	if p.value == nil {
+		return ""
+	}
+	return *p.value
+}
+
+func (p *periodFlag) Set(v string) error {
+	*p.value = v
+	p.at = -1
+	if v == currentPeriod {
+		p.at = len(p.fs.Args())
+	}
+	return nil
+}
+
+func (p *periodFlag) Type() string {
+	return "string"
+}
+
+// takePeriodValues sets --month and --year to the positional argument that
+// directly followed them, if it looks like their value, and returns args
+// without it. Arguments after "--" are never taken.
+func (r *rangeFlags) takePeriodValues(args []string) []string {
+	taken := map[int]bool{}
+	for _, p := range []*periodFlag{&r.monthFlag, &r.yearFlag} {
+		if p.at < 0 || p.at >= len(args) || p.at == p.fs.ArgsLenAtDash() || !p.re.MatchString(args[p.at]) {
+			continue
+		}
+		*p.value = args[p.at]
+		taken[p.at] = true
+	}
+	if len(taken) == 0 {
+		return args
+	}
+	rest := make([]string, 0, len(args))
+	for i, a := range args {
+		if !taken[i] {
+			rest = append(rest, a)
+		}
+	}
+	return rest
+}
+
+// resolve turns the selected flags into a dateRange. def is used when no
+// selector is given. Combining selectors is a user error.
+func (r *rangeFlags) resolve(now time.Time, def func(time.Time) dateRange) (dateRange, error) {
+	var selected []string
+	for name, set := range map[string]bool{
+		"--today":     r.today,
+		"--yesterday": r.yesterday,
+		"--week":      r.week,
+		"--last-week": r.lastWeek,
+		"--all":       r.all,
+		"--date":      r.date != "",
+		"--from/--to": r.from != "" || r.to != "",
+		"--month":     r.month != "",
+		"--year":      r.year != "",
+	} {
+		if set {
+			selected = append(selected, name)
+		}
+	}
+	sort.Strings(selected)
+	if len(selected) > 1 {
+		return dateRange{}, userError{fmt.Sprintf("only one date range may be given, got %s", strings.Join(selected, ", "))}
+	}
+
+	switch {
+	case r.today:
+		return dayRange(now), nil
+	case r.yesterday:
+		return dayRange(now.AddDate(0, 0, -1)), nil
+	case r.week:
+		return weekRange(now), nil
+	case r.lastWeek:
+		return weekRange(now.AddDate(0, 0, -7)), nil
+	case r.all:
+		return dateRange{All: true, Label: "All time"}, nil
+	case r.date != "":
+		d, err := parseDateFlag("date", r.date, now)
+		if err != nil {
+			return dateRange{}, err
+		}
+		return dayRange(d), nil
+	case r.from != "" || r.to != "":
+		if r.from == "" {
+			return dateRange{}, userError{"--from is required when --to is set"}
+		}
+		from, err := parseDateFlag("from", r.from, now)
+		if err != nil {
+			return dateRange{}, err
+		}
+		to := now
+		if r.to != "" {
+			if to, err = parseDateFlag("to", r.to, now); err != nil {
+				return dateRange{}, err
+			}
+		}
+		if to.Before(from) {
+			return dateRange{}, userError{"--to must not be before --from"}
+		}
+		return dateRange{
+			From:  timecalc.StartOfDay(from),
+			To:    timecalc.EndOfDay(to),
+			Label: fmt.Sprintf("%s → %s", from.Format("2006-01-02"), to.Format("2006-01-02")),
+		}, nil
+	case r.month != "":
+		m := now
+		if r.month != currentPeriod {
+			t, err := time.ParseInLocation("2006-01", r.month, now.Location())
+			if err != nil {
+				return dateRange{}, userError{fmt.Sprintf("invalid --month %q: expected YYYY-MM", r.month)}
+			}
+			m = t
+		}
+		from, to := timecalc.MonthRange(m)
+		return dateRange{From: from, To: to, Label: "Month " + from.Format("2006-01")}, nil
+	case r.year != "":
+		y := now
+		if r.year != currentPeriod {
+			t, err := time.ParseInLocation("2006", r.year, now.Location())
+			if err != nil {
+				return dateRange{}, userError{fmt.Sprintf("invalid --year %q: expected YYYY", r.year)}
+			}
+			y = t
+		}
+		from, to := timecalc.YearRange(y)
+		return dateRange{From: from, To: to, Label: "Year " + from.Format("2006")}, nil
+	default:
+		return def(now), nil
+	}
+}
+
+// dayRange returns the range covering the single day containing t.
+func dayRange(t time.Time) dateRange {
+	from, to := timecalc.DayRange(t)
+	return dateRange{From: from, To: to, Label: from.Format("2006-01-02")}
+}
+
+// weekRange returns the range covering the ISO week containing t.
+func weekRange(t time.Time) dateRange {
+	from, to := timecalc.WeekRange(t)
+	label := timecalc.ISOWeekLabel(t)
+	return dateRange{From: from, To: to, Label: "Week " + label, Week: label}
+}
+
+// parseDateFlag parses a day flag value such as YYYY-MM-DD, yesterday or mon
+// relative to now.
+func parseDateFlag(flag, v string, now time.Time) (time.Time, error) {
+	t, err := timeparse.Day(v, now)
+	if err != nil {
+		return time.Time{}, userError{fmt.Sprintf("invalid --%s: %v", flag, err)}
+	}
+	return t, nil
+}
+
+// loadEntries loads all entries in r.
+func loadEntries(store storage.Store, r dateRange) ([]model.Entry, error) {
+	if r.All {
+		return storage.LoadAll(store)
+	}
+	return store.LoadRange(r.From, r.To)
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestRangeFlagsResolve(t *testing.T) {
+	now := time.Date(2026, 2, 27, 10, 0, 0, 0, time.UTC)
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		flags    rangeFlags
+		wantFrom time.Time
+		wantTo   time.Time
+		wantAll  bool
+	}{
+		{"default", rangeFlags{}, day(2026, 2, 23), day(2026, 3, 1), false},
+		{"yesterday", rangeFlags{yesterday: true}, day(2026, 2, 26), day(2026, 2, 26), false},
+		{"last week", rangeFlags{lastWeek: true}, day(2026, 2, 16), day(2026, 2, 22), false},
+		{"date", rangeFlags{date: "2026-01-15"}, day(2026, 1, 15), day(2026, 1, 15), false},
+		{"from only", rangeFlags{from: "2026-02-20"}, day(2026, 2, 20), day(2026, 2, 27), false},
+		{"from to", rangeFlags{from: "2026-02-01", to: "2026-02-10"}, day(2026, 2, 1), day(2026, 2, 10), false},
+		{"this month", rangeFlags{month: currentPeriod}, day(2026, 2, 1), day(2026, 2, 28), false},
+		{"given month", rangeFlags{month: "2025-12"}, day(2025, 12, 1), day(2025, 12, 31), false},
+		{"year", rangeFlags{year: "2025"}, day(2025, 1, 1), day(2025, 12, 31), false},
+		{"all", rangeFlags{all: true}, time.Time{}, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		r, err := tt.flags.resolve(now, weekRange)
+		if err != nil {
+			t.Errorf("%s: resolve: %v", tt.name, err)
+			continue
+		}
+		if r.All != tt.wantAll {
+			t.Errorf("%s: All = %v, want %v", tt.name, r.All, tt.wantAll)
+		}
+		if tt.wantAll {
+			continue
+		}
+		if !r.From.Equal(tt.wantFrom) {
+			t.Errorf("%s: From = %v, want %v", tt.name, r.From, tt.wantFrom)
+		}
+		if wantTo := tt.wantTo.Add(24*time.Hour - time.Second); !r.To.Equal(wantTo) {
+			t.Errorf("%s: To = %v, want %v", tt.name, r.To, wantTo)
+		}
+	}
+}
+
+func TestRangeFlagsResolveErrors(t *testing.T) {
+	now := time.Date(2026, 2, 27, 10, 0, 0, 0, time.UTC)
+	for name, flags := range map[string]rangeFlags{
+		"combined":     {today: true, week: true},
+		"to only":      {to: "2026-02-10"},
+		"reversed":     {from: "2026-02-10", to: "2026-02-01"},
+		"bad date":     {date: "27.02.2026"},
+		"bad month":    {month: "2026-13"},
+		"bad year":     {year: "twenty"},
+		"month + week": {month: currentPeriod, week: true},
+	} {
+		if _, err := flags.resolve(now, weekRange); err == nil || exitCode(err) != 1 {
+			t.Errorf("%s: err = %v, want user error", name, err)
+		}
+	}
+}
+
+func TestRangeFlagsPeriodValue(t *testing.T) {
+	tests := []struct {
+		args, month, year, rest string
+	}{
+		{"--month 2026-02 --format csv", "2026-02", "", ""},
+		{"--month --format csv", currentPeriod, "", ""},
+		{"--year 2025", "", "2025", ""},
+		{"--month 2026-2", "2026-2", "", ""},
+		{"--month=2026-01", "2026-01", "", ""},
+		{"deploy --month", currentPeriod, "", "deploy"},
+		{"deploy --month 2026-02 review", "2026-02", "", "deploy review"},
+		{"2026 --year", "", currentPeriod, "2026"},
+		{"--month -- 2026-02", currentPeriod, "", "2026-02"},
+	}
+	for _, tt := range tests {
+		var r rangeFlags
+		var rest []string
+		cmd := &cobra.Command{
+			Use:  "test",
+			Args: cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				rest = args
+				return nil
+			},
+			SilenceErrors: true,
+			SilenceUsage:  true,
+		}
+		if tt.rest != "" {
+			cmd.Args = cobra.ArbitraryArgs
+		}
+		cmd.Flags().String("format", "", "")
+		r.register(cmd)
+		cmd.SetArgs(strings.Fields(tt.args))
+		if err := cmd.Execute(); err != nil {
+			t.Errorf("%q: %v", tt.args, err)
+			continue
+		}
+		if r.month != tt.month || r.year != tt.year || strings.Join(rest, " ") != tt.rest {
+			t.Errorf("%q: month %q, year %q, args %q; want %q, %q, %q",
+				tt.args, r.month, r.year, rest, tt.month, tt.year, tt.rest)
+		}
+	}
+}
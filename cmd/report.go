@@ -1,101 +1,151 @@
 package cmd
 
 import (
-	"fmt"
-	"os"
-	"sort"
+	"errors"
 	"time"
 
 	"github.com/spf13/cobra"
 
-	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
-	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
+	"github.com/Tiliavir/trivial-time-tracker/internal/overlap"
 )
 
 var (
-	reportWeek   bool
-	reportFormat string
+	reportFormat  string
+	reportGroupBy string
+	reportPivot   string
+	reportDedupe  bool
+	reportRange   rangeFlags
+	reportFilter  filterFlags
 )
 
 var reportCmd = &cobra.Command{
 	Use:   "report",
 	Short: "Show aggregated time report",
-	Args:  cobra.NoArgs,
-	RunE:  runReport,
+	Long: `Show tracked time aggregated by project, or by any combination of
+project, task, tag, day and client.
+
+With several --group-by keys the report nests one level per key and shows a
+subtotal for every outer group. --pivot renders a grid with one row per value
+of the first key, one column per value of the second and totals for both.
+An entry with several tags counts towards each of its tags; totals count it
+once. --dedupe-overlaps counts time covered by several entries only once,
+crediting it to Outlook meetings before manual entries and otherwise to the
+entry that started first (see ttt overlaps).`,
+	Example: `  ttt report --week
+  ttt report --group-by project,task
+  ttt report --group-by tag --month
+  ttt report --pivot "project x day" --format csv
+  ttt report --month --dedupe-overlaps`,
+	Args: cobra.NoArgs,
+	RunE: runReport,
 }
 
 func init() {
-	reportCmd.Flags().BoolVar(&reportWeek, "week", false, "Report for this week (default)")
 	reportCmd.Flags().StringVar(&reportFormat, "format", "md", "Output format: md, csv, json")
+	reportCmd.Flags().StringVar(&reportGroupBy, "group-by", "project", "Comma-separated group keys: project, task, tag, day, client")
+	reportCmd.Flags().StringVar(&reportPivot, "pivot", "", "Pivot grid \"<rows> x <columns>\", e.g. \"project x day\"")
+	reportCmd.Flags().BoolVar(&reportDedupe, "dedupe-overlaps", false, "Count time covered by several entries only once")
+	reportRange.register(reportCmd)
+	reportFilter.register(reportCmd.Flags())
+	reportCmd.Flags().Lookup("week").Usage = "This week (default)"
 }
 
 func runReport(cmd *cobra.Command, args []string) error {
 	now := time.Now()
+	format := formatFor(cmd, reportFormat)
+
+	store, err := openStore()
+	if err != nil {
+		fail(2, err)
+	}
+
+	// Default to this week when no range is given.
+	r, err := reportRange.resolve(now, weekRange)
+	if err != nil {
+		fail(1, err)
+	}
 
-	base, err := storage.BaseDir()
+	keep, err := reportFilter.build(now)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(2)
+		fail(1, err)
 	}
 
-	from, to := timecalc.WeekRange(now)
-	label := timecalc.ISOWeekLabel(now)
+	entries, err := loadEntries(store, r)
+	if err != nil {
+		fail(2, err)
+	}
+	// Dedupe before filtering, so an entry filtered out still claims the
+	// time it shares with the entries shown.
+	if reportDedupe {
+		entries = overlap.Dedupe(entries, overlap.DefaultPriority)
+	}
+	entries = filterEntries(entries, keep)
 
-	entries, err := storage.LoadRange(base, from, to)
+	projects, err := store.LoadProjects()
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(2)
+		fail(2, err)
 	}
 
-	// Aggregate by project.
-	totals := map[string]int64{}
-	var order []string
-	for _, e := range entries {
-		if e.DurationSeconds == nil {
-			continue
+	if reportPivot != "" {
+		if cmd.Flags().Changed("group-by") {
+			fail(1, errors.New("--pivot cannot be combined with --group-by"))
 		}
-		if _, seen := totals[e.Project]; !seen {
-			order = append(order, e.Project)
+		row, col, err := parsePivot(reportPivot, projects)
+		if err != nil {
+			fail(1, err)
 		}
-		totals[e.Project] += *e.DurationSeconds
+		var cols []string
+		if col.name == "day" {
+			cols = rangeDays(r)
+		}
+		t := pivotEntries(entries, row, col, cols)
+		switch format {
+		case "csv":
+			printPivotCSV(t, row)
+		case "json":
+			report := append(reportJSONHeader(r), pivotJSON(t, row, col)...)
+			printOutput(append(report, jsonField{"total_minutes", t.Total / 60}))
+		default: // md
+			printPivotMD(t, row, col, r.Label)
+		}
+		return nil
 	}
-	sort.Strings(order)
 
-	var grandTotal int64
-	for _, sec := range totals {
-		grandTotal += sec
+	keys, err := parseGroupBy(reportGroupBy, projects)
+	if err != nil {
+		fail(1, err)
 	}
+	groups := groupEntries(entries, keys)
 
-	switch reportFormat {
+	grandTotal := entriesTotal(entries)
+
+	switch format {
 	case "csv":
-		fmt.Println("project,duration_minutes")
-		for _, p := range order {
-			fmt.Printf("%s,%d\n", p, totals[p]/60)
-		}
+		printGroupsCSV(groups, keys)
 	case "json":
-		fmt.Println("{")
-		fmt.Printf("  \"week\": %q,\n", label)
-		fmt.Println("  \"projects\": [")
-		for i, p := range order {
-			comma := ","
-			if i == len(order)-1 {
-				comma = ""
-			}
-			fmt.Printf("    {\"project\": %q, \"duration_minutes\": %d}%s\n",
-				p, totals[p]/60, comma)
+		// A plain project report keeps its original "projects" field.
+		name := "groups"
+		if len(keys) == 1 && keys[0].name == "project" {
+			name = "projects"
 		}
-		fmt.Println("  ],")
-		fmt.Printf("  \"total_minutes\": %d\n", grandTotal/60)
-		fmt.Println("}")
+		report := append(reportJSONHeader(r), jsonField{name, groupsJSON(groups, keys, 0)})
+		printOutput(append(report, jsonField{"total_minutes", grandTotal / 60}))
 	default: // md
-		fmt.Printf("Week %s\n", label)
-		fmt.Println("--------------------------------")
-		for _, p := range order {
-			fmt.Printf("%-20s%s\n", p, timecalc.FormatDuration(totals[p]))
-		}
-		fmt.Println("--------------------------------")
-		fmt.Printf("%-20s%s\n", "Total", timecalc.FormatDuration(grandTotal))
+		printGroupsMD(groups, grandTotal, r.Label)
 	}
 
 	return nil
 }
+
+// reportJSONHeader returns the period fields that open a JSON report.
+func reportJSONHeader(r dateRange) jsonObject {
+	var o jsonObject
+	if r.Week != "" {
+		o = append(o, jsonField{"week", r.Week})
+	}
+	o = append(o, jsonField{"period", r.Label})
+	if !r.All {
+		o = append(o, jsonField{"from", r.From.Format("2006-01-02")}, jsonField{"to", r.To.Format("2006-01-02")})
+	}
+	return o
+}
@@ -0,0 +1,401 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
+)
+
+// groupKeyNames lists the keys accepted by --group-by and --pivot.
+var groupKeyNames = []string{"project", "task", "tag", "day", "client"}
+
+// groupKey is a report dimension. values returns the values an entry is
+// reported under: one for most keys, one per tag for "tag", so an entry with
+// two tags counts towards both.
+type groupKey struct {
+	name   string
+	values func(e model.Entry) []string
+}
+
+// newGroupKey returns the key with the given name. projects is used to look
+// up the client of an entry's project.
+func newGroupKey(name string, projects []model.Project) (groupKey, error) {
+	k := groupKey{name: name}
+	switch name {
+	case "project":
+		k.values = func(e model.Entry) []string { return []string{e.Project} }
+	case "task":
+		k.values = func(e model.Entry) []string {
+			if e.Task == nil || *e.Task == "" {
+				return []string{"(no task)"}
+			}
+			return []string{*e.Task}
+		}
+	case "tag":
+		k.values = func(e model.Entry) []string {
+			if len(e.Tags) == 0 {
+				return []string{"(no tag)"}
+			}
+			return e.Tags
+		}
+	case "day":
+		k.values = func(e model.Entry) []string { return []string{e.Start.Format("2006-01-02")} }
+	case "client":
+		k.values = func(e model.Entry) []string {
+			if p := findProject(projects, e.Project); p != nil && p.Client != "" {
+				return []string{p.Client}
+			}
+			return []string{"(no client)"}
+		}
+	default:
+		return k, userError{fmt.Sprintf("unknown group key %q: expected one of %s", name, strings.Join(groupKeyNames, ", "))}
+	}
+	return k, nil
+}
+
+// parseGroupBy parses a comma-separated --group-by value such as
+// "project,task".
+func parseGroupBy(s string, projects []model.Project) ([]groupKey, error) {
+	var keys []groupKey
+	seen := map[string]bool{}
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if seen[name] {
+			return nil, userError{fmt.Sprintf("invalid --group-by: %q given twice", name)}
+		}
+		seen[name] = true
+		k, err := newGroupKey(name, projects)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+var pivotRe = regexp.MustCompile(`^\s*([a-z]+)\s*x\s*([a-z]+)\s*$`)
+
+// parsePivot parses a --pivot value of the form "<rows> x <columns>", e.g.
+// "project x day".
+func parsePivot(s string, projects []model.Project) (groupKey, groupKey, error) {
+	m := pivotRe.FindStringSubmatch(strings.ToLower(s))
+	if m == nil {
+		return groupKey{}, groupKey{}, userError{fmt.Sprintf("invalid --pivot %q: expected <rows> x <columns>, e.g. \"project x day\"", s)}
+	}
+	if m[1] == m[2] {
+		return groupKey{}, groupKey{}, userError{fmt.Sprintf("invalid --pivot %q: rows and columns must differ", s)}
+	}
+	row, err := newGroupKey(m[1], projects)
+	if err != nil {
+		return groupKey{}, groupKey{}, err
+	}
+	col, err := newGroupKey(m[2], projects)
+	if err != nil {
+		return groupKey{}, groupKey{}, err
+	}
+	return row, col, nil
+}
+
+// reportGroup is one node of a grouped report. Seconds is the total of all
+// entries in the group, including those of its sub-groups.
+type reportGroup struct {
+	Key     string
+	Seconds int64
+	Groups  []*reportGroup
+}
+
+// groupEntries aggregates completed entries by keys, nesting one level per
+// key. Groups are sorted by key at every level.
+func groupEntries(entries []model.Entry, keys []groupKey) []*reportGroup {
+	if len(keys) == 0 {
+		return nil
+	}
+	byKey := map[string][]model.Entry{}
+	for _, e := range entries {
+		if e.DurationSeconds == nil {
+			continue
+		}
+		for _, v := range keys[0].values(e) {
+			byKey[v] = append(byKey[v], e)
+		}
+	}
+
+	groups := make([]*reportGroup, 0, len(byKey))
+	for k, es := range byKey {
+		g := &reportGroup{Key: k, Groups: groupEntries(es, keys[1:])}
+		for _, e := range es {
+			g.Seconds += *e.DurationSeconds
+		}
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Key < groups[j].Key })
+	return groups
+}
+
+// entriesTotal returns the grand total of a report. It counts every
+// completed entry once, even if it is reported under several tags.
+func entriesTotal(entries []model.Entry) int64 {
+	var total int64
+	for _, e := range entries {
+		if e.DurationSeconds != nil {
+			total += *e.DurationSeconds
+		}
+	}
+	return total
+}
+
+// pivotTable is a two-dimensional aggregation of entries. Row, column and
+// grand totals count every entry once, even when it appears in several
+// cells of a row or column because of multiple tags.
+type pivotTable struct {
+	Rows      []string
+	Cols      []string
+	Cells     map[[2]string]int64
+	RowTotals map[string]int64
+	ColTotals map[string]int64
+	Total     int64
+}
+
+// pivotEntries aggregates completed entries by row and column key. cols
+// seeds the column list, so that e.g. days without entries still appear.
+func pivotEntries(entries []model.Entry, row, col groupKey, cols []string) pivotTable {
+	t := pivotTable{
+		Cells:     map[[2]string]int64{},
+		RowTotals: map[string]int64{},
+		ColTotals: map[string]int64{},
+	}
+	seenCol := map[string]bool{}
+	for _, c := range cols {
+		seenCol[c] = true
+	}
+	for _, e := range entries {
+		if e.DurationSeconds == nil {
+			continue
+		}
+		sec := *e.DurationSeconds
+		t.Total += sec
+		rows, cs := row.values(e), col.values(e)
+		for _, r := range rows {
+			if _, ok := t.RowTotals[r]; !ok {
+				t.Rows = append(t.Rows, r)
+			}
+			t.RowTotals[r] += sec
+			for _, c := range cs {
+				t.Cells[[2]string{r, c}] += sec
+			}
+		}
+		for _, c := range cs {
+			if !seenCol[c] {
+				seenCol[c] = true
+				cols = append(cols, c)
+			}
+			t.ColTotals[c] += sec
+		}
+	}
+	sort.Strings(t.Rows)
+	t.Cols = append([]string(nil), cols...)
+	sort.Strings(t.Cols)
+	return t
+}
+
+// rangeDays returns every day in r as YYYY-MM-DD, or nil for --all.
+func rangeDays(r dateRange) []string {
+	if r.All {
+		return nil
+	}
+	var days []string
+	for d := timecalc.StartOfDay(r.From); !d.After(r.To); d = d.AddDate(0, 0, 1) {
+		days = append(days, d.Format("2006-01-02"))
+	}
+	return days
+}
+
+// printGroupsMD prints nested groups as an indented list in which every
+// parent line carries the subtotal of its children.
+func printGroupsMD(groups []*reportGroup, total int64, label string) {
+	width := 20
+	var measure func(gs []*reportGroup, depth int)
+	measure = func(gs []*reportGroup, depth int) {
+		for _, g := range gs {
+			if n := 2*depth + len([]rune(g.Key)) + 2; n > width {
+				width = n
+			}
+			measure(g.Groups, depth+1)
+		}
+	}
+	measure(groups, 0)
+
+	var walk func(gs []*reportGroup, depth int)
+	walk = func(gs []*reportGroup, depth int) {
+		for _, g := range gs {
+			fmt.Printf("%-*s%s\n", width, strings.Repeat("  ", depth)+g.Key, timecalc.FormatDuration(g.Seconds))
+			walk(g.Groups, depth+1)
+		}
+	}
+
+	fmt.Println(label)
+	fmt.Println("--------------------------------")
+	walk(groups, 0)
+	fmt.Println("--------------------------------")
+	fmt.Printf("%-*s%s\n", width, "Total", timecalc.FormatDuration(total))
+}
+
+// printGroupsCSV prints one row per innermost group. With several keys,
+// each outer group is followed by a subtotal row whose inner key columns
+// are empty.
+func printGroupsCSV(groups []*reportGroup, keys []groupKey) {
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = k.name
+	}
+	fmt.Println(strings.Join(names, ",") + ",duration_minutes")
+
+	var walk func(gs []*reportGroup, path []string)
+	walk = func(gs []*reportGroup, path []string) {
+		for _, g := range gs {
+			p := append(append([]string(nil), path...), csvEscape(g.Key))
+			if len(g.Groups) > 0 {
+				walk(g.Groups, p)
+			}
+			cols := append(p, make([]string, len(keys)-len(p))...)
+			fmt.Printf("%s,%d\n", strings.Join(cols, ","), g.Seconds/60)
+		}
+	}
+	walk(groups, nil)
+}
+
+// groupsJSON returns groups as JSON objects, nesting sub-groups under
+// "groups".
+func groupsJSON(groups []*reportGroup, keys []groupKey, depth int) []jsonObject {
+	out := []jsonObject{}
+	for _, g := range groups {
+		o := jsonObject{{keys[depth].name, g.Key}, {"duration_minutes", g.Seconds / 60}}
+		if len(g.Groups) > 0 {
+			o = append(o, jsonField{"groups", groupsJSON(g.Groups, keys, depth+1)})
+		}
+		out = append(out, o)
+	}
+	return out
+}
+
+// printPivotMD prints the pivot table as an aligned grid with a total column
+// and a total row.
+func printPivotMD(t pivotTable, row, col groupKey, label string) {
+	header := make([]string, len(t.Cols))
+	for i, c := range t.Cols {
+		header[i] = c
+		if col.name == "day" {
+			if d, err := time.Parse("2006-01-02", c); err == nil {
+				header[i] = d.Format("Mon 01-02")
+			}
+		}
+	}
+
+	cell := func(sec int64) string {
+		if sec == 0 {
+			return "-"
+		}
+		return timecalc.FormatDuration(sec)
+	}
+	lines := [][]string{append(append([]string{strings.ToUpper(row.name[:1]) + row.name[1:]}, header...), "Total")}
+	for _, r := range t.Rows {
+		line := []string{r}
+		for _, c := range t.Cols {
+			line = append(line, cell(t.Cells[[2]string{r, c}]))
+		}
+		lines = append(lines, append(line, cell(t.RowTotals[r])))
+	}
+	totals := []string{"Total"}
+	for _, c := range t.Cols {
+		totals = append(totals, cell(t.ColTotals[c]))
+	}
+	totals = append(totals, cell(t.Total))
+
+	widths := make([]int, len(lines[0]))
+	widths[0] = 18
+	for _, l := range append(lines, totals) {
+		for i, s := range l {
+			if n := len([]rune(s)); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	printRow := func(l []string) {
+		var b strings.Builder
+		for i, s := range l {
+			if i == 0 {
+				fmt.Fprintf(&b, "%-*s", widths[i]+2, s)
+			} else {
+				fmt.Fprintf(&b, "%*s", widths[i]+2, s)
+			}
+		}
+		fmt.Println(strings.TrimRight(b.String(), " "))
+	}
+	rule := 0
+	for _, w := range widths {
+		rule += w + 2
+	}
+
+	fmt.Println(label)
+	printRow(lines[0])
+	fmt.Println(strings.Repeat("-", rule))
+	for _, l := range lines[1:] {
+		printRow(l)
+	}
+	fmt.Println(strings.Repeat("-", rule))
+	printRow(totals)
+}
+
+// printPivotCSV prints the pivot table in minutes with a total column and a
+// final total row.
+func printPivotCSV(t pivotTable, row groupKey) {
+	header := []string{row.name}
+	for _, c := range t.Cols {
+		header = append(header, csvEscape(c))
+	}
+	fmt.Println(strings.Join(append(header, "total"), ","))
+	for _, r := range t.Rows {
+		line := []string{csvEscape(r)}
+		for _, c := range t.Cols {
+			line = append(line, fmt.Sprint(t.Cells[[2]string{r, c}]/60))
+		}
+		fmt.Println(strings.Join(append(line, fmt.Sprint(t.RowTotals[r]/60)), ","))
+	}
+	line := []string{"total"}
+	for _, c := range t.Cols {
+		line = append(line, fmt.Sprint(t.ColTotals[c]/60))
+	}
+	fmt.Println(strings.Join(append(line, fmt.Sprint(t.Total/60)), ","))
+}
+
+// pivotJSON returns the body of a pivot report: the column values, one
+// object per row with per-column minutes, and the column totals.
+func pivotJSON(t pivotTable, row, col groupKey) jsonObject {
+	minutes := func(get func(c string) int64) []int64 {
+		m := make([]int64, len(t.Cols))
+		for i, c := range t.Cols {
+			m[i] = get(c) / 60
+		}
+		return m
+	}
+
+	rows := []jsonObject{}
+	for _, r := range t.Rows {
+		rows = append(rows, jsonObject{
+			{row.name, r},
+			{"minutes", minutes(func(c string) int64 { return t.Cells[[2]string{r, c}] })},
+			{"total_minutes", t.RowTotals[r] / 60},
+		})
+	}
+	return jsonObject{
+		{"pivot", jsonObject{{"rows", row.name}, {"columns", col.name}}},
+		{"columns", append([]string{}, t.Cols...)},
+		{"rows", rows},
+		{"column_totals_minutes", minutes(func(c string) int64 { return t.ColTotals[c] })},
+	}
+}
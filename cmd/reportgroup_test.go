@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+)
+
+func reportEntry(project, task string, day int, minutes int64, tags ...string) model.Entry {
+	sec := minutes * 60
+	e := model.Entry{
+		Project:         project,
+		Tags:            tags,
+		Start:           time.Date(2026, 2, day, 9, 0, 0, 0, time.UTC),
+		DurationSeconds: &sec,
+	}
+	if task != "" {
+		e.Task = &task
+	}
+	return e
+}
+
+func TestGroupEntriesNested(t *testing.T) {
+	entries := []model.Entry{
+		reportEntry("ECM", "REST", 23, 60),
+		reportEntry("ECM", "Review", 23, 30),
+		reportEntry("ECM", "REST", 24, 60),
+		reportEntry("Admin", "", 24, 15),
+		{Project: "ECM", Start: time.Now()}, // running, not counted
+	}
+	keys, err := parseGroupBy("project, task", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	groups := groupEntries(entries, keys)
+	if len(groups) != 2 || groups[0].Key != "Admin" || groups[1].Key != "ECM" {
+		t.Fatalf("groups = %+v, want Admin and ECM", groups)
+	}
+	ecm := groups[1]
+	if ecm.Seconds != 150*60 || len(ecm.Groups) != 2 {
+		t.Fatalf("ECM = %+v, want 150m in two tasks", ecm)
+	}
+	if g := ecm.Groups[0]; g.Key != "REST" || g.Seconds != 120*60 {
+		t.Errorf("ECM/REST = %+v, want 120m", g)
+	}
+	if g := groups[0].Groups[0]; g.Key != "(no task)" {
+		t.Errorf("Admin task = %q, want (no task)", g.Key)
+	}
+}
+
+func TestPivotEntries(t *testing.T) {
+	entries := []model.Entry{
+		reportEntry("ECM", "", 23, 60, "backend", "api"),
+		reportEntry("ECM", "", 24, 30),
+		reportEntry("Admin", "", 24, 15, "api"),
+	}
+	row, col, err := parsePivot("tag x day", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tbl := pivotEntries(entries, row, col, []string{"2026-02-22", "2026-02-23", "2026-02-24"})
+	if want := []string{"(no tag)", "api", "backend"}; len(tbl.Rows) != 3 || tbl.Rows[1] != want[1] {
+		t.Errorf("rows = %v, want %v", tbl.Rows, want)
+	}
+	if len(tbl.Cols) != 3 || tbl.Cols[0] != "2026-02-22" {
+		t.Errorf("cols = %v, want the seeded days", tbl.Cols)
+	}
+	if got := tbl.Cells[[2]string{"api", "2026-02-24"}]; got != 15*60 {
+		t.Errorf("api on 24th = %d, want 15m", got)
+	}
+	if got := tbl.RowTotals["api"]; got != 75*60 {
+		t.Errorf("api total = %d, want 75m", got)
+	}
+	// Column and grand totals count the entry tagged twice only once.
+	if got := tbl.ColTotals["2026-02-23"]; got != 60*60 {
+		t.Errorf("23rd total = %d, want 60m", got)
+	}
+	if tbl.Total != 105*60 {
+		t.Errorf("total = %d, want 105m", tbl.Total)
+	}
+}
+
+func TestReportJSONEscapesControlCharacters(t *testing.T) {
+	entries := []model.Entry{reportEntry("ECM", "bell\x01 \"quoted\"", 23, 60, "a\u2028b")}
+	keys, err := parseGroupBy("project,task", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	row, col, err := parsePivot("tag x task", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := weekRange(time.Date(2026, 2, 23, 0, 0, 0, 0, time.UTC))
+
+	for name, report := range map[string]jsonObject{
+		"groups": append(reportJSONHeader(r), jsonField{"groups", groupsJSON(groupEntries(entries, keys), keys, 0)}),
+		"pivot":  append(reportJSONHeader(r), pivotJSON(pivotEntries(entries, row, col, nil), row, col)...),
+	} {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		var decoded map[string]any
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Errorf("%s: invalid JSON: %v\n%s", name, err, data)
+		}
+		if !strings.Contains(string(data), `bell\u0001 \"quoted\"`) {
+			t.Errorf("%s: task not escaped:\n%s", name, data)
+		}
+		if i, j := strings.Index(string(data), `"week"`), strings.Index(string(data), `"period"`); i < 0 || j < i {
+			t.Errorf("%s: header fields out of order:\n%s", name, data)
+		}
+	}
+}
+
+func TestParsePivotInvalid(t *testing.T) {
+	for _, s := range []string{"project", "project x project", "project x week", "x day"} {
+		if _, _, err := parsePivot(s, nil); err == nil {
+			t.Errorf("parsePivot(%q): expected error, got nil", s)
+		}
+	}
+}
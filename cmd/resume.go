@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+)
+
+var (
+	resumeComment bool
+	resumePick    bool
+	resumeLimit   int
+	resumeAt      string
+	resumeAgo     string
+)
+
+var resumeCmd = &cobra.Command{
+	Use:     "resume [id|prefix]",
+	Aliases: []string{"continue"},
+	Short:   "Start a new timer from a previous entry",
+	Long: `Start a new timer with the project, task and tags of a previous entry.
+
+Without arguments the most recently stopped entry is resumed. An entry ID or
+unique prefix selects a specific entry, and --pick offers a choice between
+the most recent distinct project/task pairs. A running timer is auto-stopped
+just like with ttt start.`,
+	Example: `  ttt resume
+  ttt continue 20260227-0832
+  ttt resume --pick --limit 5`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runResume,
+}
+
+func init() {
+	resumeCmd.Flags().BoolVar(&resumeComment, "comment", false, "Also copy the comment")
+	resumeCmd.Flags().BoolVar(&resumePick, "pick", false, "Choose from recent project/task pairs")
+	resumeCmd.Flags().IntVar(&resumeLimit, "limit", 10, "Number of choices offered by --pick")
+	resumeCmd.Flags().StringVar(&resumeAt, "at", "", "Start time, e.g. 08:45 or now-15m")
+	resumeCmd.Flags().StringVar(&resumeAgo, "ago", "", "Start this long ago, e.g. 20m")
+}
+
+func runResume(cmd *cobra.Command, args []string) error {
+	if resumePick && len(args) > 0 {
+		fail(1, errors.New("--pick cannot be combined with an entry ID"))
+	}
+
+	now, err := resolveAt(time.Now(), resumeAt, resumeAgo)
+	if err != nil {
+		fail(1, err)
+	}
+
+	store, err := openStore()
+	if err != nil {
+		fail(2, err)
+	}
+
+	var source model.Entry
+	switch {
+	case len(args) == 1:
+		loc, err := resolveEntry(store, args[0])
+		if err != nil {
+			fail(exitCode(err), err)
+		}
+		source = loc.Entry
+	case resumePick:
+		candidates, err := recentPairs(store, resumeLimit)
+		if err != nil {
+			fail(2, err)
+		}
+		if len(candidates) == 0 {
+			fail(1, errors.New("no previous entry to resume"))
+		}
+		// Keep stdout free for the result with --output json or yaml.
+		prompt := os.Stdout
+		if structuredOutput() {
+			prompt = os.Stderr
+		}
+		source, err = pickEntry(os.Stdin, prompt, candidates)
+		if err != nil {
+			fail(1, err)
+		}
+	default:
+		candidates, err := recentPairs(store, 1)
+		if err != nil {
+			fail(2, err)
+		}
+		if len(candidates) == 0 {
+			fail(1, errors.New("no previous entry to resume"))
+		}
+		source = candidates[0]
+	}
+
+	entry := model.Entry{
+		Project: source.Project,
+		Task:    source.Task,
+		Tags:    append([]string{}, source.Tags...),
+	}
+	if resumeComment {
+		entry.Comment = source.Comment
+	}
+
+	entry, stopped, err := startEntry(store, entry, now)
+	if err != nil {
+		fail(exitCode(err), err)
+	}
+
+	if structuredOutput() {
+		printOutput(entryOutput{Entry: entry, Stopped: stopped})
+		return nil
+	}
+
+	task := ""
+	if entry.Task != nil {
+		task = fmt.Sprintf(" (%s)", *entry.Task)
+	}
+	fmt.Printf("Resumed timer for project %q%s at %s\n", entry.Project, task, now.Format("15:04:05"))
+	return nil
+}
+
+// recentPairs returns up to limit stopped entries, newest first, keeping only
+// the most recent entry of each distinct project/task pair.
+func recentPairs(store storage.Store, limit int) ([]model.Entry, error) {
+	seen := map[string]bool{}
+	var out []model.Entry
+	err := store.EachReverse(func(e model.Entry, _ time.Time) bool {
+		if e.End == nil {
+			return true
+		}
+		key := e.Project + "\x00" + derefOr(e.Task, "")
+		if seen[key] {
+			return true
+		}
+		seen[key] = true
+		out = append(out, e)
+		return len(out) < limit
+	})
+	return out, err
+}
+
+// pickEntry prints a numbered list of candidates to out and reads the chosen
+// number from in.
+func pickEntry(in io.Reader, out io.Writer, candidates []model.Entry) (model.Entry, error) {
+	for i, e := range candidates {
+		task := ""
+		if e.Task != nil {
+			task = "  " + *e.Task
+		}
+		fmt.Fprintf(out, "%2d) %s%s  (last %s)\n", i+1, e.Project, task, e.Start.Format("2006-01-02"))
+	}
+	fmt.Fprintf(out, "Select [1-%d]: ", len(candidates))
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && line == "" {
+		return model.Entry{}, fmt.Errorf("no selection made")
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || n < 1 || n > len(candidates) {
+		return model.Entry{}, fmt.Errorf("invalid selection %q", strings.TrimSpace(line))
+	}
+	return candidates[n-1], nil
+}
+
+// derefOr returns *s, or def when s is nil.
+func derefOr(s *string, def string) string {
+	if s == nil {
+		return def
+	}
+	return *s
+}
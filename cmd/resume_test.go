@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+)
+
+func TestRecentPairs(t *testing.T) {
+	store := storage.NewMemoryStore()
+	task := "review"
+	at := func(d, h int) time.Time { return time.Date(2026, 2, d, h, 0, 0, 0, time.Local) }
+	for i, e := range []model.Entry{
+		{Project: "A", Start: at(26, 9), End: ptrTime(at(26, 10))},
+		{Project: "B", Task: &task, Start: at(26, 11), End: ptrTime(at(26, 12))},
+		{Project: "A", Start: at(27, 9), End: ptrTime(at(27, 10))},
+		{Project: "C", Start: at(27, 11)}, // still running
+	} {
+		e.ID = string(rune('a' + i))
+		e.Tags = []string{}
+		e.Source = "manual"
+		if err := store.UpdateEntry(e.Start, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := recentPairs(store, 10)
+	if err != nil {
+		t.Fatalf("recentPairs: %v", err)
+	}
+	var ids []string
+	for _, e := range got {
+		ids = append(ids, e.ID)
+	}
+	if strings.Join(ids, ",") != "c,b" {
+		t.Errorf("recentPairs IDs = %v, want [c b]", ids)
+	}
+
+	got, err = recentPairs(store, 1)
+	if err != nil || len(got) != 1 || got[0].ID != "c" {
+		t.Errorf("recentPairs(limit 1) = %v, %v; want [c]", got, err)
+	}
+}
+
+func TestPickEntry(t *testing.T) {
+	candidates := []model.Entry{{ID: "x", Project: "A"}, {ID: "y", Project: "B"}}
+
+	var out bytes.Buffer
+	got, err := pickEntry(strings.NewReader("2\n"), &out, candidates)
+	if err != nil {
+		t.Fatalf("pickEntry: %v", err)
+	}
+	if got.ID != "y" {
+		t.Errorf("pickEntry = %q, want %q", got.ID, "y")
+	}
+	if !strings.Contains(out.String(), " 1) A") {
+		t.Errorf("menu %q does not list the first candidate", out.String())
+	}
+
+	for _, input := range []string{"", "0\n", "3\n", "abc\n"} {
+		if _, err := pickEntry(strings.NewReader(input), &out, candidates); err == nil {
+			t.Errorf("pickEntry(%q): expected error, got nil", input)
+		}
+	}
+}
@@ -1,42 +1,149 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
 
 	"github.com/Tiliavir/trivial-time-tracker/internal/config"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+	"github.com/Tiliavir/trivial-time-tracker/internal/workspace"
+)
+
+// cfg holds the configuration loaded before every subcommand runs. When the
+// config file is invalid it falls back to built-in defaults; a config file
+// from a newer ttt is refused.
+var cfg config.Config
+
+// ws is the workspace selected before every subcommand runs.
+var ws workspace.Workspace
+
+// Global flags selecting where data is kept.
+var (
+	dataDirFlag   string
+	workspaceFlag string
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "ttt",
 	Short: "Trivial Time Tracker – a minimal CLI time tracker",
 	Long: `ttt is a single-binary, file-based command-line time tracker.
-All data is stored as human-readable JSON files in ~/.ttt/.`,
-	// PersistentPreRunE runs before every subcommand, ensuring ~/.ttt/config.json
+All data is stored as human-readable JSON files in ~/.ttt/, or in the
+directory given by --data-dir or $TTT_HOME. Without ~/.ttt, $XDG_DATA_HOME
+and $XDG_CONFIG_HOME are honoured. Named workspaces keep separate data and
+config; see ttt workspace.
+
+--output json or yaml makes every command print a structured result and
+report errors as {"error": {"code", "type", "message"}} on stderr.`,
+	// Errors and usage are reported by Execute, in the format selected by
+	// --output.
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	// PersistentPreRunE runs before every subcommand, ensuring the config file
 	// is created with annotated defaults on the very first invocation.
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		if _, err := config.Load(); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: config error: %v\n", err)
+		roots := rootsOrExit()
+		selected, err := roots.Select(workspaceFlag)
+		if err != nil {
+			fail(1, err)
 		}
+		ws = selected
+
+		loaded, err := config.Load(ws.ConfigFile)
+		var newer *config.NewerSchemaError
+		if errors.As(err, &newer) {
+			// Its options might mean something else to this version, e.g.
+			// select a different storage backend, so refuse to guess.
+			fail(2, err)
+		}
+		if err != nil {
+			warn("config error: %v", err)
+		}
+		cfg = loaded
 		return nil
 	},
+	// PersistentPostRun keeps the state read by ttt prompt up to date after
+	// every command that ran successfully.
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		refreshPrompt()
+	},
+}
+
+// rootsOrExit returns the directories selected by --data-dir and the
+// environment.
+func rootsOrExit() workspace.Roots {
+	roots, err := workspace.DefaultRoots(dataDirFlag)
+	if err != nil {
+		fail(2, err)
+	}
+	return roots
 }
 
 // Execute is the entry point called from main.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	if cmd, err := rootCmd.ExecuteC(); err != nil {
+		// Structured errors must be the only output on stderr.
+		if !structuredOutput() {
+			fmt.Fprintln(os.Stderr, cmd.UsageString())
+		}
+		fail(1, err)
 	}
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&dataDirFlag, "data-dir", "", "Directory holding data and config (default: $TTT_HOME or ~/.ttt)")
+	rootCmd.PersistentFlags().VarP(&output, "output", "o", "Output format: text, json or yaml")
+	rootCmd.PersistentFlags().StringVar(&workspaceFlag, "workspace", "", "Workspace to use (default: $TTT_WORKSPACE or the one chosen with ttt workspace use)")
 	rootCmd.AddCommand(startCmd)
 	rootCmd.AddCommand(stopCmd)
+	rootCmd.AddCommand(resumeCmd)
+	rootCmd.AddCommand(pauseCmd)
+	rootCmd.AddCommand(unpauseCmd)
 	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(addCmd)
+	rootCmd.AddCommand(editCmd)
+	rootCmd.AddCommand(deleteCmd)
+	rootCmd.AddCommand(trashCmd)
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(timesheetCmd)
+	rootCmd.AddCommand(overlapsCmd)
 	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(queryCmd)
+	rootCmd.AddCommand(searchCmd)
+	rootCmd.AddCommand(projectCmd)
+	rootCmd.AddCommand(storageCmd)
+	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(workspaceCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(outlookCmd)
+	rootCmd.AddCommand(promptCmd)
+	rootCmd.AddCommand(uiCmd)
+}
+
+// openStore opens the storage backend selected by the storage.backend config
+// option, with its data in the selected workspace.
+func openStore() (storage.Store, error) {
+	return storage.Open(cfg.Storage.Backend, ws.DataDir)
+}
+
+// userError marks an error caused by invalid input rather than storage
+// problems, so it maps to exit code 1 instead of 2.
+type userError struct{ msg string }
+
+func (e userError) Error() string { return e.msg }
+
+// exitCode returns the process exit code for err: 1 for user errors,
+// including entry IDs the store rejects, and 2 for everything else (storage
+// errors).
+func exitCode(err error) int {
+	var ue userError
+	var invalid *storage.InvalidIDError
+	var dup *storage.DuplicateIDError
+	if errors.As(err, &ue) || errors.As(err, &invalid) || errors.As(err, &dup) {
+		return 1
+	}
+	return 2
 }
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/search"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
+)
+
+var (
+	searchJSON  bool
+	searchLimit int
+	searchRange rangeFlags
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <terms>...",
+	Short: "Full-text search over tasks, comments, projects and tags",
+	Long: `Search the task, comment, project and tags of all stored entries.
+
+Every term must match a word or the start of a word, ignoring case. Results
+are ranked by relevance, with matches in the task weighted highest, and
+show a snippet with the matching words highlighted. The search index in
+~/.ttt/index is updated automatically from changed day files.`,
+	Example: `  ttt search mapping issue
+  ttt search deploy --month
+  ttt search review --json --limit 5`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSearch,
+}
+
+func init() {
+	searchCmd.Flags().BoolVar(&searchJSON, "json", false, "Output results as JSON, like --output json")
+	searchCmd.Flags().IntVar(&searchLimit, "limit", 20, "Maximum number of results (0 for all)")
+	searchRange.register(searchCmd)
+}
+
+// searchHit is a search result in structured output.
+type searchHit struct {
+	model.Entry
+	Score      float64  `json:"score"`
+	Snippet    string   `json:"snippet"`
+	Highlights [][2]int `json:"highlights"`
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	now := time.Now()
+	terms := strings.Join(args, " ")
+	if len(search.Tokenize(terms)) == 0 {
+		fail(1, errors.New("search terms must contain letters or digits"))
+	}
+
+	// Default to every stored entry when no range is given.
+	r, err := searchRange.resolve(now, func(time.Time) dateRange {
+		return dateRange{All: true, Label: "All time"}
+	})
+	if err != nil {
+		fail(1, err)
+	}
+
+	store, err := openStore()
+	if err != nil {
+		fail(2, err)
+	}
+
+	ix, err := search.Open(ws.DataDir, store)
+	if err != nil {
+		fail(2, err)
+	}
+	opts := search.Options{Limit: searchLimit}
+	if !r.All {
+		opts.From, opts.To = r.From, r.To
+	}
+	results := ix.Search(terms, opts)
+
+	if searchJSON || structuredOutput() {
+		hits := make([]searchHit, 0, len(results))
+		for _, res := range results {
+			hits = append(hits, searchHit{Entry: res.Entry, Score: res.Score, Snippet: res.Snippet, Highlights: res.Highlights})
+		}
+		printOutput(hits)
+		return nil
+	}
+
+	if len(results) == 0 {
+		fmt.Println("No matching entries.")
+		return nil
+	}
+	open, close := highlightMarkers()
+	for _, res := range results {
+		e := res.Entry
+		durStr := ""
+		if e.DurationSeconds != nil {
+			durStr = fmt.Sprintf(" (%s)", timecalc.FormatDuration(*e.DurationSeconds))
+		}
+		task := ""
+		if e.Task != nil {
+			task = "  " + *e.Task
+		}
+		fmt.Printf("%s %s  %s%s%s  %s\n", e.Start.Format("2006-01-02"), e.Start.Format("15:04"),
+			e.Project, task, durStr, e.ID)
+		fmt.Printf("    %s\n", highlight(res.Snippet, res.Highlights, open, close))
+	}
+	return nil
+}
+
+// highlightMarkers returns the strings placed around matched words: bold
+// when writing to a terminal, Markdown emphasis otherwise. NO_COLOR disables
+// terminal styling.
+func highlightMarkers() (string, string) {
+	if fi, err := os.Stdout.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 && os.Getenv("NO_COLOR") == "" {
+		return "\x1b[1m", "\x1b[0m"
+	}
+	return "**", "**"
+}
+
+// highlight wraps the given byte ranges of s in open and close.
+func highlight(s string, ranges [][2]int, open, close string) string {
+	var b strings.Builder
+	last := 0
+	for _, r := range ranges {
+		b.WriteString(s[last:r[0]])
+		b.WriteString(open + s[r[0]:r[1]] + close)
+		last = r[1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
@@ -17,52 +16,49 @@ var (
 	startTask    string
 	startComment string
 	startTags    string
+	startAt      string
+	startAgo     string
 )
 
 var startCmd = &cobra.Command{
 	Use:   "start <project>",
 	Short: "Start a new time entry",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runStart,
+	Example: `  ttt start ECM --task "REST refactor"
+  ttt start ECM --at 08:45
+  ttt start ECM --ago 20m`,
+	Args: cobra.ExactArgs(1),
+	RunE: runStart,
 }
 
 func init() {
 	startCmd.Flags().StringVar(&startTask, "task", "", "Task description")
 	startCmd.Flags().StringVar(&startComment, "comment", "", "Optional comment")
 	startCmd.Flags().StringVar(&startTags, "tags", "", "Comma-separated tags")
+	startCmd.Flags().StringVar(&startAt, "at", "", "Start time, e.g. 08:45, 9am, \"yesterday 14:30\" or now-15m")
+	startCmd.Flags().StringVar(&startAgo, "ago", "", "Start this long ago, e.g. 20m")
 }
 
 func runStart(cmd *cobra.Command, args []string) error {
 	project := args[0]
-	now := time.Now()
-
-	base, err := storage.BaseDir()
+	now, err := resolveAt(time.Now(), startAt, startAgo)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(2)
+		fail(1, err)
 	}
 
-	// Check for an existing active timer and auto-stop it.
-	active, activeDay, err := storage.FindActiveEntry(base)
+	store, err := openStore()
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(2)
+		fail(2, err)
 	}
-	if active != nil {
-		fmt.Fprintf(os.Stderr, "Warning: auto-stopping active timer for project %q\n", active.Project)
-		if err := stopEntry(base, active, activeDay, now, nil); err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(2)
-		}
+
+	project, registered, err := resolveProject(store, project)
+	if err != nil {
+		fail(exitCode(err), err)
 	}
 
 	// Build new entry.
 	entry := model.Entry{
-		ID:      timecalc.GenerateID(now),
 		Project: project,
-		Tags:    []string{},
-		Start:   now,
-		Source:  "manual",
+		Tags:    defaultTags(registered),
 	}
 	if startTask != "" {
 		entry.Task = &startTask
@@ -71,26 +67,60 @@ func runStart(cmd *cobra.Command, args []string) error {
 		entry.Comment = &startComment
 	}
 	if startTags != "" {
-		parts := strings.Split(startTags, ",")
-		for i, p := range parts {
-			parts[i] = strings.TrimSpace(p)
-		}
-		entry.Tags = parts
+		entry.Tags = parseTags(startTags)
 	}
 
-	// Handle midnight crossover: if now is midnight exactly or start spans midnight,
-	// we simply store on the current day as usual; crossover is handled at stop time.
-	if err := storage.UpdateEntry(base, now, entry); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(2)
+	entry, stopped, err := startEntry(store, entry, now)
+	if err != nil {
+		fail(exitCode(err), err)
 	}
 
+	if structuredOutput() {
+		printOutput(entryOutput{Entry: entry, Stopped: stopped})
+		return nil
+	}
 	fmt.Printf("Started timer for project %q at %s\n", project, now.Format("15:04:05"))
 	return nil
 }
 
+// startEntry auto-stops any active timer at now and stores entry as a new
+// open entry starting at now. ID, Start and Source are filled in here. The
+// auto-stopped entry, if any, is returned as well.
+func startEntry(store storage.Store, entry model.Entry, now time.Time) (model.Entry, *model.Entry, error) {
+	// Check for an existing active timer and auto-stop it.
+	active, activeDay, err := store.FindActiveEntry()
+	if err != nil {
+		return entry, nil, err
+	}
+	if active != nil {
+		if now.Before(active.Start) {
+			return entry, nil, userError{fmt.Sprintf("the active timer for project %q started at %s, after the requested start time",
+				active.Project, active.Start.Format("2006-01-02 15:04"))}
+		}
+		warn("auto-stopping active timer for project %q", active.Project)
+		if err := stopEntry(store, active, activeDay, now, nil); err != nil {
+			return entry, nil, err
+		}
+	}
+
+	entry.ID = timecalc.GenerateID(now)
+	entry.ExternalID = ""
+	entry.Start = now
+	entry.End = nil
+	entry.DurationSeconds = nil
+	entry.Breaks = nil
+	entry.Source = "manual"
+	if entry.Tags == nil {
+		entry.Tags = []string{}
+	}
+
+	// Handle midnight crossover: if now is midnight exactly or start spans midnight,
+	// we simply store on the current day as usual; crossover is handled at stop time.
+	return entry, active, store.UpdateEntry(now, entry)
+}
+
 // stopEntry closes an entry, handling midnight crossover by splitting if necessary.
-func stopEntry(base string, entry *model.Entry, entryDay time.Time, stopTime time.Time, comment *string) error {
+func stopEntry(store storage.Store, entry *model.Entry, entryDay time.Time, stopTime time.Time, comment *string) error {
 	if comment != nil && *comment != "" {
 		if entry.Comment != nil {
 			merged := *entry.Comment + "\n" + *comment
@@ -100,42 +130,83 @@ func stopEntry(base string, entry *model.Entry, entryDay time.Time, stopTime tim
 		}
 	}
 
+	// Stopping a paused timer ends it where the pause began at the latest,
+	// closing the pause there.
+	if b := openBreak(entry); b != nil {
+		if b.Start.Before(stopTime) {
+			stopTime = b.Start
+		}
+		end := stopTime
+		b.End = &end
+	}
+
 	// Check for midnight crossover.
 	if !timecalc.SameDay(entry.Start, stopTime) {
-		return splitAcrossMidnight(base, entry, entryDay, stopTime, comment)
+		return splitAcrossMidnight(store, entry, entryDay, stopTime, comment)
 	}
 
+	// Stored breaks never extend past the end; the closed pause has no
+	// length left and is dropped.
+	entry.Breaks = model.ClipBreaks(entry.Breaks, entry.Start, stopTime)
 	end := stopTime
-	dur := int64(stopTime.Sub(entry.Start).Seconds())
+	dur := model.WorkedSeconds(entry.Start, stopTime, entry.Breaks)
 	entry.End = &end
 	entry.DurationSeconds = &dur
-	return storage.UpdateEntry(base, entryDay, *entry)
+	return store.UpdateEntry(entryDay, *entry)
 }
 
-// splitAcrossMidnight splits a cross-midnight entry into two entries.
-func splitAcrossMidnight(base string, entry *model.Entry, entryDay time.Time, stopTime time.Time, comment *string) error {
+// splitAcrossMidnight splits a cross-midnight entry into one entry per
+// calendar day it covers. Breaks are clipped to the segment they fall into,
+// so a break spanning midnight is shared between both days.
+func splitAcrossMidnight(store storage.Store, entry *model.Entry, entryDay time.Time, stopTime time.Time, comment *string) error {
+	breaks := entry.Breaks
+
 	// First segment ends at 23:59:59 of the start day.
 	endOfFirst := timecalc.EndOfDay(entry.Start)
-	dur1 := int64(endOfFirst.Sub(entry.Start).Seconds())
+	entry.Breaks = model.ClipBreaks(breaks, entry.Start, endOfFirst)
+	dur1 := model.WorkedSeconds(entry.Start, endOfFirst, entry.Breaks)
 	entry.End = &endOfFirst
 	entry.DurationSeconds = &dur1
-	if err := storage.UpdateEntry(base, entryDay, *entry); err != nil {
+	if err := store.UpdateEntry(entryDay, *entry); err != nil {
 		return err
 	}
 
-	// Second segment starts at 00:00:00 of the stop day.
-	startOfSecond := timecalc.StartOfDay(stopTime)
-	dur2 := int64(stopTime.Sub(startOfSecond).Seconds())
-	second := model.Entry{
-		ID:              timecalc.GenerateID(startOfSecond),
-		Project:         entry.Project,
-		Task:            entry.Task,
-		Comment:         entry.Comment,
-		Tags:            entry.Tags,
-		Start:           startOfSecond,
-		End:             &stopTime,
-		DurationSeconds: &dur2,
-		Source:          entry.Source,
-	}
-	return storage.UpdateEntry(base, stopTime, second)
+	// Each following segment starts at 00:00:00 and ends at 23:59:59, except
+	// the last one, which ends at the stop time.
+	for day := timecalc.Midnight(entry.Start); !day.After(stopTime); day = timecalc.Midnight(day) {
+		end := timecalc.EndOfDay(day)
+		if timecalc.SameDay(day, stopTime) {
+			end = stopTime
+		}
+		segBreaks := model.ClipBreaks(breaks, day, end)
+		dur := model.WorkedSeconds(day, end, segBreaks)
+		segment := model.Entry{
+			ID:              timecalc.GenerateID(day),
+			Project:         entry.Project,
+			Task:            entry.Task,
+			Comment:         entry.Comment,
+			Tags:            entry.Tags,
+			Start:           day,
+			End:             &end,
+			DurationSeconds: &dur,
+			Source:          entry.Source,
+			Breaks:          segBreaks,
+		}
+		if err := store.UpdateEntry(day, segment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseTags splits a comma-separated tag list, trimming whitespace and
+// dropping empty items.
+func parseTags(s string) []string {
+	tags := []string{}
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			tags = append(tags, p)
+		}
+	}
+	return tags
 }
@@ -2,12 +2,11 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/spf13/cobra"
 
-	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
 	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
 )
 
@@ -18,48 +17,82 @@ var statusCmd = &cobra.Command{
 	RunE:  runStatus,
 }
 
+// statusOutput is the structured output of ttt status.
+type statusOutput struct {
+	// Running is set while a timer is active, also when it is paused.
+	Running bool `json:"running"`
+	Paused  bool `json:"paused"`
+	// Entry is the active entry, or null.
+	Entry *model.Entry `json:"entry"`
+	// ElapsedSeconds is the time worked on the active entry, without breaks.
+	ElapsedSeconds int64 `json:"elapsed_seconds"`
+	// TodaySeconds is the time logged today, including the running timer.
+	TodaySeconds int64 `json:"today_seconds"`
+}
+
 func runStatus(cmd *cobra.Command, args []string) error {
 	now := time.Now()
 
-	base, err := storage.BaseDir()
+	store, err := openStore()
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(2)
+		fail(2, err)
 	}
 
-	active, _, err := storage.FindActiveEntry(base)
+	active, _, err := store.FindActiveEntry()
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(2)
+		fail(2, err)
+	}
+
+	// Today's total counts closed entries from today's day file and the part
+	// of the active timer since midnight.
+	df, err := store.LoadDay(now)
+	if err != nil {
+		fail(2, err)
+	}
+	var todaySeconds int64
+	for _, e := range df.Entries {
+		if e.DurationSeconds != nil {
+			todaySeconds += *e.DurationSeconds
+		}
+	}
+	if active != nil {
+		since := active.Start
+		if midnight := timecalc.StartOfDay(now); since.Before(midnight) {
+			since = midnight
+		}
+		todaySeconds += model.WorkedSeconds(since, now, active.Breaks)
+	}
+
+	if structuredOutput() {
+		out := statusOutput{Running: active != nil, Entry: active, TodaySeconds: todaySeconds}
+		if active != nil {
+			out.Paused = openBreak(active) != nil
+			out.ElapsedSeconds = model.WorkedSeconds(active.Start, now, active.Breaks)
+		}
+		printOutput(out)
+		return nil
 	}
 
 	if active != nil {
-		elapsed := int64(now.Sub(active.Start).Seconds())
-		fmt.Println("Running:")
+		elapsed := model.WorkedSeconds(active.Start, now, active.Breaks)
+		paused := openBreak(active)
+		if paused != nil {
+			fmt.Println("Paused:")
+		} else {
+			fmt.Println("Running:")
+		}
 		fmt.Printf("  Project: %s\n", active.Project)
 		if active.Task != nil {
 			fmt.Printf("  Task: %s\n", *active.Task)
 		}
 		fmt.Printf("  Since: %s\n", active.Start.Format("15:04"))
 		fmt.Printf("  Elapsed: %s\n", timecalc.FormatDurationHHMMSS(elapsed))
-		return nil
-	}
-
-	// Idle — show today's total.
-	df, err := storage.LoadDay(base, now)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(2)
-	}
-
-	var totalSeconds int64
-	for _, e := range df.Entries {
-		if e.DurationSeconds != nil {
-			totalSeconds += *e.DurationSeconds
+		if paused != nil {
+			fmt.Printf("  Paused since %s\n", paused.Start.Format("15:04"))
 		}
+	} else {
+		fmt.Println("No active timer.")
 	}
-
-	fmt.Println("No active timer.")
-	fmt.Printf("Today: %s logged.\n", timecalc.FormatDuration(totalSeconds))
+	fmt.Printf("Today: %s logged.\n", timecalc.FormatDuration(todaySeconds))
 	return nil
 }
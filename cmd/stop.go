@@ -1,16 +1,20 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/spf13/cobra"
 
-	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
 )
 
-var stopComment string
+var (
+	stopComment string
+	stopAt      string
+	stopAgo     string
+)
 
 var stopCmd = &cobra.Command{
 	Use:   "stop",
@@ -21,25 +25,39 @@ var stopCmd = &cobra.Command{
 
 func init() {
 	stopCmd.Flags().StringVar(&stopComment, "comment", "", "Append a comment to the entry")
+	stopCmd.Flags().StringVar(&stopAt, "at", "", "Stop time, e.g. 17:10, 5pm or now-10m")
+	stopCmd.Flags().StringVar(&stopAgo, "ago", "", "Stop this long ago, e.g. 10m")
+}
+
+// stopOutput is the structured output of ttt stop. Entry is the stopped
+// entry, or its first day when it was split at midnight; ElapsedSeconds
+// covers all days.
+type stopOutput struct {
+	Entry          model.Entry `json:"entry"`
+	ElapsedSeconds int64       `json:"elapsed_seconds"`
 }
 
 func runStop(cmd *cobra.Command, args []string) error {
-	now := time.Now()
+	now, err := resolveAt(time.Now(), stopAt, stopAgo)
+	if err != nil {
+		fail(1, err)
+	}
 
-	base, err := storage.BaseDir()
+	store, err := openStore()
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(2)
+		fail(2, err)
 	}
 
-	active, activeDay, err := storage.FindActiveEntry(base)
+	active, activeDay, err := store.FindActiveEntry()
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(2)
+		fail(2, err)
 	}
 	if active == nil {
-		fmt.Fprintln(os.Stderr, "No active timer to stop.")
-		os.Exit(1)
+		fail(1, errors.New("no active timer to stop"))
+	}
+
+	if err := checkStopTime(active, now); err != nil {
+		fail(1, err)
 	}
 
 	var comment *string
@@ -47,17 +65,30 @@ func runStop(cmd *cobra.Command, args []string) error {
 		comment = &stopComment
 	}
 
-	if err := stopEntry(base, active, activeDay, now, comment); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(2)
+	// Measure before stopping: stopEntry may split the entry at midnight.
+	elapsed := model.WorkedSeconds(active.Start, now, active.Breaks)
+
+	if err := stopEntry(store, active, activeDay, now, comment); err != nil {
+		fail(2, err)
 	}
 
-	elapsed := int64(now.Sub(active.Start).Seconds())
+	if structuredOutput() {
+		printOutput(stopOutput{Entry: *active, ElapsedSeconds: elapsed})
+		return nil
+	}
 	fmt.Printf("Stopped timer for project %q. Elapsed: %s\n",
 		active.Project, formatElapsed(elapsed))
 	return nil
 }
 
+// checkStopTime rejects stopping the active timer at or before its start.
+func checkStopTime(active *model.Entry, now time.Time) error {
+	if !now.After(active.Start) {
+		return fmt.Errorf("stop time must be after the timer's start at %s", active.Start.Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
 func formatElapsed(seconds int64) string {
 	h := seconds / 3600
 	m := (seconds % 3600) / 60
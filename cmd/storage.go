@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+)
+
+var (
+	migrateFrom    string
+	migrateTo      string
+	migrateReplace bool
+)
+
+var storageCmd = &cobra.Command{
+	Use:   "storage",
+	Short: "Manage the storage backend",
+}
+
+var storageMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Copy all data to another storage backend",
+	Long: `Copy every entry, trashed entry and the project registry from one storage
+backend to another. The source is left untouched. The target must be empty
+unless --replace is given, which deletes its data first.
+
+Afterwards, select the new backend in the config file, e.g. ~/.ttt/config.json:
+
+  "storage": { "backend": "sqlite" }`,
+	Example: `  ttt storage migrate --to sqlite
+  ttt storage migrate --to json --replace`,
+	Args: cobra.NoArgs,
+	RunE: runStorageMigrate,
+}
+
+func init() {
+	storageMigrateCmd.Flags().StringVar(&migrateFrom, "from", "", "Source backend (default: the configured backend)")
+	storageMigrateCmd.Flags().StringVar(&migrateTo, "to", "", "Target backend: json or sqlite")
+	storageMigrateCmd.Flags().BoolVar(&migrateReplace, "replace", false, "Delete existing data in the target first")
+	storageCmd.AddCommand(storageMigrateCmd)
+}
+
+// storageMigrateOutput is the structured output of ttt storage migrate.
+type storageMigrateOutput struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Entries int    `json:"entries"`
+	Trashed int    `json:"trashed"`
+}
+
+func runStorageMigrate(cmd *cobra.Command, args []string) error {
+	from := migrateFrom
+	if from == "" {
+		from = cfg.Storage.Backend
+	}
+	if from == "" {
+		from = storage.BackendJSON
+	}
+	if migrateTo == "" {
+		fail(1, errors.New("--to is required"))
+	}
+	if migrateTo == from {
+		fail(1, fmt.Errorf("source and target are both %q", from))
+	}
+
+	base := ws.DataDir
+	src, err := storage.Open(from, base)
+	if err != nil {
+		fail(1, err)
+	}
+	defer closeStore(src)
+	dst, err := storage.Open(migrateTo, base)
+	if err != nil {
+		fail(1, err)
+	}
+	defer closeStore(dst)
+
+	// Check before clearing the target, so a refused migration keeps it.
+	if err := storage.CheckUniqueIDs(src); err != nil {
+		fail(exitCode(err), err)
+	}
+	empty, err := storage.IsEmpty(dst)
+	if err != nil {
+		fail(2, err)
+	}
+	if !empty {
+		if !migrateReplace {
+			fail(1, fmt.Errorf("the %s backend already holds data; use --replace to overwrite it", migrateTo))
+		}
+		if err := storage.Clear(dst); err != nil {
+			fail(2, err)
+		}
+	}
+
+	entries, trashed, err := storage.Copy(dst, src)
+	if err != nil {
+		fail(exitCode(err), err)
+	}
+
+	if structuredOutput() {
+		printOutput(storageMigrateOutput{From: from, To: migrateTo, Entries: entries, Trashed: trashed})
+		return nil
+	}
+	fmt.Printf("Migrated %d entries and %d deleted entries from %s to %s.\n", entries, trashed, from, migrateTo)
+	if cfg.Storage.Backend != migrateTo {
+		fmt.Printf("To use it, set \"storage\": {\"backend\": %q} in %s.\n", migrateTo, ws.ConfigFile)
+	}
+	return nil
+}
+
+// closeStore closes backends that hold open resources, such as a database.
+func closeStore(s storage.Store) {
+	if c, ok := s.(io.Closer); ok {
+		c.Close()
+	}
+}
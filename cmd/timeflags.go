@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/timeparse"
+)
+
+// resolveAt resolves the --at and --ago flags shared by start and stop into
+// a point in time, defaulting to now. Times in the future are rejected.
+func resolveAt(now time.Time, at, ago string) (time.Time, error) {
+	switch {
+	case at != "" && ago != "":
+		return time.Time{}, userError{"--at and --ago cannot be combined"}
+	case at != "":
+		t, err := timeparse.Time(at, now)
+		if err != nil {
+			return time.Time{}, userError{fmt.Sprintf("invalid --at: %v", err)}
+		}
+		if t.After(now) {
+			return time.Time{}, userError{fmt.Sprintf("--at %s is in the future", t.Format("2006-01-02 15:04"))}
+		}
+		return t, nil
+	case ago != "":
+		d, err := timeparse.Duration(ago)
+		if err != nil {
+			return time.Time{}, userError{fmt.Sprintf("invalid --ago: %v", err)}
+		}
+		return now.Add(-d), nil
+	default:
+		return now, nil
+	}
+}
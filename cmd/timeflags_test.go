@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolveAt(t *testing.T) {
+	now := time.Date(2026, 2, 27, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		at, ago string
+		want    time.Time
+	}{
+		{"", "", now},
+		{"08:45", "", time.Date(2026, 2, 27, 8, 45, 0, 0, time.UTC)},
+		{"yesterday 17:10", "", time.Date(2026, 2, 26, 17, 10, 0, 0, time.UTC)},
+		{"", "20m", now.Add(-20 * time.Minute)},
+	}
+	for _, tt := range tests {
+		got, err := resolveAt(now, tt.at, tt.ago)
+		if err != nil {
+			t.Errorf("resolveAt(%q, %q): %v", tt.at, tt.ago, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("resolveAt(%q, %q) = %v, want %v", tt.at, tt.ago, got, tt.want)
+		}
+	}
+
+	for _, tt := range []struct{ at, ago string }{
+		{"08:45", "20m"},
+		{"16:00", ""},
+		{"whenever", ""},
+		{"", "soon"},
+	} {
+		if _, err := resolveAt(now, tt.at, tt.ago); err == nil || exitCode(err) != 1 {
+			t.Errorf("resolveAt(%q, %q) err = %v, want user error", tt.at, tt.ago, err)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/overlap"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timesheet"
+)
+
+var (
+	timesheetFormat string
+	timesheetRange  rangeFlags
+	timesheetDedupe bool
+)
+
+var timesheetCmd = &cobra.Command{
+	Use:   "timesheet",
+	Short: "Compare worked time per day with your target hours",
+	Long: `List every day of the range with the time worked, the daily target, the
+overtime of the day and the running balance since the first day shown.
+
+Targets come from the timesheet section of the config: weekly_hours is
+split evenly across working_days, except for weekdays with a fixed target
+in overrides. Days without a target still count the time worked on them.
+
+Notes flag past working days without entries, tracked days off and the
+day of the running timer, which counts until now. Days after today are
+listed with their target but left out of the balance.`,
+	Example: `  ttt timesheet
+  ttt timesheet --month
+  ttt timesheet --last-week --format csv`,
+	Args: cobra.NoArgs,
+	RunE: runTimesheet,
+}
+
+func init() {
+	timesheetCmd.Flags().StringVar(&timesheetFormat, "format", "md", "Output format: md, csv, json")
+	timesheetCmd.Flags().BoolVar(&timesheetDedupe, "dedupe-overlaps", false, "Count time covered by several entries only once")
+	timesheetRange.register(timesheetCmd)
+	timesheetCmd.Flags().Lookup("week").Usage = "This week (default)"
+}
+
+func runTimesheet(cmd *cobra.Command, args []string) error {
+	now := time.Now()
+	format := formatFor(cmd, timesheetFormat)
+
+	tc := cfg.Timesheet
+	targets, err := timesheet.NewTargets(tc.WeeklyHours, tc.WorkingDays, tc.Overrides)
+	if err != nil {
+		fail(1, fmt.Errorf("invalid timesheet config in %s: %w", ws.ConfigFile, err))
+	}
+
+	// Default to this week when no range is given.
+	r, err := timesheetRange.resolve(now, weekRange)
+	if err != nil {
+		fail(1, err)
+	}
+	if r.All {
+		fail(1, errors.New("ttt timesheet needs a bounded range; --all is not supported"))
+	}
+
+	store, err := openStore()
+	if err != nil {
+		fail(2, err)
+	}
+	entries, err := loadEntries(store, r)
+	if err != nil {
+		fail(2, err)
+	}
+	if timesheetDedupe {
+		entries = overlap.Dedupe(entries, overlap.DefaultPriority)
+	}
+
+	s := timesheet.Build(entries, r.From, r.To, targets, now)
+	switch format {
+	case "csv":
+		printTimesheetCSV(s)
+	case "json":
+		printOutput(newTimesheetOutput(r, s))
+	default: // md
+		printTimesheetMD(s, r.Label)
+	}
+	return nil
+}
+
+// timesheetOutput is the JSON form of a timesheet, in minutes like the
+// other reports. Its totals leave out upcoming days.
+type timesheetOutput struct {
+	periodOutput
+	Days           []timesheetDay `json:"days"`
+	WorkedMinutes  int64          `json:"worked_minutes"`
+	TargetMinutes  int64          `json:"target_minutes"`
+	BalanceMinutes int64          `json:"balance_minutes"`
+}
+
+type timesheetDay struct {
+	Date                  string           `json:"date"`
+	Weekday               string           `json:"weekday"`
+	WorkedMinutes         int64            `json:"worked_minutes"`
+	TargetMinutes         int64            `json:"target_minutes"`
+	BalanceMinutes        int64            `json:"balance_minutes"`
+	RunningBalanceMinutes int64            `json:"running_balance_minutes"`
+	Entries               int              `json:"entries"`
+	Flags                 []timesheet.Flag `json:"flags"`
+}
+
+func newTimesheetOutput(r dateRange, s timesheet.Sheet) timesheetOutput {
+	out := timesheetOutput{
+		periodOutput:   newPeriodOutput(r),
+		Days:           []timesheetDay{},
+		WorkedMinutes:  s.Worked / 60,
+		TargetMinutes:  s.Target / 60,
+		BalanceMinutes: s.Balance / 60,
+	}
+	for _, d := range s.Days {
+		day := timesheetDay{
+			Date:                  d.Date.Format("2006-01-02"),
+			Weekday:               d.Date.Weekday().String(),
+			WorkedMinutes:         d.Worked / 60,
+			TargetMinutes:         d.Target / 60,
+			BalanceMinutes:        d.Balance / 60,
+			RunningBalanceMinutes: d.Running / 60,
+			Entries:               d.Entries,
+			Flags:                 d.Flags,
+		}
+		if day.Flags == nil {
+			day.Flags = []timesheet.Flag{}
+		}
+		out.Days = append(out.Days, day)
+	}
+	return out
+}
+
+// upcoming reports whether d lies after today and is left out of the
+// balance.
+func upcoming(d timesheet.Day) bool {
+	for _, f := range d.Flags {
+		if f == timesheet.Upcoming {
+			return true
+		}
+	}
+	return false
+}
+
+// signedDuration formats a balance with its sign, e.g. "+30m" or "-7h 30m".
+func signedDuration(seconds int64) string {
+	switch {
+	case seconds > 0:
+		return "+" + timecalc.FormatDuration(seconds)
+	case seconds < 0:
+		return "-" + timecalc.FormatDuration(-seconds)
+	}
+	return "0"
+}
+
+// printTimesheetMD prints the timesheet as an aligned table with a note
+// column for the flags and a total row.
+func printTimesheetMD(s timesheet.Sheet, label string) {
+	lines := [][]string{{"Day", "Worked", "Target", "Balance", "Running", "Note"}}
+	for _, d := range s.Days {
+		worked, balance, running := "-", signedDuration(d.Balance), signedDuration(d.Running)
+		if d.Worked > 0 {
+			worked = timecalc.FormatDuration(d.Worked)
+		}
+		target := "-"
+		if d.Target > 0 {
+			target = timecalc.FormatDuration(d.Target)
+		}
+		if upcoming(d) {
+			balance, running = "", ""
+		}
+		notes := make([]string, len(d.Flags))
+		for i, f := range d.Flags {
+			notes[i] = strings.ReplaceAll(string(f), "_", " ")
+		}
+		lines = append(lines, []string{d.Date.Format("Mon 01-02"), worked, target, balance, running, strings.Join(notes, ", ")})
+	}
+	totals := []string{"Total", timecalc.FormatDuration(s.Worked), timecalc.FormatDuration(s.Target), signedDuration(s.Balance), "", ""}
+
+	widths := make([]int, len(lines[0]))
+	for _, l := range append(lines, totals) {
+		for i, c := range l {
+			if n := len([]rune(c)); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	// The day and note columns are left-aligned, the durations right-aligned.
+	printRow := func(l []string) {
+		var b strings.Builder
+		for i, c := range l {
+			switch i {
+			case 0:
+				fmt.Fprintf(&b, "%-*s", widths[i], c)
+			case len(l) - 1:
+				fmt.Fprintf(&b, "  %s", c)
+			default:
+				fmt.Fprintf(&b, "%*s", widths[i]+2, c)
+			}
+		}
+		fmt.Println(strings.TrimRight(b.String(), " "))
+	}
+	rule := widths[0]
+	for _, w := range widths[1 : len(widths)-1] {
+		rule += w + 2
+	}
+
+	fmt.Println(label)
+	printRow(lines[0])
+	fmt.Println(strings.Repeat("-", rule))
+	for _, l := range lines[1:] {
+		printRow(l)
+	}
+	fmt.Println(strings.Repeat("-", rule))
+	printRow(totals)
+}
+
+// printTimesheetCSV prints one row per day in minutes, with the flags
+// separated by spaces, and a final total row.
+func printTimesheetCSV(s timesheet.Sheet) {
+	fmt.Println("date,weekday,worked_minutes,target_minutes,balance_minutes,running_balance_minutes,flags")
+	for _, d := range s.Days {
+		flags := make([]string, len(d.Flags))
+		for i, f := range d.Flags {
+			flags[i] = string(f)
+		}
+		fmt.Printf("%s,%s,%d,%d,%d,%d,%s\n", d.Date.Format("2006-01-02"), d.Date.Weekday(),
+			d.Worked/60, d.Target/60, d.Balance/60, d.Running/60, strings.Join(flags, " "))
+	}
+	fmt.Printf("total,,%d,%d,%d,,\n", s.Worked/60, s.Target/60, s.Balance/60)
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timeparse"
+)
+
+var (
+	trashPurgeAll       bool
+	trashPurgeOlderThan string
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Manage deleted entries",
+}
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List deleted entries",
+	Args:  cobra.NoArgs,
+	RunE:  runTrashList,
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <id>...",
+	Short: "Restore deleted entries",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runTrashRestore,
+}
+
+var trashPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Permanently remove deleted entries",
+	Example: `  ttt trash purge --all
+  ttt trash purge --older-than 30d`,
+	Args: cobra.NoArgs,
+	RunE: runTrashPurge,
+}
+
+func init() {
+	trashPurgeCmd.Flags().BoolVar(&trashPurgeAll, "all", false, "Purge every deleted entry")
+	trashPurgeCmd.Flags().StringVar(&trashPurgeOlderThan, "older-than", "", "Purge entries deleted longer ago than this duration, e.g. 30d")
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashPurgeCmd)
+}
+
+func runTrashList(cmd *cobra.Command, args []string) error {
+	store, err := openStore()
+	if err != nil {
+		fail(2, err)
+	}
+
+	trashed, err := store.ListTrash()
+	if err != nil {
+		fail(2, err)
+	}
+	if structuredOutput() {
+		if trashed == nil {
+			trashed = []model.TrashedEntry{}
+		}
+		printOutput(trashed)
+		return nil
+	}
+	if len(trashed) == 0 {
+		fmt.Println("Trash is empty.")
+		return nil
+	}
+
+	for _, t := range trashed {
+		e := t.Entry
+		durStr := ""
+		if e.DurationSeconds != nil {
+			durStr = fmt.Sprintf(" (%s)", timecalc.FormatDuration(*e.DurationSeconds))
+		}
+		task := ""
+		if e.Task != nil {
+			task = "  " + *e.Task
+		}
+		fmt.Printf("%s  %s %s  %s%s%s  [deleted %s]\n", e.ID,
+			e.Start.Format("2006-01-02"), e.Start.Format("15:04"), e.Project, task, durStr,
+			t.DeletedAt.Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+func runTrashRestore(cmd *cobra.Command, args []string) error {
+	store, err := openStore()
+	if err != nil {
+		fail(2, err)
+	}
+
+	out := trashRestoreOutput{Restored: []model.Entry{}, Missing: []string{}}
+	for _, id := range args {
+		e, err := store.RestoreEntry(id)
+		if err != nil {
+			fail(exitCode(err), err)
+		}
+		if e == nil {
+			out.Missing = append(out.Missing, id)
+			continue
+		}
+		out.Restored = append(out.Restored, *e)
+		if !structuredOutput() {
+			fmt.Printf("Restored entry %s (%s, %s %s).\n", e.ID, e.Project,
+				e.Start.Format("2006-01-02"), e.Start.Format("15:04"))
+		}
+	}
+	if structuredOutput() {
+		printOutput(out)
+	}
+	if len(out.Missing) > 0 {
+		fail(1, fmt.Errorf("no deleted entry with ID %s", strings.Join(out.Missing, ", ")))
+	}
+	return nil
+}
+
+// trashRestoreOutput is the structured output of ttt trash restore. IDs
+// without a deleted entry are listed in Missing and make it exit with 1.
+type trashRestoreOutput struct {
+	Restored []model.Entry `json:"restored"`
+	Missing  []string      `json:"missing"`
+}
+
+func runTrashPurge(cmd *cobra.Command, args []string) error {
+	var cutoff time.Time
+	switch {
+	case trashPurgeAll && trashPurgeOlderThan == "":
+	case !trashPurgeAll && trashPurgeOlderThan != "":
+		d, err := timeparse.Duration(trashPurgeOlderThan)
+		if err != nil {
+			fail(1, fmt.Errorf("invalid --older-than %q", trashPurgeOlderThan))
+		}
+		cutoff = time.Now().Add(-d)
+	default:
+		fail(1, errors.New("specify either --all or --older-than"))
+	}
+
+	store, err := openStore()
+	if err != nil {
+		fail(2, err)
+	}
+
+	n, err := store.PurgeTrash(cutoff)
+	if err != nil {
+		fail(2, err)
+	}
+	if structuredOutput() {
+		printOutput(countOutput{Count: n})
+		return nil
+	}
+	fmt.Printf("Purged %d deleted entries.\n", n)
+	return nil
+}
@@ -0,0 +1,433 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
+)
+
+var uiDate string
+
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: "Browse and edit entries in a full-screen terminal UI",
+	Long: `Show one day of entries as a timeline, with the running timer ticking
+live and a report pane aggregating the day or week like ttt report.
+
+Keys:
+` + uiKeyHelp + `
+
+Merging keeps the time between both entries as a break, so the worked time
+does not change. Deleted and merged-away entries can be restored with
+ttt trash restore. Changes made by other ttt commands show up within a few
+seconds.`,
+	Example: `  ttt ui
+  ttt ui --date yesterday`,
+	Args: cobra.NoArgs,
+	RunE: runUI,
+}
+
+func init() {
+	uiCmd.Flags().StringVar(&uiDate, "date", "", "Day to show first (YYYY-MM-DD, yesterday, mon, ...)")
+}
+
+func runUI(cmd *cobra.Command, args []string) error {
+	if structuredOutput() {
+		fail(1, errors.New("ttt ui is interactive and has no structured output"))
+	}
+	now := time.Now()
+	day := now
+	if uiDate != "" {
+		d, err := parseDateFlag("date", uiDate, now)
+		if err != nil {
+			fail(1, err)
+		}
+		day = d
+	}
+
+	store, err := openStore()
+	if err != nil {
+		fail(2, err)
+	}
+	defer closeStore(store)
+
+	m := newUIModel(store, day, time.Now)
+	if err := m.reload(); err != nil {
+		fail(2, err)
+	}
+	if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+		fail(1, err)
+	}
+	return nil
+}
+
+type uiMode int
+
+const (
+	uiBrowse  uiMode = iota
+	uiEditing        // a form is open
+	uiConfirm        // waiting for y to delete the selected entry
+	uiHelp
+)
+
+// uiReloadTicks is how many seconds pass between reloads that pick up
+// changes made by other ttt commands.
+const uiReloadTicks = 5
+
+// uiReportKeys are the groupings the report pane cycles through.
+var uiReportKeys = []string{"project", "project,task", "task", "tag", "client", "day"}
+
+// uiModel is the state of ttt ui. Every action writes through the store
+// and reloads the displayed day, so the screen always shows stored data.
+type uiModel struct {
+	store storage.Store
+	now   func() time.Time
+
+	// day is the midnight of the displayed day; entries are stored under
+	// it and sorted by start time.
+	day      time.Time
+	entries  []model.Entry
+	selected int
+	// active is the running timer, which may be stored under another day.
+	active    *model.Entry
+	activeDay time.Time
+	projects  []model.Project
+	weekTotal int64
+
+	mode uiMode
+	form *uiForm
+
+	status    string
+	statusErr bool
+	// warned collects the warnings of actions for the status line.
+	warned bytes.Buffer
+
+	showReport bool
+	reportWeek bool
+	reportKey  int
+	report     []*reportGroup
+	reportSum  int64
+	reportName string
+
+	width, height int
+	ticks         int
+}
+
+func newUIModel(store storage.Store, day time.Time, now func() time.Time) *uiModel {
+	return &uiModel{
+		store:      store,
+		now:        now,
+		day:        timecalc.StartOfDay(day),
+		showReport: true,
+		reportWeek: true,
+		width:      80,
+		height:     24,
+	}
+}
+
+// reload reads the displayed day, the running timer and the report from
+// the store, keeping the selected entry selected.
+func (m *uiModel) reload() error {
+	var selectedID string
+	if e := m.selectedEntry(); e != nil {
+		selectedID = e.ID
+	}
+
+	df, err := m.store.LoadDay(m.day)
+	if err != nil {
+		return err
+	}
+	m.entries = append(m.entries[:0], df.Entries...)
+	sort.SliceStable(m.entries, func(i, j int) bool { return m.entries[i].Start.Before(m.entries[j].Start) })
+	for i, e := range m.entries {
+		if e.ID == selectedID {
+			m.selected = i
+		}
+	}
+	m.selected = max(0, min(m.selected, len(m.entries)-1))
+
+	if m.active, m.activeDay, err = m.store.FindActiveEntry(); err != nil {
+		return err
+	}
+	if m.projects, err = m.store.LoadProjects(); err != nil {
+		return err
+	}
+
+	week, err := loadEntries(m.store, weekRange(m.day))
+	if err != nil {
+		return err
+	}
+	m.weekTotal = entriesTotal(week)
+
+	// The report pane aggregates like ttt report without filters.
+	r, entries := weekRange(m.day), week
+	if !m.reportWeek {
+		r, entries = dayRange(m.day), m.entries
+	}
+	keys, err := parseGroupBy(uiReportKeys[m.reportKey], m.projects)
+	if err != nil {
+		return err
+	}
+	m.report = groupEntries(entries, keys)
+	m.reportSum = entriesTotal(entries)
+	m.reportName = r.Label
+	return nil
+}
+
+func (m *uiModel) selectedEntry() *model.Entry {
+	if m.selected < 0 || m.selected >= len(m.entries) {
+		return nil
+	}
+	return &m.entries[m.selected]
+}
+
+func (m *uiModel) setStatus(msg string, isErr bool) {
+	m.status, m.statusErr = msg, isErr
+}
+
+// do runs an action that changes stored data and reports its result or
+// error in the status line. It reports whether the action succeeded.
+func (m *uiModel) do(action func() (string, error)) bool {
+	prev := warnings
+	warnings = &m.warned
+	msg, err := action()
+	warnings = prev
+
+	if err != nil {
+		m.setStatus(err.Error(), true)
+	} else {
+		if w := strings.TrimSpace(m.warned.String()); w != "" {
+			msg = strings.ReplaceAll(w, "\n", " ") + " " + msg
+		}
+		m.setStatus(msg, false)
+	}
+	m.warned.Reset()
+
+	if rerr := m.reload(); rerr != nil {
+		m.setStatus(rerr.Error(), true)
+	}
+	// Keep shell prompts in step with the screen.
+	if err == nil && ws.DataDir != "" {
+		_, _ = savePromptState(m.store, m.now())
+	}
+	return err == nil
+}
+
+// refresh reloads the screen, reporting errors in the status line.
+func (m *uiModel) refresh() {
+	if err := m.reload(); err != nil {
+		m.setStatus(err.Error(), true)
+	}
+}
+
+// showDay switches to the day containing t.
+func (m *uiModel) showDay(t time.Time) {
+	m.day = timecalc.StartOfDay(t)
+	m.selected = 0
+	m.entries = m.entries[:0]
+	if err := m.reload(); err != nil {
+		m.setStatus(err.Error(), true)
+		return
+	}
+	m.setStatus("", false)
+}
+
+type uiTickMsg time.Time
+
+func uiTick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg { return uiTickMsg(t) })
+}
+
+func (m *uiModel) Init() tea.Cmd {
+	return uiTick()
+}
+
+func (m *uiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case uiTickMsg:
+		m.ticks++
+		// Reloading while a form is open would not change it, so wait.
+		if m.ticks%uiReloadTicks == 0 && m.mode != uiEditing {
+			m.refresh()
+		}
+		return m, uiTick()
+	case tea.KeyMsg:
+		return m, m.key(msg)
+	}
+	return m, nil
+}
+
+// key handles a key press and returns tea.Quit to end the program.
+func (m *uiModel) key(k tea.KeyMsg) tea.Cmd {
+	if k.Type == tea.KeyCtrlC {
+		return tea.Quit
+	}
+	switch m.mode {
+	case uiEditing:
+		m.formKey(k)
+		return nil
+	case uiConfirm:
+		m.mode = uiBrowse
+		if k.String() == "y" {
+			m.do(m.deleteSelected)
+		} else {
+			m.setStatus("Not deleted.", false)
+		}
+		return nil
+	case uiHelp:
+		m.mode = uiBrowse
+		return nil
+	}
+
+	switch k.String() {
+	case "q":
+		return tea.Quit
+	case "up", "k":
+		m.selected = max(0, m.selected-1)
+	case "down", "j":
+		m.selected = max(0, min(m.selected+1, len(m.entries)-1))
+	case "left", "h":
+		m.showDay(m.day.AddDate(0, 0, -1))
+	case "right", "l":
+		m.showDay(m.day.AddDate(0, 0, 1))
+	case "[":
+		m.showDay(m.day.AddDate(0, 0, -7))
+	case "]":
+		m.showDay(m.day.AddDate(0, 0, 7))
+	case "t":
+		m.showDay(m.now())
+	case "s":
+		m.openStartForm()
+	case "x":
+		m.do(m.stop)
+	case "r":
+		m.do(m.resume)
+	case "p":
+		m.do(m.togglePause)
+	case "e", "enter":
+		m.openEditForm()
+	case "S":
+		m.openSplitForm()
+	case "m":
+		m.do(m.mergeNext)
+	case "d", "delete":
+		if e := m.selectedEntry(); e != nil {
+			m.mode = uiConfirm
+			m.setStatus("Delete "+e.Project+" "+e.Start.Format("15:04")+"? y/n", false)
+		}
+	case "tab":
+		m.showReport = !m.showReport
+	case "g":
+		m.reportKey = (m.reportKey + 1) % len(uiReportKeys)
+		m.refresh()
+	case "w":
+		m.reportWeek = !m.reportWeek
+		m.refresh()
+	case "?":
+		m.mode = uiHelp
+	}
+	return nil
+}
+
+// uiForm is an inline form of text fields. submit receives the field
+// values and returns the status message.
+type uiForm struct {
+	title  string
+	labels []string
+	fields []uiField
+	focus  int
+	// entryID is the entry the form changes, shown below its row; empty
+	// for a new entry.
+	entryID string
+	submit  func(values []string) (string, error)
+}
+
+func newUIForm(title, entryID string, labels, values []string, submit func([]string) (string, error)) *uiForm {
+	f := &uiForm{title: title, entryID: entryID, labels: labels, submit: submit}
+	for _, v := range values {
+		f.fields = append(f.fields, newUIField(v))
+	}
+	return f
+}
+
+func (f *uiForm) values() []string {
+	out := make([]string, len(f.fields))
+	for i, fd := range f.fields {
+		out[i] = string(fd.text)
+	}
+	return out
+}
+
+// formKey edits the open form: enter submits it, esc closes it, tab and the
+// arrow keys move between fields.
+func (m *uiModel) formKey(k tea.KeyMsg) {
+	f := m.form
+	switch k.Type {
+	case tea.KeyEsc:
+		m.mode, m.form = uiBrowse, nil
+		m.setStatus("", false)
+	case tea.KeyEnter:
+		if m.do(func() (string, error) { return f.submit(f.values()) }) {
+			m.mode, m.form = uiBrowse, nil
+		}
+	case tea.KeyTab, tea.KeyDown:
+		f.focus = (f.focus + 1) % len(f.fields)
+	case tea.KeyShiftTab, tea.KeyUp:
+		f.focus = (f.focus + len(f.fields) - 1) % len(f.fields)
+	default:
+		f.fields[f.focus].key(k)
+	}
+}
+
+// uiField is a single-line text input.
+type uiField struct {
+	text []rune
+	pos  int
+}
+
+func newUIField(s string) uiField {
+	r := []rune(s)
+	return uiField{text: r, pos: len(r)}
+}
+
+func (f *uiField) key(k tea.KeyMsg) {
+	switch k.Type {
+	case tea.KeyRunes, tea.KeySpace:
+		r := k.Runes
+		if k.Type == tea.KeySpace {
+			r = []rune{' '}
+		}
+		f.text = append(f.text[:f.pos], append(append([]rune{}, r...), f.text[f.pos:]...)...)
+		f.pos += len(r)
+	case tea.KeyBackspace:
+		if f.pos > 0 {
+			f.text = append(f.text[:f.pos-1], f.text[f.pos:]...)
+			f.pos--
+		}
+	case tea.KeyDelete:
+		if f.pos < len(f.text) {
+			f.text = append(f.text[:f.pos], f.text[f.pos+1:]...)
+		}
+	case tea.KeyLeft:
+		f.pos = max(0, f.pos-1)
+	case tea.KeyRight:
+		f.pos = min(len(f.text), f.pos+1)
+	case tea.KeyHome, tea.KeyCtrlA:
+		f.pos = 0
+	case tea.KeyEnd, tea.KeyCtrlE:
+		f.pos = len(f.text)
+	case tea.KeyCtrlU:
+		f.text, f.pos = f.text[f.pos:], 0
+	}
+}
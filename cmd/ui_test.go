@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+)
+
+func uiTime(h, m int) time.Time {
+	return time.Date(2026, 2, 27, h, m, 0, 0, time.Local)
+}
+
+func closedEntry(id string, from, to time.Time, breaks ...model.Break) model.Entry {
+	e := model.Entry{ID: id, Project: "ECM", Tags: []string{}, Start: from, End: &to, Source: "manual", Breaks: breaks}
+	recomputeDuration(&e)
+	return e
+}
+
+func TestSplitEntry(t *testing.T) {
+	bEnd := uiTime(9, 45)
+	e := closedEntry("a", uiTime(9, 0), uiTime(11, 0), model.Break{Start: uiTime(9, 30), End: &bEnd})
+
+	first, second, err := splitEntry(e, uiTime(10, 0), uiTime(12, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ID != "a" || !first.End.Equal(uiTime(10, 0)) || *first.DurationSeconds != 45*60 || len(first.Breaks) != 1 {
+		t.Errorf("first part = %+v, want a until 10:00 with the break, 45m", first)
+	}
+	if second.ID == "a" || !second.Start.Equal(uiTime(10, 0)) || *second.DurationSeconds != 3600 || len(second.Breaks) != 0 {
+		t.Errorf("second part = %+v, want a new entry from 10:00, 1h", second)
+	}
+
+	// A running, paused entry keeps running and paused in its second part.
+	running := model.Entry{ID: "r", Project: "ECM", Start: uiTime(9, 0), Breaks: []model.Break{{Start: uiTime(9, 50)}}}
+	first, second, err = splitEntry(running, uiTime(10, 0), uiTime(10, 30))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.End == nil || *first.DurationSeconds != 50*60 {
+		t.Errorf("first part = %+v, want stopped at 10:00 with 50m", first)
+	}
+	if second.End != nil || len(second.Breaks) != 1 || second.Breaks[0].End != nil || !second.Breaks[0].Start.Equal(uiTime(10, 0)) {
+		t.Errorf("second part = %+v, want running and paused since 10:00", second)
+	}
+
+	for _, at := range []time.Time{uiTime(9, 0), uiTime(11, 0), uiTime(8, 0)} {
+		if _, _, err := splitEntry(e, at, uiTime(12, 0)); err == nil {
+			t.Errorf("split at %s succeeded", at.Format("15:04"))
+		}
+	}
+}
+
+func TestMergeEntries(t *testing.T) {
+	a := closedEntry("a", uiTime(9, 0), uiTime(10, 0))
+	a.Tags = []string{"api"}
+	b := closedEntry("b", uiTime(10, 30), uiTime(11, 0))
+	b.Tags = []string{"api", "review"}
+	comment := "follow-up"
+	b.Comment = &comment
+
+	merged, err := mergeEntries(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.ID != "a" || !merged.End.Equal(uiTime(11, 0)) || *merged.DurationSeconds != 90*60 {
+		t.Errorf("merged = %+v, want a from 9:00 to 11:00 with 1h 30m", merged)
+	}
+	if len(merged.Breaks) != 1 || !merged.Breaks[0].Start.Equal(uiTime(10, 0)) {
+		t.Errorf("merged breaks = %+v, want the gap 10:00-10:30", merged.Breaks)
+	}
+	if strings.Join(merged.Tags, ",") != "api,review" || merged.Comment == nil || *merged.Comment != comment {
+		t.Errorf("merged tags %v, comment %v; want api,review and the comment of b", merged.Tags, merged.Comment)
+	}
+
+	other := closedEntry("c", uiTime(10, 30), uiTime(11, 0))
+	other.Project = "Web"
+	overlapping := closedEntry("d", uiTime(9, 30), uiTime(11, 0))
+	for _, next := range []model.Entry{other, overlapping} {
+		if _, err := mergeEntries(a, next); err == nil {
+			t.Errorf("merging %s succeeded", next.ID)
+		}
+	}
+}
+
+// typeKeys sends every rune of s, or a named key such as "enter" or "tab".
+func typeKeys(m *uiModel, keys ...string) {
+	named := map[string]tea.KeyType{"enter": tea.KeyEnter, "tab": tea.KeyTab, "esc": tea.KeyEsc}
+	for _, k := range keys {
+		if t, ok := named[k]; ok {
+			m.Update(tea.KeyMsg{Type: t})
+			continue
+		}
+		for _, r := range k {
+			m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	}
+}
+
+func TestUIKeys(t *testing.T) {
+	store := storage.NewMemoryStore()
+	now := uiTime(9, 0)
+	m := newUIModel(store, now, func() time.Time { return now })
+	if err := m.reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	typeKeys(m, "s", "ECM", "tab", "review", "enter")
+	active, _, _ := store.FindActiveEntry()
+	if active == nil || active.Project != "ECM" || derefOr(active.Task, "") != "review" {
+		t.Fatalf("after start: active = %+v, status %q", active, m.status)
+	}
+
+	now = uiTime(10, 0)
+	typeKeys(m, "p")
+	now = uiTime(10, 15)
+	typeKeys(m, "p")
+	now = uiTime(11, 0)
+	typeKeys(m, "x")
+	if len(m.entries) != 1 || m.entries[0].DurationSeconds == nil || *m.entries[0].DurationSeconds != 105*60 {
+		t.Fatalf("after stop: entries = %+v, status %q", m.entries, m.status)
+	}
+	if !strings.Contains(m.View(), "ECM") {
+		t.Errorf("view does not show the entry:\n%s", m.View())
+	}
+
+	// Resume the entry, stop it and merge both parts.
+	now = uiTime(11, 30)
+	typeKeys(m, "r")
+	now = uiTime(12, 0)
+	typeKeys(m, "x", "k", "m")
+	if len(m.entries) != 1 || *m.entries[0].DurationSeconds != 135*60 {
+		t.Fatalf("after merge: entries = %+v, status %q", m.entries, m.status)
+	}
+
+	// Edit the task inline; ctrl+u clears the field before the cursor.
+	typeKeys(m, "e", "tab")
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlU})
+	typeKeys(m, "design", "enter")
+	if got := derefOr(m.entries[0].Task, ""); got != "design" || m.statusErr {
+		t.Errorf("after edit: task %q, status %q", got, m.status)
+	}
+
+	// An invalid value keeps the form open with the error.
+	typeKeys(m, "e", "tab", "tab", "tab", "tab")
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlU})
+	typeKeys(m, "25:00", "enter")
+	if m.mode != uiEditing || !m.statusErr {
+		t.Errorf("invalid start accepted: mode %v, status %q", m.mode, m.status)
+	}
+	typeKeys(m, "esc", "d", "y")
+	if len(m.entries) != 0 {
+		t.Errorf("after delete: entries = %+v", m.entries)
+	}
+	if trash, _ := store.ListTrash(); len(trash) != 2 {
+		t.Errorf("trash has %d entries, want the merged and the deleted one", len(trash))
+	}
+}
@@ -0,0 +1,385 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timeparse"
+)
+
+// followEntry shows the day of e with e selected after the next reload.
+func (m *uiModel) followEntry(e model.Entry) {
+	m.day = timecalc.StartOfDay(e.Start)
+	m.entries = append(m.entries[:0], e)
+	m.selected = 0
+}
+
+func (m *uiModel) openStartForm() {
+	m.form = newUIForm("Start a timer", "", []string{"Project", "Task", "Tags"}, []string{"", "", ""}, m.start)
+	m.mode = uiEditing
+}
+
+// start starts a timer from the values of the start form, like ttt start.
+func (m *uiModel) start(v []string) (string, error) {
+	if strings.TrimSpace(v[0]) == "" {
+		return "", errors.New("project must not be empty")
+	}
+	project, registered, err := resolveProject(m.store, v[0])
+	if err != nil {
+		return "", err
+	}
+	entry := model.Entry{
+		Project: project,
+		Task:    optionalString(strings.TrimSpace(v[1])),
+		Tags:    defaultTags(registered),
+	}
+	if strings.TrimSpace(v[2]) != "" {
+		entry.Tags = parseTags(v[2])
+	}
+	entry, _, err = startEntry(m.store, entry, m.now())
+	if err != nil {
+		return "", err
+	}
+	m.followEntry(entry)
+	return fmt.Sprintf("Started timer for project %q at %s", project, entry.Start.Format("15:04:05")), nil
+}
+
+// stop stops the running timer, like ttt stop.
+func (m *uiModel) stop() (string, error) {
+	now := m.now()
+	active, activeDay, err := m.store.FindActiveEntry()
+	if err != nil {
+		return "", err
+	}
+	if active == nil {
+		return "", errors.New("no active timer to stop")
+	}
+	if err := checkStopTime(active, now); err != nil {
+		return "", err
+	}
+	elapsed := model.WorkedSeconds(active.Start, now, active.Breaks)
+	if err := stopEntry(m.store, active, activeDay, now, nil); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Stopped timer for project %q. Elapsed: %s", active.Project, formatElapsed(elapsed)), nil
+}
+
+// resume starts a timer with the project, task and tags of the selected
+// entry, or of the last stopped entry when the day has none, like ttt resume.
+func (m *uiModel) resume() (string, error) {
+	var source model.Entry
+	if e := m.selectedEntry(); e != nil {
+		if e.End == nil {
+			return "", fmt.Errorf("the timer for project %q is already running", e.Project)
+		}
+		source = *e
+	} else {
+		candidates, err := recentPairs(m.store, 1)
+		if err != nil {
+			return "", err
+		}
+		if len(candidates) == 0 {
+			return "", errors.New("no previous entry to resume")
+		}
+		source = candidates[0]
+	}
+
+	entry := model.Entry{
+		Project: source.Project,
+		Task:    source.Task,
+		Tags:    append([]string{}, source.Tags...),
+	}
+	entry, _, err := startEntry(m.store, entry, m.now())
+	if err != nil {
+		return "", err
+	}
+	m.followEntry(entry)
+	task := ""
+	if entry.Task != nil {
+		task = fmt.Sprintf(" (%s)", *entry.Task)
+	}
+	return fmt.Sprintf("Resumed timer for project %q%s at %s", entry.Project, task, entry.Start.Format("15:04:05")), nil
+}
+
+// togglePause pauses the running timer or continues a paused one, like ttt
+// pause and ttt unpause.
+func (m *uiModel) togglePause() (string, error) {
+	now := m.now()
+	active, activeDay, err := m.store.FindActiveEntry()
+	if err != nil {
+		return "", err
+	}
+	if active == nil {
+		return "", errors.New("no active timer to pause")
+	}
+
+	if b := openBreak(active); b != nil {
+		if err := checkContinueTime(b, now); err != nil {
+			return "", err
+		}
+		end := now
+		b.End = &end
+		if err := m.store.UpdateEntry(activeDay, *active); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Continued timer for project %q after a %s break", active.Project,
+			timecalc.FormatDuration(int64(now.Sub(b.Start).Seconds()))), nil
+	}
+
+	if err := checkPauseTime(active, now); err != nil {
+		return "", err
+	}
+	active.Breaks = append(active.Breaks, model.Break{Start: now})
+	if err := m.store.UpdateEntry(activeDay, *active); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Paused timer for project %q at %s", active.Project, now.Format("15:04:05")), nil
+}
+
+// uiEditLabels are the fields of the edit form.
+var uiEditLabels = []string{"Project", "Task", "Comment", "Tags", "Start", "End"}
+
+func uiEditValues(e model.Entry) []string {
+	end := ""
+	if e.End != nil {
+		end = e.End.Format("15:04")
+	}
+	return []string{e.Project, derefOr(e.Task, ""), derefOr(e.Comment, ""),
+		strings.Join(e.Tags, ", "), e.Start.Format("15:04"), end}
+}
+
+func (m *uiModel) openEditForm() {
+	e := m.selectedEntry()
+	if e == nil {
+		return
+	}
+	orig, initial := *e, uiEditValues(*e)
+	m.form = newUIForm("Edit entry "+e.ID, e.ID, uiEditLabels, initial, func(v []string) (string, error) {
+		return m.saveEdit(orig, initial, v)
+	})
+	m.mode = uiEditing
+}
+
+// saveEdit applies the fields of the edit form that differ from their
+// initial values, like the flags of ttt edit. Times refer to the entry's day.
+func (m *uiModel) saveEdit(orig model.Entry, initial, v []string) (string, error) {
+	entry := orig
+	day := timecalc.StartOfDay(orig.Start)
+	now := m.now()
+
+	if v[0] != initial[0] {
+		entry.Project = strings.TrimSpace(v[0])
+		if entry.Project != "" {
+			var err error
+			if entry.Project, _, err = resolveProject(m.store, entry.Project); err != nil {
+				return "", err
+			}
+		}
+	}
+	if v[1] != initial[1] {
+		entry.Task = optionalString(v[1])
+	}
+	if v[2] != initial[2] {
+		entry.Comment = optionalString(v[2])
+	}
+	if v[3] != initial[3] {
+		entry.Tags = parseTags(v[3])
+	}
+	if v[4] != initial[4] {
+		t, err := timeparse.TimeOn(v[4], day, now)
+		if err != nil {
+			return "", fmt.Errorf("invalid start: %w", err)
+		}
+		entry.Start = t
+	}
+	if v[5] != initial[5] {
+		if strings.TrimSpace(v[5]) == "" {
+			return "", errors.New("end must not be empty")
+		}
+		t, err := timeparse.TimeOn(v[5], day, now)
+		if err != nil {
+			return "", fmt.Errorf("invalid end: %w", err)
+		}
+		entry.End = &t
+	}
+
+	recomputeDuration(&entry)
+	if err := validateEntry(entry, orig.ID); err != nil {
+		return "", err
+	}
+	if err := moveEntry(m.store, m.day, entry); err != nil {
+		return "", err
+	}
+	if !timecalc.SameDay(entry.Start, m.day) {
+		return fmt.Sprintf("Updated entry %s; it moved to %s.", entry.ID, entry.Start.Format("2006-01-02")), nil
+	}
+	return fmt.Sprintf("Updated entry %s.", entry.ID), nil
+}
+
+func (m *uiModel) openSplitForm() {
+	e := m.selectedEntry()
+	if e == nil {
+		return
+	}
+	orig := *e
+	// Offer the middle of a stopped entry, or now for the running one.
+	at := m.now()
+	if e.End != nil {
+		at = e.Start.Add(e.End.Sub(e.Start) / 2)
+	}
+	m.form = newUIForm("Split entry "+e.ID, e.ID, []string{"At"}, []string{at.Format("15:04")}, func(v []string) (string, error) {
+		return m.split(orig, v[0])
+	})
+	m.mode = uiEditing
+}
+
+// split splits e at the time at on its day.
+func (m *uiModel) split(e model.Entry, at string) (string, error) {
+	now := m.now()
+	t, err := timeparse.TimeOn(at, timecalc.StartOfDay(e.Start), now)
+	if err != nil {
+		return "", fmt.Errorf("invalid time: %w", err)
+	}
+	first, second, err := splitEntry(e, t, now)
+	if err != nil {
+		return "", err
+	}
+	// The open part is written last, so it stays the active timer.
+	if err := m.store.UpdateEntry(m.day, first); err != nil {
+		return "", err
+	}
+	if err := m.store.UpdateEntry(second.Start, second); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Split entry %s at %s; the second part is %s.", e.ID, t.Format("15:04"), second.ID), nil
+}
+
+// splitEntry splits e at t into e ending at t and a new entry with the same
+// fields starting at t. Breaks are divided between both parts, and a running
+// entry keeps running as the second part.
+func splitEntry(e model.Entry, t, now time.Time) (model.Entry, model.Entry, error) {
+	end := now
+	if e.End != nil {
+		end = *e.End
+	}
+	if !t.After(e.Start) || !t.Before(end) {
+		return e, e, fmt.Errorf("the split time must be between %s and %s", e.Start.Format("15:04"), end.Format("15:04"))
+	}
+	if !timecalc.SameDay(e.Start, t) {
+		return e, e, fmt.Errorf("the split time must be on %s, the day the entry starts", e.Start.Format("2006-01-02"))
+	}
+
+	second := e
+	second.ID = timecalc.GenerateID(t)
+	second.ExternalID = ""
+	second.Start = t
+	second.Tags = append([]string{}, e.Tags...)
+	second.Breaks = nil
+	for _, b := range e.Breaks {
+		if b.End != nil && !b.End.After(t) {
+			continue
+		}
+		if b.Start.Before(t) {
+			b.Start = t
+		}
+		second.Breaks = append(second.Breaks, b)
+	}
+
+	first := e
+	first.End = &t
+	first.Breaks = model.ClipBreaks(e.Breaks, e.Start, t)
+
+	recomputeDuration(&first)
+	recomputeDuration(&second)
+	return first, second, nil
+}
+
+// mergeNext merges the selected entry with the next one on the day. The
+// next entry goes to the trash, so the merge can be undone.
+func (m *uiModel) mergeNext() (string, error) {
+	if m.selected+1 >= len(m.entries) {
+		return "", errors.New("no next entry to merge with")
+	}
+	a, b := m.entries[m.selected], m.entries[m.selected+1]
+	merged, err := mergeEntries(a, b)
+	if err != nil {
+		return "", err
+	}
+	// Writing the merged entry first makes it the active timer if b was.
+	if err := m.store.UpdateEntry(m.day, merged); err != nil {
+		return "", err
+	}
+	if _, err := m.store.TrashEntry(m.day, b.ID, m.now()); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Merged %s into %s. Undo with: ttt trash restore %s", b.ID, a.ID, b.ID), nil
+}
+
+// mergeEntries joins b into a, which ends before b starts. The result keeps
+// the ID and fields of a, the tags of both and spans both entries; the time
+// between them becomes a break, so the worked time stays the sum of both.
+func mergeEntries(a, b model.Entry) (model.Entry, error) {
+	if !model.SameProject(a.Project, b.Project) {
+		return a, fmt.Errorf("cannot merge entries of different projects (%q and %q); edit one of them first", a.Project, b.Project)
+	}
+	if a.End == nil {
+		return a, errors.New("cannot merge the running timer with a later entry")
+	}
+	if b.Start.Before(*a.End) {
+		return a, fmt.Errorf("the entries overlap from %s to %s; see ttt overlaps", b.Start.Format("15:04"), a.End.Format("15:04"))
+	}
+
+	merged := a
+	merged.End = b.End
+	merged.Breaks = append([]model.Break{}, a.Breaks...)
+	if b.Start.After(*a.End) {
+		gapEnd := b.Start
+		merged.Breaks = append(merged.Breaks, model.Break{Start: *a.End, End: &gapEnd})
+	}
+	merged.Breaks = append(merged.Breaks, b.Breaks...)
+
+	if merged.Task == nil {
+		merged.Task = b.Task
+	}
+	if b.Comment != nil && *b.Comment != "" && (a.Comment == nil || *a.Comment != *b.Comment) {
+		comment := *b.Comment
+		if a.Comment != nil {
+			comment = *a.Comment + "\n" + comment
+		}
+		merged.Comment = &comment
+	}
+	merged.Tags = append([]string{}, a.Tags...)
+	for _, t := range b.Tags {
+		if !containsString(merged.Tags, t) {
+			merged.Tags = append(merged.Tags, t)
+		}
+	}
+
+	recomputeDuration(&merged)
+	return merged, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteSelected moves the selected entry to the trash, like ttt delete.
+func (m *uiModel) deleteSelected() (string, error) {
+	e := m.selectedEntry()
+	if e == nil {
+		return "", errors.New("no entry selected")
+	}
+	if _, err := m.store.TrashEntry(m.day, e.ID, m.now()); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Deleted entry %s. Restore it with: ttt trash restore %s", e.ID, e.ID), nil
+}
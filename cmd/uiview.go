@@ -0,0 +1,364 @@
+package cmd
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
+)
+
+// uiKeyHelp lists the keys of ttt ui, for its help text and help screen.
+const uiKeyHelp = `  ↑/k ↓/j      select an entry          ←/h →/l   previous/next day
+  [ ]          previous/next week       t         today
+  s            start a timer            x         stop the running timer
+  r            resume the selected entry, or the last one
+  p            pause or continue the running timer
+  e, enter     edit the selected entry  S         split it at a time
+  m            merge it with the next entry on the day
+  d            delete it (to the trash)
+  tab          show or hide the report  g         change the report grouping
+  w            report the day or week   ?         help
+  q, ctrl+c    quit`
+
+const uiHints = "s start · x stop · r resume · p pause · e edit · S split · m merge · d delete · ←/→ day · [/] week · tab report · ? help · q quit"
+
+// uiReportWidth is the width of the report pane when it is shown next to
+// the day; narrower terminals show it below.
+const uiReportWidth = 36
+
+var (
+	uiBold         = lipgloss.NewStyle().Bold(true)
+	uiDim          = lipgloss.NewStyle().Faint(true)
+	uiCursor       = lipgloss.NewStyle().Reverse(true)
+	uiErrorStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Bold(true)
+	uiRunningStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("2")).Bold(true)
+	uiPausedStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("3")).Bold(true)
+)
+
+// uiNamedColours maps the colour names accepted by ttt project add to ANSI
+// colours.
+var uiNamedColours = map[string]string{
+	"black": "0", "red": "1", "green": "2", "yellow": "3", "blue": "4", "magenta": "5",
+	"cyan": "6", "white": "7", "grey": "8", "gray": "8", "orange": "208", "purple": "93",
+}
+
+// uiPalette colours projects without a registered colour.
+var uiPalette = []string{"4", "2", "5", "6", "3", "12", "10", "13", "14", "11"}
+
+// projectColour returns the registered colour of a project, or a colour
+// picked from its name so it stays the same across days.
+func (m *uiModel) projectColour(name string) lipgloss.Color {
+	if p := findProject(m.projects, name); p != nil && p.Colour != "" {
+		c := strings.ToLower(p.Colour)
+		if ansi, ok := uiNamedColours[c]; ok {
+			return lipgloss.Color(ansi)
+		}
+		if len(c) == 4 { // #rgb
+			c = "#" + strings.Repeat(c[1:2], 2) + strings.Repeat(c[2:3], 2) + strings.Repeat(c[3:4], 2)
+		}
+		return lipgloss.Color(c)
+	}
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(strings.TrimSpace(name))))
+	return lipgloss.Color(uiPalette[h.Sum32()%uint32(len(uiPalette))])
+}
+
+func (m *uiModel) View() string {
+	now := m.now()
+	width := max(40, m.width)
+
+	dayWidth, beside := width, m.showReport && width >= 80+uiReportWidth
+	if beside {
+		dayWidth = width - uiReportWidth - 2
+	}
+	var report []string
+	if m.showReport {
+		report = m.viewReport(min(width, uiReportWidth))
+	}
+
+	top := append(m.viewTimeline(now, dayWidth), "")
+	bottom := []string{"", m.viewTotals(now)}
+
+	// The entry list gets the rows the other parts leave free.
+	rows := m.height - len(top) - len(bottom) - 5
+	if m.showReport && !beside {
+		rows -= len(report) + 1
+	}
+	var list []string
+	if m.mode == uiHelp {
+		list = append(strings.Split(uiKeyHelp, "\n"), "", uiDim.Render("Press any key to go back."))
+	} else {
+		list = m.viewEntries(now, dayWidth, max(3, rows))
+	}
+
+	day := strings.Join(append(append(top, list...), bottom...), "\n")
+	body := day
+	switch {
+	case beside:
+		body = lipgloss.JoinHorizontal(lipgloss.Top, lipgloss.NewStyle().Width(dayWidth).Render(day), "  ", strings.Join(report, "\n"))
+	case m.showReport:
+		body = day + "\n\n" + strings.Join(report, "\n")
+	}
+
+	status := uiTruncate(m.status, width)
+	if m.statusErr {
+		status = uiErrorStyle.Render(status)
+	}
+	return m.viewHeader(now, width) + "\n\n" + body + "\n\n" + status + "\n" + uiDim.Render(uiTruncate(uiHints, width))
+}
+
+// viewHeader shows the displayed day on the left and the running timer,
+// ticking, on the right.
+func (m *uiModel) viewHeader(now time.Time, width int) string {
+	left := uiBold.Render("ttt") + "  " + m.day.Format("Monday 2006-01-02") + uiDim.Render(" · Week "+timecalc.ISOWeekLabel(m.day))
+	if timecalc.SameDay(m.day, now) {
+		left += uiDim.Render(" · today")
+	}
+
+	right := uiDim.Render("No timer running")
+	if e := m.active; e != nil {
+		label := e.Project
+		if e.Task != nil {
+			label += " – " + *e.Task
+		}
+		elapsed := timecalc.FormatDurationHHMMSS(model.WorkedSeconds(e.Start, now, e.Breaks))
+		if openBreak(e) != nil {
+			right = uiPausedStyle.Render("‖ "+label) + " " + elapsed + uiDim.Render(" paused")
+		} else {
+			right = uiRunningStyle.Render("● "+label) + " " + elapsed
+		}
+	}
+
+	gap := width - lipgloss.Width(left) - lipgloss.Width(right)
+	if gap < 2 {
+		return left
+	}
+	return left + strings.Repeat(" ", gap) + right
+}
+
+// uiEnd returns the end of e, or now while it is running.
+func uiEnd(e model.Entry, now time.Time) time.Time {
+	if e.End != nil {
+		return *e.End
+	}
+	return now
+}
+
+// viewTimeline draws the day as a bar with an hour ruler above it. It spans
+// at least 08:00 to 18:00 and grows to cover every entry.
+func (m *uiModel) viewTimeline(now time.Time, width int) []string {
+	h0, h1 := 8, 18
+	for _, e := range m.entries {
+		h0 = min(h0, e.Start.Hour())
+		end := uiEnd(e, now)
+		switch {
+		case !timecalc.SameDay(end, m.day):
+			h1 = 24
+		case end.Minute() > 0 || end.Second() > 0:
+			h1 = max(h1, end.Hour()+1)
+		default:
+			h1 = max(h1, end.Hour())
+		}
+	}
+
+	cols := max(24, width)
+	from := m.day.Add(time.Duration(h0) * time.Hour)
+	span := time.Duration(h1-h0) * time.Hour
+
+	var bar strings.Builder
+	for c := 0; c < cols; c++ {
+		t := from.Add(span * time.Duration(2*c+1) / time.Duration(2*cols))
+		i, inBreak := m.entryAt(t, now)
+		if i < 0 {
+			bar.WriteString(uiDim.Render("·"))
+			continue
+		}
+		ch := "▆"
+		switch {
+		case inBreak:
+			ch = "░"
+		case i == m.selected:
+			ch = "█"
+		}
+		bar.WriteString(lipgloss.NewStyle().Foreground(m.projectColour(m.entries[i].Project)).Render(ch))
+	}
+
+	// Label every hour, or every few hours when they would not fit.
+	ruler := []rune(strings.Repeat(" ", cols))
+	step := 1
+	for cols*step/(h1-h0) < 3 {
+		step++
+	}
+	for h := h0; h < h1; h += step {
+		p := (h - h0) * cols / (h1 - h0)
+		label := fmt.Sprintf("%02d", h)
+		if p+len(label) <= cols {
+			copy(ruler[p:], []rune(label))
+		}
+	}
+	return []string{uiDim.Render(string(ruler)), bar.String()}
+}
+
+// entryAt returns the index of the entry covering t, or -1, and whether t
+// falls into one of its breaks.
+func (m *uiModel) entryAt(t, now time.Time) (int, bool) {
+	for i, e := range m.entries {
+		if t.Before(e.Start) || !t.Before(uiEnd(e, now)) {
+			continue
+		}
+		for _, b := range e.Breaks {
+			if !t.Before(b.Start) && (b.End == nil || t.Before(*b.End)) {
+				return i, true
+			}
+		}
+		return i, false
+	}
+	return -1, false
+}
+
+// viewEntries lists the entries of the day, with the open form below the
+// entry it changes. At most rows lines are shown, scrolled to the selection.
+func (m *uiModel) viewEntries(now time.Time, width, rows int) []string {
+	var lines []string
+	if m.form != nil && m.form.entryID == "" {
+		lines = append(lines, m.viewForm()...)
+	}
+	if len(m.entries) == 0 {
+		return append(lines, uiDim.Render("No entries. Press s to start a timer, ←/→ to change the day."))
+	}
+
+	projWidth := 8
+	for _, e := range m.entries {
+		projWidth = max(projWidth, min(20, len([]rune(e.Project))))
+	}
+
+	focus := 0
+	for i, e := range m.entries {
+		end := "now  "
+		if e.End != nil {
+			end = e.End.Format("15:04")
+		}
+		dur := timecalc.FormatDuration(model.WorkedSeconds(e.Start, uiEnd(e, now), e.Breaks))
+		marker := "  "
+		if i == m.selected {
+			marker = "▸ "
+			focus = len(lines)
+		}
+		project := lipgloss.NewStyle().Foreground(m.projectColour(e.Project)).Render(
+			fmt.Sprintf("%-*s", projWidth, uiTruncate(e.Project, projWidth)))
+
+		rest := derefOr(e.Task, "")
+		for _, t := range e.Tags {
+			rest += " #" + t
+		}
+		state := ""
+		switch {
+		case e.End == nil && openBreak(&e) != nil:
+			state = uiPausedStyle.Render(" paused")
+		case e.End == nil:
+			state = uiRunningStyle.Render(" running")
+		case e.Source == "outlook":
+			state = uiDim.Render(" outlook")
+		}
+
+		line := fmt.Sprintf("%s%s–%s %8s  %s  ", marker, e.Start.Format("15:04"), end, dur, project)
+		free := width - lipgloss.Width(line) - lipgloss.Width(state)
+		line += uiTruncate(strings.TrimSpace(rest), max(0, free)) + state
+		if i == m.selected {
+			line = uiBold.Render(line)
+		}
+		lines = append(lines, line)
+
+		if m.form != nil && m.form.entryID == e.ID {
+			lines = append(lines, m.viewForm()...)
+		}
+	}
+
+	if len(lines) <= rows {
+		return lines
+	}
+	first := max(0, min(focus-rows/3, len(lines)-rows))
+	return lines[first : first+rows]
+}
+
+// viewForm shows the open form, indented below the entry it belongs to.
+func (m *uiModel) viewForm() []string {
+	f := m.form
+	lines := []string{"    " + uiBold.Render(f.title)}
+	for i, fd := range f.fields {
+		label := fmt.Sprintf("%-8s", f.labels[i])
+		value := string(fd.text)
+		if i == f.focus {
+			label = uiBold.Render(label)
+			cursor := " "
+			if fd.pos < len(fd.text) {
+				cursor = string(fd.text[fd.pos])
+			}
+			value = string(fd.text[:fd.pos]) + uiCursor.Render(cursor)
+			if fd.pos < len(fd.text) {
+				value += string(fd.text[fd.pos+1:])
+			}
+		}
+		lines = append(lines, "    "+label+" "+value)
+	}
+	return append(lines, "    "+uiDim.Render("enter save · tab next field · esc cancel"))
+}
+
+// viewTotals shows the time of the day and of its week, including the
+// running timer.
+func (m *uiModel) viewTotals(now time.Time) string {
+	day, week := entriesTotal(m.entries), m.weekTotal
+	if e := m.active; e != nil {
+		running := model.WorkedSeconds(e.Start, now, e.Breaks)
+		if timecalc.SameDay(m.activeDay, m.day) {
+			day += running
+		}
+		if r := weekRange(m.day); !m.activeDay.Before(r.From) && !m.activeDay.After(r.To) {
+			week += running
+		}
+	}
+	return fmt.Sprintf("Day %s · Week %s", uiBold.Render(timecalc.FormatDuration(day)), uiBold.Render(timecalc.FormatDuration(week)))
+}
+
+// viewReport shows the report pane: the groups of ttt report for the day or
+// week, with their durations right-aligned.
+func (m *uiModel) viewReport(width int) []string {
+	rule := uiDim.Render(strings.Repeat("─", width))
+	lines := []string{
+		uiBold.Render("Report · "+m.reportName) + uiDim.Render(" · by "+uiReportKeys[m.reportKey]),
+		rule,
+	}
+	row := func(label string, seconds int64) string {
+		dur := timecalc.FormatDuration(seconds)
+		return fmt.Sprintf("%-*s%s", width-len(dur), uiTruncate(label, width-len(dur)-1), dur)
+	}
+	var walk func(gs []*reportGroup, depth int)
+	walk = func(gs []*reportGroup, depth int) {
+		for _, g := range gs {
+			lines = append(lines, row(strings.Repeat("  ", depth)+g.Key, g.Seconds))
+			walk(g.Groups, depth+1)
+		}
+	}
+	walk(m.report, 0)
+	if len(m.report) == 0 {
+		lines = append(lines, uiDim.Render("No stopped entries."))
+	}
+	return append(lines, rule, uiBold.Render(row("Total", m.reportSum)), uiDim.Render("g grouping · w day/week"))
+}
+
+// uiTruncate shortens s to at most n runes, ending with … when cut.
+func uiTruncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	if n <= 0 {
+		return ""
+	}
+	return string(r[:n-1]) + "…"
+}
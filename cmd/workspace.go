@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/workspace"
+)
+
+var workspaceCreateUse bool
+
+var workspaceCmd = &cobra.Command{
+	Use:   "workspace",
+	Short: "Manage workspaces with separate data and config",
+	Long: `Workspaces keep unrelated tracking apart, e.g. client work and personal
+projects. Each has its own entries, trash, projects and config file. The
+"default" workspace is the data directory itself; named workspaces live in
+its workspaces/ subdirectory.
+
+Commands use the workspace given by --workspace, then $TTT_WORKSPACE, then
+the one chosen with ttt workspace use.`,
+	Example: `  ttt workspace create acme
+  ttt workspace use acme
+  ttt --workspace default report
+  ttt workspace list`,
+	// Workspace commands must work even when the selected workspace is
+	// missing, so they skip loading its config.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+}
+
+var workspaceCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a workspace",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runWorkspaceCreate,
+}
+
+var workspaceUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Select the workspace used by later commands",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runWorkspaceUse,
+}
+
+var workspaceListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List workspaces",
+	Args:  cobra.NoArgs,
+	RunE:  runWorkspaceList,
+}
+
+func init() {
+	workspaceCreateCmd.Flags().BoolVar(&workspaceCreateUse, "use", false, "Also select the new workspace")
+	workspaceCmd.AddCommand(workspaceCreateCmd)
+	workspaceCmd.AddCommand(workspaceUseCmd)
+	workspaceCmd.AddCommand(workspaceListCmd)
+}
+
+// workspaceOutput describes a workspace in structured output. Active marks
+// the one later commands use.
+type workspaceOutput struct {
+	Name       string `json:"name"`
+	DataDir    string `json:"data_dir"`
+	ConfigFile string `json:"config_file"`
+	Active     bool   `json:"active"`
+}
+
+func newWorkspaceOutput(roots workspace.Roots, w workspace.Workspace) workspaceOutput {
+	return workspaceOutput{Name: w.Name, DataDir: w.DataDir, ConfigFile: w.ConfigFile, Active: roots.Selected(workspaceFlag) == w.Name}
+}
+
+func runWorkspaceCreate(cmd *cobra.Command, args []string) error {
+	roots := rootsOrExit()
+	w, err := roots.Create(args[0])
+	if err != nil {
+		fail(1, err)
+	}
+	if workspaceCreateUse {
+		if err := roots.Use(w.Name); err != nil {
+			fail(1, err)
+		}
+	}
+
+	if structuredOutput() {
+		printOutput(newWorkspaceOutput(roots, w))
+		return nil
+	}
+	fmt.Printf("Created workspace %s in %s\n", w.Name, w.DataDir)
+	if workspaceCreateUse {
+		printUsing(w.Name)
+	} else {
+		fmt.Printf("Select it with: ttt workspace use %s\n", w.Name)
+	}
+	return nil
+}
+
+func runWorkspaceUse(cmd *cobra.Command, args []string) error {
+	roots := rootsOrExit()
+	if err := roots.Use(args[0]); err != nil {
+		fail(1, err)
+	}
+
+	if structuredOutput() {
+		w, err := roots.Open(args[0])
+		if err != nil {
+			fail(1, err)
+		}
+		printOutput(newWorkspaceOutput(roots, w))
+		return nil
+	}
+	printUsing(args[0])
+	return nil
+}
+
+// printUsing confirms that name was selected, noting when $TTT_WORKSPACE
+// overrides it.
+func printUsing(name string) {
+	fmt.Printf("Using workspace %s\n", name)
+	if env := os.Getenv(workspace.EnvWorkspace); env != "" && env != name {
+		fmt.Printf("Note: $%s=%s takes precedence in this shell.\n", workspace.EnvWorkspace, env)
+	}
+}
+
+func runWorkspaceList(cmd *cobra.Command, args []string) error {
+	roots := rootsOrExit()
+	names, err := roots.List()
+	if err != nil {
+		fail(2, err)
+	}
+
+	out := []workspaceOutput{}
+	for _, name := range names {
+		w, err := roots.Open(name)
+		if err != nil {
+			continue
+		}
+		out = append(out, newWorkspaceOutput(roots, w))
+	}
+	if structuredOutput() {
+		printOutput(out)
+		return nil
+	}
+	for _, w := range out {
+		marker := " "
+		if w.Active {
+			marker = "*"
+		}
+		fmt.Printf("%s %-20s %s\n", marker, w.Name, w.DataDir)
+	}
+	return nil
+}
@@ -6,32 +6,136 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 )
 
+// SchemaVersion is the newest config file format this binary understands.
+const SchemaVersion = 1
+
 // Config is the root configuration for ttt, stored in ~/.ttt/config.json.
 // The file supports single-line // comments for documentation purposes.
-type Config struct{}
+type Config struct {
+	// SchemaVersion is the format version of the file; files without it are
+	// version 0.
+	SchemaVersion int `json:"schema_version"`
+	// StrictProjects makes start, add and edit reject project names that are
+	// not in the project registry.
+	StrictProjects bool            `json:"strict_projects"`
+	Storage        StorageConfig   `json:"storage"`
+	Outlook        OutlookConfig   `json:"outlook"`
+	Timesheet      TimesheetConfig `json:"timesheet"`
+}
+
+// StorageConfig selects where entries are kept.
+type StorageConfig struct {
+	// Backend names the storage backend; see storage.Open.
+	Backend string `json:"backend"`
+}
+
+// OutlookConfig holds the Microsoft Graph / Outlook calendar sync settings.
+type OutlookConfig struct {
+	TenantID       string `json:"tenant_id"`
+	ClientID       string `json:"client_id"`
+	DefaultProject string `json:"default_project"`
+	Timezone       string `json:"timezone"`
+}
+
+// TimesheetConfig sets the working time ttt timesheet compares against.
+type TimesheetConfig struct {
+	// WeeklyHours is the target per week, split evenly across the working
+	// days without an override.
+	WeeklyHours float64 `json:"weekly_hours"`
+	// WorkingDays lists the weekdays with a target, e.g. "mon".
+	WorkingDays []string `json:"working_days"`
+	// Overrides sets the target in hours of single weekdays, e.g. a short
+	// Friday. They count towards WeeklyHours.
+	Overrides map[string]float64 `json:"overrides"`
+}
+
+// DefaultClientID is the public Azure CLI application ID, which allows the
+// device code flow without a dedicated app registration.
+const DefaultClientID = "04b07795-8542-4c4a-95af-30b2c573d5ab"
 
-// defaultConfig returns an empty Config.
+// defaultConfig returns a Config populated with built-in defaults.
 func defaultConfig() Config {
-	return Config{}
+	return Config{
+		SchemaVersion:  SchemaVersion,
+		StrictProjects: false,
+		Storage: StorageConfig{
+			Backend: "json",
+		},
+		Outlook: OutlookConfig{
+			TenantID:       "common",
+			ClientID:       DefaultClientID,
+			DefaultProject: "Meetings",
+			Timezone:       "",
+		},
+		Timesheet: TimesheetConfig{
+			WeeklyHours: 40,
+			WorkingDays: []string{"mon", "tue", "wed", "thu", "fri"},
+			Overrides:   map[string]float64{},
+		},
+	}
 }
 
 // configTemplate is the annotated config written on first run.
 // Lines whose trimmed content starts with // are stripped before JSON parsing,
 // allowing human-readable documentation inside the file.
 const configTemplate = `// ttt configuration – ~/.ttt/config.json
-{}
-`
+{
+  // Format version of this file, upgraded by: ttt migrate
+  "schema_version": 1,
 
-// configFilePath returns the path to ~/.ttt/config.json.
-func configFilePath() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("cannot determine home directory: %w", err)
-	}
-	return filepath.Join(home, ".ttt", "config.json"), nil
+  // ── Projects ──────────────────────────────────────────────────────────────
+  // Only accept project names registered with: ttt project add <name>
+  // Unknown names are rejected with a "did you mean" suggestion.
+  "strict_projects": false,
+
+  // ── Storage ───────────────────────────────────────────────────────────────
+  "storage": {
+    // Backend holding entries, the trash and the project registry.
+    // • "json"   – one human-readable JSON file per day under ~/.ttt (default)
+    // • "sqlite" – a single database at ~/.ttt/ttt.db, faster for long histories
+    // Convert existing data first with: ttt storage migrate --to <backend>
+    "backend": "json"
+  },
+
+  // ── Microsoft Graph / Outlook calendar sync ──────────────────────────────
+  "outlook": {
+    // Azure AD tenant ID.
+    // • "common"  – personal Microsoft accounts and any organisation (default)
+    // • Your organisation's tenant GUID, e.g. "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"
+    "tenant_id": "common",
+
+    // Azure application (client) ID used for the OAuth2 device code flow.
+    // The built-in value is the public Azure CLI app – no app registration needed.
+    // Replace with your own Azure app registration for single-tenant deployments.
+    "client_id": "04b07795-8542-4c4a-95af-30b2c573d5ab",
+
+    // Default project name assigned to imported Outlook calendar events.
+    // Can be overridden per-sync with: ttt outlook sync --project <name>
+    "default_project": "Meetings",
+
+    // IANA timezone for interpreting calendar event times, e.g. "Europe/Berlin".
+    // Leave empty to use UTC. Can be overridden with: ttt outlook sync --timezone <tz>
+    "timezone": ""
+  },
+
+  // ── Timesheet ─────────────────────────────────────────────────────────────
+  "timesheet": {
+    // Hours you are expected to work per week, compared against by: ttt timesheet
+    "weekly_hours": 40,
+
+    // Days with a target: mon, tue, wed, thu, fri, sat, sun.
+    // The weekly hours are split evenly across them.
+    "working_days": ["mon", "tue", "wed", "thu", "fri"],
+
+    // Fixed targets in hours for single weekdays, e.g. { "fri": 4 }.
+    // They are taken out of weekly_hours before the rest is split.
+    "overrides": {}
+  }
 }
+`
 
 // stripLineComments removes lines whose leading non-whitespace content starts
 // with //. Only full-line comments are handled; inline comments are not stripped.
@@ -47,15 +151,10 @@ func stripLineComments(data []byte) []byte {
 	return out
 }
 
-// Load reads ~/.ttt/config.json, creating it with annotated defaults on first
-// run. Lines starting with // are treated as comments and stripped before
-// JSON parsing.
-func Load() (Config, error) {
-	path, err := configFilePath()
-	if err != nil {
-		return defaultConfig(), err
-	}
-
+// Load reads the config file at path, usually ~/.ttt/config.json, creating it
+// with annotated defaults on first run. Lines starting with // are treated as
+// comments and stripped before JSON parsing.
+func Load(path string) (Config, error) {
 	data, err := os.ReadFile(path)
 	if os.IsNotExist(err) {
 		// First run: write the annotated template so users can discover options.
@@ -68,15 +167,103 @@ func Load() (Config, error) {
 		return defaultConfig(), fmt.Errorf("reading config file %s: %w", path, err)
 	}
 
+	return parse(data, path)
+}
+
+// parse decodes commented config JSON on top of the built-in defaults, so any
+// omitted field keeps its default value.
+func parse(data []byte, path string) (Config, error) {
 	cleaned := stripLineComments(data)
-	var cfg Config
+	cfg := defaultConfig()
 	if err := json.Unmarshal(cleaned, &cfg); err != nil {
 		return defaultConfig(), fmt.Errorf("parsing config file %s: %w\nTip: delete the file to regenerate defaults", path, err)
 	}
-
+	if cfg.SchemaVersion > SchemaVersion {
+		return defaultConfig(), &NewerSchemaError{Path: path, Version: cfg.SchemaVersion}
+	}
 	return cfg, nil
 }
 
+// NewerSchemaError reports a config file written by a newer ttt, whose
+// options this binary might misread.
+type NewerSchemaError struct {
+	Path    string
+	Version int
+}
+
+func (e *NewerSchemaError) Error() string {
+	return fmt.Sprintf("config file %s uses schema version %d, but this ttt only understands up to version %d; upgrade ttt",
+		e.Path, e.Version, SchemaVersion)
+}
+
+// migrations[v] upgrades the text of a config file from schema version v to
+// v+1. They edit the text rather than re-encoding it, so the user's comments
+// survive. Upgrade sets schema_version afterwards.
+var migrations = []func(data []byte) ([]byte, error){
+	// 0 → 1: schema_version was introduced; the options are unchanged.
+	func(data []byte) ([]byte, error) { return data, nil },
+}
+
+// schemaVersionRe matches the schema_version option in a config file.
+var schemaVersionRe = regexp.MustCompile(`"schema_version"\s*:\s*\d+`)
+
+// setSchemaVersion sets the schema_version option in the text of a config
+// file, adding it after the opening brace when it is missing.
+func setSchemaVersion(data []byte, version int) []byte {
+	field := fmt.Sprintf(`"schema_version": %d`, version)
+	if schemaVersionRe.Match(data) {
+		return schemaVersionRe.ReplaceAll(data, []byte(field))
+	}
+	offset := 0
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		isComment := bytes.HasPrefix(bytes.TrimLeft(line, " \t"), []byte("//"))
+		if i := bytes.IndexByte(line, '{'); i >= 0 && !isComment {
+			offset += i + 1
+			out := append([]byte{}, data[:offset]...)
+			out = append(out, "\n  // Format version of this file, upgraded by: ttt migrate\n  "+field+","...)
+			return append(out, data[offset:]...)
+		}
+		offset += len(line)
+	}
+	return data
+}
+
+// Upgrade rewrites the config file at path in the current format if it is
+// older and reports whether it was (or, with dryRun, would be) changed.
+func Upgrade(path string, dryRun bool) (bool, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("reading config file %s: %w", path, err)
+	}
+	var v struct {
+		SchemaVersion int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(stripLineComments(data), &v); err != nil {
+		return false, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	if v.SchemaVersion > SchemaVersion {
+		return false, &NewerSchemaError{Path: path, Version: v.SchemaVersion}
+	}
+	if v.SchemaVersion == SchemaVersion {
+		return false, nil
+	}
+	for version := v.SchemaVersion; version < SchemaVersion; version++ {
+		if data, err = migrations[version](data); err != nil {
+			return false, fmt.Errorf("migrating config file %s from schema version %d: %w", path, version, err)
+		}
+	}
+	if dryRun {
+		return true, nil
+	}
+	if err := os.WriteFile(path, setSchemaVersion(data, SchemaVersion), 0o600); err != nil {
+		return false, fmt.Errorf("writing config file %s: %w", path, err)
+	}
+	return true, nil
+}
+
 // writeDefault creates the config directory and writes the annotated default
 // config template.
 func writeDefault(path string) error {
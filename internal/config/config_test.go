@@ -0,0 +1,71 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTemplateMatchesDefaults(t *testing.T) {
+	cfg, err := parse([]byte(configTemplate), "template")
+	if err != nil {
+		t.Fatalf("parse(configTemplate): %v", err)
+	}
+	if !reflect.DeepEqual(cfg, defaultConfig()) {
+		t.Errorf("template config = %+v, want %+v", cfg, defaultConfig())
+	}
+}
+
+func TestParseKeepsDefaultsForOmittedFields(t *testing.T) {
+	data := []byte(`// comment
+{
+  "outlook": {
+    // inner comment
+    "default_project": "Calls"
+  }
+}`)
+	cfg, err := parse(data, "test")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if cfg.Outlook.DefaultProject != "Calls" {
+		t.Errorf("default_project = %q, want %q", cfg.Outlook.DefaultProject, "Calls")
+	}
+	if cfg.Outlook.TenantID != "common" {
+		t.Errorf("tenant_id = %q, want %q", cfg.Outlook.TenantID, "common")
+	}
+	if cfg.Outlook.ClientID != DefaultClientID {
+		t.Errorf("client_id = %q, want %q", cfg.Outlook.ClientID, DefaultClientID)
+	}
+}
+
+func TestParseInvalidJSON(t *testing.T) {
+	if _, err := parse([]byte("{bad"), "test"); err == nil {
+		t.Fatal("expected error for invalid JSON, got nil")
+	}
+}
+
+func TestParseRefusesNewerSchema(t *testing.T) {
+	_, err := parse([]byte(`{"schema_version": 99}`), "test")
+	var newer *NewerSchemaError
+	if !errors.As(err, &newer) || newer.Version != 99 {
+		t.Fatalf("parse error = %v, want NewerSchemaError for version 99", err)
+	}
+}
+
+func TestSetSchemaVersionKeepsComments(t *testing.T) {
+	old := "// my settings\n{\n  // keep me\n  \"strict_projects\": true\n}\n"
+	got := string(setSchemaVersion([]byte(old), 1))
+	want := "// my settings\n{\n  // Format version of this file, upgraded by: ttt migrate\n  \"schema_version\": 1,\n  // keep me\n  \"strict_projects\": true\n}\n"
+	if got != want {
+		t.Errorf("setSchemaVersion =\n%s\nwant\n%s", got, want)
+	}
+	cfg, err := parse([]byte(got), "test")
+	if err != nil || cfg.SchemaVersion != 1 || !cfg.StrictProjects {
+		t.Errorf("parse(upgraded) = %+v, %v", cfg, err)
+	}
+	if again := string(setSchemaVersion([]byte(got), 2)); !strings.Contains(again, `"schema_version": 2,`) || strings.Count(again, "schema_version") != 1 {
+		t.Errorf("setSchemaVersion(2) =\n%s", again)
+	}
+}
@@ -0,0 +1,347 @@
+// Package doctor checks stored data for inconsistencies and repairs the ones
+// that have an unambiguous fix.
+package doctor
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/outlook"
+	"github.com/Tiliavir/trivial-time-tracker/internal/overlap"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
+)
+
+// Kinds of Issue.
+const (
+	KindUnparsableFile   = "unparsable_file"
+	KindTempFile         = "temp_file"
+	KindCorruptFile      = "corrupt_file"
+	KindWrongDay         = "wrong_day"
+	KindDuplicateID      = "duplicate_id"
+	KindDurationMismatch = "duration_mismatch"
+	KindEndBeforeStart   = "end_before_start"
+	KindMultipleOpen     = "multiple_open"
+	KindOverlap          = "overlap"
+	KindUnknownSource    = "unknown_source"
+)
+
+// Issue is a single problem found by Check. Fix describes the repair, and is
+// empty when the issue has to be resolved by hand.
+type Issue struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	Path    string `json:"path,omitempty"`
+	Day     string `json:"day,omitempty"`
+	EntryID string `json:"entry_id,omitempty"`
+	Fix     string `json:"fix,omitempty"`
+
+	repair func() error
+}
+
+// Fixable reports whether Repair can resolve the issue.
+func (i Issue) Fixable() bool {
+	return i.repair != nil
+}
+
+// Repair applies the fix described by Fix.
+func (i Issue) Repair() error {
+	if i.repair == nil {
+		return fmt.Errorf("%s cannot be repaired automatically", i.Kind)
+	}
+	return i.repair()
+}
+
+// fileChecker is implemented by backends that store data in plain files.
+type fileChecker interface {
+	ScanFiles() ([]storage.FileProblem, error)
+	RepairFile(p storage.FileProblem) error
+}
+
+// located is an entry together with the day that stores it.
+type located struct {
+	entry model.Entry
+	day   time.Time
+}
+
+// Check inspects the whole history in s. Open entries and open breaks are
+// measured up to now. Issues are returned in a stable order: file problems
+// first, then per-day problems by day, then problems spanning days.
+func Check(s storage.Store, now time.Time) ([]Issue, error) {
+	var issues []Issue
+	skip := map[string]bool{}
+
+	if fc, ok := s.(fileChecker); ok {
+		problems, err := fc.ScanFiles()
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range problems {
+			issues = append(issues, fileIssue(fc, p))
+			if p.Kind == storage.FileUnparsable && !p.Day.IsZero() {
+				skip[p.Day.Format("2006-01-02")] = true
+			}
+		}
+	}
+
+	days, err := s.Days()
+	if err != nil {
+		return nil, err
+	}
+	var all []located
+	for _, day := range days {
+		key := day.Format("2006-01-02")
+		if skip[key] {
+			continue
+		}
+		df, err := s.LoadDay(day)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, checkDay(s, day, df.Entries)...)
+		for _, e := range df.Entries {
+			all = append(all, located{entry: e, day: day})
+		}
+	}
+
+	sort.SliceStable(all, func(i, j int) bool { return all[i].entry.Start.Before(all[j].entry.Start) })
+	issues = append(issues, checkOpen(s, all, now)...)
+	issues = append(issues, checkOverlaps(all, now)...)
+	return issues, nil
+}
+
+func fileIssue(fc fileChecker, p storage.FileProblem) Issue {
+	issue := Issue{Path: p.Path}
+	if !p.Day.IsZero() {
+		issue.Day = p.Day.Format("2006-01-02")
+	}
+	switch p.Kind {
+	case storage.FileTemp:
+		issue.Kind = KindTempFile
+		issue.Message = "leftover temp file from an interrupted write"
+		issue.Fix = "delete the file"
+	case storage.FileCorrupt:
+		issue.Kind = KindCorruptFile
+		issue.Message = "backup of a corrupt file; inspect it and delete it when done"
+		return issue
+	default:
+		issue.Kind = KindUnparsableFile
+		issue.Message = fmt.Sprintf("cannot parse file: %v", p.Err)
+		issue.Fix = "move it aside to " + p.Path + ".corrupt"
+	}
+	issue.repair = func() error { return fc.RepairFile(p) }
+	return issue
+}
+
+// checkDay reports problems within a single day's entries.
+func checkDay(s storage.Store, day time.Time, entries []model.Entry) []Issue {
+	var issues []Issue
+	key := day.Format("2006-01-02")
+
+	count := map[string]int{}
+	for _, e := range entries {
+		count[e.ID]++
+	}
+	reported := map[string]bool{}
+	for _, e := range entries {
+		if count[e.ID] < 2 || reported[e.ID] {
+			continue
+		}
+		reported[e.ID] = true
+		id := e.ID
+		issues = append(issues, Issue{
+			Kind:    KindDuplicateID,
+			Message: fmt.Sprintf("%d entries share the ID %s", count[id], id),
+			Day:     key,
+			EntryID: id,
+			Fix:     "drop identical copies and give the others new IDs",
+			repair:  func() error { return dedupe(s, day, entries, id) },
+		})
+	}
+
+	for _, e := range entries {
+		e := e
+		// Entries with a shared ID cannot be addressed individually until
+		// the duplicates are resolved.
+		fixable := count[e.ID] == 1
+		issue := func(kind, msg, fix string, repair func() error) {
+			i := Issue{Kind: kind, Message: msg, Day: key, EntryID: e.ID}
+			if fixable && repair != nil {
+				i.Fix, i.repair = fix, repair
+			}
+			issues = append(issues, i)
+		}
+
+		if !timecalc.SameDay(e.Start, day) {
+			start := timecalc.StartOfDay(e.Start)
+			issue(KindWrongDay,
+				fmt.Sprintf("starts on %s but is stored under %s", start.Format("2006-01-02"), key),
+				"move it to "+start.Format("2006-01-02"),
+				func() error {
+					// Write the new copy first so a failure never loses it.
+					if err := s.UpdateEntry(start, e); err != nil {
+						return err
+					}
+					_, err := s.RemoveEntry(day, e.ID)
+					return err
+				})
+		}
+
+		if e.End != nil && e.End.Before(e.Start) {
+			issue(KindEndBeforeStart,
+				fmt.Sprintf("ends at %s, before its start at %s", e.End.Format("2006-01-02 15:04"), e.Start.Format("2006-01-02 15:04")),
+				"", nil)
+		} else if e.End != nil {
+			want := model.WorkedSeconds(e.Start, *e.End, e.Breaks)
+			if e.DurationSeconds == nil || *e.DurationSeconds != want {
+				got := "missing"
+				if e.DurationSeconds != nil {
+					got = fmt.Sprintf("%ds", *e.DurationSeconds)
+				}
+				issue(KindDurationMismatch,
+					fmt.Sprintf("duration is %s but the interval minus breaks is %ds", got, want),
+					fmt.Sprintf("set the duration to %ds", want),
+					func() error {
+						e.DurationSeconds = &want
+						return s.UpdateEntry(day, e)
+					})
+			}
+		}
+
+		if e.Source != "manual" && e.Source != outlook.Source {
+			issue(KindUnknownSource, fmt.Sprintf("unknown source %q", e.Source), "", nil)
+		}
+	}
+	return issues
+}
+
+// dedupe resolves the entries sharing the given ID on day, keeping one of
+// each identical copy and giving the rest new IDs. The renamed copies are
+// written before the others are removed, so a failure never loses one.
+func dedupe(s storage.Store, day time.Time, entries []model.Entry, id string) error {
+	var copies []model.Entry
+	for _, e := range entries {
+		if e.ID == id {
+			copies = append(copies, e)
+		}
+	}
+	// RemoveEntry drops the first copy with the ID, so the last one stays
+	// in place and keeps it.
+	kept := []model.Entry{copies[len(copies)-1]}
+	for i := len(copies) - 2; i >= 0; i-- {
+		e := copies[i]
+		dup := false
+		for _, k := range kept {
+			if reflect.DeepEqual(k, e) {
+				dup = true
+				break
+			}
+		}
+		if dup {
+			continue
+		}
+		kept = append(kept, e)
+		e.ID = timecalc.GenerateID(e.Start)
+		if err := s.UpdateEntry(day, e); err != nil {
+			return err
+		}
+	}
+	for range copies[1:] {
+		if _, err := s.RemoveEntry(day, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkOpen reports open entries other than the newest one. They are left
+// over from crashes or hand edits and would otherwise count as running.
+func checkOpen(s storage.Store, all []located, now time.Time) []Issue {
+	var open []int
+	for i, l := range all {
+		if l.entry.End == nil {
+			open = append(open, i)
+		}
+	}
+	if len(open) < 2 {
+		return nil
+	}
+
+	var issues []Issue
+	for _, i := range open[:len(open)-1] {
+		l := all[i]
+		end := timecalc.EndOfDay(l.entry.Start)
+		if i+1 < len(all) && all[i+1].entry.Start.Before(end) {
+			end = all[i+1].entry.Start
+		}
+		if now.Before(end) {
+			end = now
+		}
+		if end.Before(l.entry.Start) {
+			end = l.entry.Start
+		}
+		e, day := l.entry, l.day
+		issues = append(issues, Issue{
+			Kind:    KindMultipleOpen,
+			Message: fmt.Sprintf("open since %s, but a newer timer is running", e.Start.Format("2006-01-02 15:04")),
+			Day:     day.Format("2006-01-02"),
+			EntryID: e.ID,
+			Fix:     "stop it at " + end.Format("2006-01-02 15:04"),
+			repair:  func() error { return s.UpdateEntry(day, closeEntry(e, end)) },
+		})
+	}
+	return issues
+}
+
+// closeEntry ends e and any open break at end.
+func closeEntry(e model.Entry, end time.Time) model.Entry {
+	e.Breaks = append([]model.Break(nil), e.Breaks...)
+	for i := range e.Breaks {
+		if e.Breaks[i].End == nil {
+			e.Breaks[i].End = &end
+		}
+	}
+	dur := model.WorkedSeconds(e.Start, end, e.Breaks)
+	e.End = &end
+	e.DurationSeconds = &dur
+	return e
+}
+
+// checkOverlaps reports entries whose worked time overlaps an earlier one,
+// as ttt overlaps does. Stale open entries and duplicated IDs are reported
+// by their own checks and skipped here.
+func checkOverlaps(all []located, now time.Time) []Issue {
+	running := -1
+	for i, l := range all {
+		if l.entry.End == nil {
+			running = i
+		}
+	}
+	var entries []model.Entry
+	days := map[string]time.Time{}
+	for i, l := range all {
+		if l.entry.End == nil && i != running {
+			continue
+		}
+		entries = append(entries, l.entry)
+		days[l.entry.ID] = l.day
+	}
+
+	var issues []Issue
+	for _, c := range overlap.Find(entries, now) {
+		if c.Earlier.ID == c.Later.ID {
+			continue
+		}
+		issues = append(issues, Issue{
+			Kind: KindOverlap,
+			Message: fmt.Sprintf("shares %s from %s with %s; see ttt overlaps",
+				timecalc.FormatDuration(int64(c.Shared.Seconds())), c.Start.Format("2006-01-02 15:04"), c.Earlier.ID),
+			Day:     days[c.Later.ID].Format("2006-01-02"),
+			EntryID: c.Later.ID,
+		})
+	}
+	return issues
+}
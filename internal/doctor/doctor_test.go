@@ -0,0 +1,159 @@
+package doctor_test
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/doctor"
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+)
+
+func entry(id string, start time.Time, d time.Duration) model.Entry {
+	e := model.Entry{ID: id, Project: "P", Start: start, Source: "manual"}
+	if d > 0 {
+		end := start.Add(d)
+		secs := int64(d.Seconds())
+		e.End, e.DurationSeconds = &end, &secs
+	}
+	return e
+}
+
+func kinds(issues []doctor.Issue) string {
+	var out []string
+	for _, i := range issues {
+		out = append(out, i.Kind+":"+i.EntryID+i.Path)
+	}
+	sort.Strings(out)
+	return strings.Join(out, " ")
+}
+
+func TestCheckAndRepair(t *testing.T) {
+	base := t.TempDir()
+	s := storage.NewJSONStore(base)
+	d1 := time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local)
+	d2 := d1.AddDate(0, 0, 1)
+	now := d2.Add(8 * time.Hour)
+
+	badDur := entry("dur", d1.Add(3*time.Hour), time.Hour)
+	wrongDur := int64(10)
+	badDur.DurationSeconds = &wrongDur
+	backwards := entry("back", d1.Add(6*time.Hour), time.Hour)
+	early := d1.Add(5 * time.Hour)
+	backwards.End = &early
+	odd := entry("odd", d1.Add(8*time.Hour), time.Hour)
+	odd.Source = "jira"
+	dupB := entry("dup", d1.Add(10*time.Hour), time.Hour)
+	dupB.Project = "Q"
+
+	if err := s.SaveDay(d1, model.DayFile{Date: "2026-03-02", Entries: []model.Entry{
+		entry("stale", d1, 0),
+		entry("over", d1.Add(30*time.Minute), time.Hour),
+		entry("clash", d1.Add(45*time.Minute), 10*time.Minute),
+		badDur,
+		backwards,
+		odd,
+		entry("dup", d1.Add(9*time.Hour), time.Hour),
+		entry("dup", d1.Add(9*time.Hour), time.Hour),
+		dupB,
+		entry("moved", d2.Add(2*time.Hour), time.Hour),
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateEntry(d2, entry("running", d2.Add(7*time.Hour), 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(base, "2026", "03", ".02.json.123.tmp"), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(base, "2026", "03", "05.json"), []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := doctor.Check(s, now)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	want := strings.Join([]string{
+		"duplicate_id:dup",
+		"duration_mismatch:dur",
+		"end_before_start:back",
+		"multiple_open:stale",
+		"overlap:clash",
+		"temp_file:" + filepath.Join("2026", "03", ".02.json.123.tmp"),
+		"unknown_source:odd",
+		"unparsable_file:" + filepath.Join("2026", "03", "05.json"),
+		"wrong_day:moved",
+	}, " ")
+	if got := kinds(issues); got != want {
+		t.Fatalf("issues =\n  %s\nwant\n  %s", got, want)
+	}
+
+	for _, i := range issues {
+		if i.Fixable() {
+			if err := i.Repair(); err != nil {
+				t.Fatalf("repair %s: %v", i.Kind, err)
+			}
+		}
+	}
+
+	issues, err = doctor.Check(s, now)
+	if err != nil {
+		t.Fatalf("Check after repair: %v", err)
+	}
+	want = "corrupt_file:" + filepath.Join("2026", "03", "05.json.corrupt") + " end_before_start:back overlap:clash unknown_source:odd"
+	if got := kinds(issues); got != want {
+		t.Fatalf("after repair: issues = %s, want %s", got, want)
+	}
+
+	df, err := s.LoadDay(d1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dups int
+	ids := map[string]bool{}
+	for _, e := range df.Entries {
+		switch {
+		case e.ID == "stale":
+			// Closed where the next entry starts.
+			if e.End == nil || !e.End.Equal(d1.Add(30*time.Minute)) || *e.DurationSeconds != 1800 {
+				t.Errorf("stale entry = %+v, want closed at 09:30", e)
+			}
+		case e.ID == "dur" && *e.DurationSeconds != 3600:
+			t.Errorf("duration = %d, want 3600", *e.DurationSeconds)
+		case e.ID == "moved":
+			t.Error("entry from another day still stored under 2026-03-02")
+		case e.Start.Equal(d1.Add(9*time.Hour)) || e.Start.Equal(d1.Add(10*time.Hour)):
+			dups++
+			ids[e.ID] = true
+		}
+	}
+	if dups != 2 || len(ids) != 2 {
+		t.Errorf("%d entries left from the duplicates, want 2", dups)
+	}
+	if active, _, err := s.FindActiveEntry(); err != nil || active == nil || active.ID != "running" {
+		t.Errorf("active = %+v, %v, want running", active, err)
+	}
+}
+
+func TestCheckMemoryStore(t *testing.T) {
+	s := storage.NewMemoryStore()
+	day := time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local)
+	if err := s.UpdateEntry(day, entry("a", day, time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateEntry(day, entry("b", day.Add(time.Hour), 0)); err != nil {
+		t.Fatal(err)
+	}
+	issues, err := doctor.Check(s, day.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("issues = %s, want none", kinds(issues))
+	}
+}
@@ -14,10 +14,60 @@ type Entry struct {
 	End             *time.Time `json:"end"`
 	DurationSeconds *int64     `json:"duration_seconds"`
 	Source          string     `json:"source"`
+	Breaks          []Break    `json:"breaks,omitempty"`
+}
+
+// Break is a paused interval within an entry. End is nil while the timer is
+// paused. DurationSeconds of the enclosing entry excludes all breaks.
+type Break struct {
+	Start time.Time  `json:"start"`
+	End   *time.Time `json:"end"`
 }
 
 // DayFile is the top-level structure stored in each daily JSON file.
+// SchemaVersion is the file format version; see storage.SchemaVersion.
 type DayFile struct {
-	Date    string  `json:"date"`
-	Entries []Entry `json:"entries"`
+	SchemaVersion int     `json:"schema_version"`
+	Date          string  `json:"date"`
+	Entries       []Entry `json:"entries"`
+}
+
+// TrashedEntry is a deleted entry kept in the trash so it can be restored.
+type TrashedEntry struct {
+	Entry     Entry     `json:"entry"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// WorkedSeconds returns the seconds in [from, to] not covered by breaks.
+// Open breaks count as lasting until to.
+func WorkedSeconds(from, to time.Time, breaks []Break) int64 {
+	worked := to.Sub(from)
+	for _, b := range ClipBreaks(breaks, from, to) {
+		worked -= b.End.Sub(b.Start)
+	}
+	return int64(worked.Seconds())
+}
+
+// ClipBreaks returns the parts of breaks that fall within [from, to], with
+// open breaks ending at to. Breaks outside the interval are dropped.
+func ClipBreaks(breaks []Break, from, to time.Time) []Break {
+	var out []Break
+	for _, b := range breaks {
+		start, end := b.Start, to
+		if b.End != nil {
+			end = *b.End
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if !end.After(start) {
+			continue
+		}
+		e := end
+		out = append(out, Break{Start: start, End: &e})
+	}
+	return out
 }
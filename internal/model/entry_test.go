@@ -0,0 +1,33 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+)
+
+func TestWorkedSeconds(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2026, 2, 27, h, m, 0, 0, time.UTC) }
+	bEnd := at(10, 15)
+	breaks := []model.Break{
+		{Start: at(10, 0), End: &bEnd},
+		{Start: at(11, 0)}, // still paused
+	}
+
+	tests := []struct {
+		from, to time.Time
+		want     int64
+	}{
+		{at(9, 0), at(9, 30), 30 * 60},
+		{at(9, 0), at(10, 30), 75 * 60},
+		{at(9, 0), at(11, 30), 105 * 60},
+		{at(10, 5), at(10, 10), 0},
+	}
+	for _, tt := range tests {
+		if got := model.WorkedSeconds(tt.from, tt.to, breaks); got != tt.want {
+			t.Errorf("WorkedSeconds(%s, %s) = %d, want %d",
+				tt.from.Format("15:04"), tt.to.Format("15:04"), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package model
+
+import "strings"
+
+// Project is a registered project with its metadata, stored in
+// ~/.ttt/projects.json.
+type Project struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Client      string   `json:"client,omitempty"`
+	DefaultTags []string `json:"default_tags,omitempty"`
+	Billable    bool     `json:"billable"`
+	Colour      string   `json:"colour,omitempty"`
+	Archived    bool     `json:"archived,omitempty"`
+}
+
+// ProjectFile is the top-level structure of projects.json.
+type ProjectFile struct {
+	Projects []Project `json:"projects"`
+}
+
+// SameProject reports whether two project names refer to the same project.
+// Names are compared case-insensitively and ignoring surrounding whitespace,
+// so "ECM", "ecm" and "ECM " are one project.
+func SameProject(a, b string) bool {
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
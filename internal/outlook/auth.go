@@ -0,0 +1,287 @@
+package outlook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultAuthorityURL is the Microsoft identity platform endpoint.
+const DefaultAuthorityURL = "https://login.microsoftonline.com"
+
+// Scopes requested by the device code flow. offline_access is required to
+// receive a refresh token.
+const Scopes = "offline_access Calendars.Read"
+
+// Token is the persisted OAuth2 token set.
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// Valid reports whether the access token can still be used at time now.
+// A one-minute safety margin avoids using a token that expires mid-request.
+func (t *Token) Valid(now time.Time) bool {
+	return t != nil && t.AccessToken != "" && now.Add(time.Minute).Before(t.ExpiresAt)
+}
+
+// LoadToken reads a token from path. It returns nil without an error if the
+// file does not exist.
+func LoadToken(path string) (*Token, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading token file %s: %w", path, err)
+	}
+	var tok Token
+	if err := json.Unmarshal(data, &tok); err != nil {
+		return nil, fmt.Errorf("parsing token file %s: %w", path, err)
+	}
+	return &tok, nil
+}
+
+// SaveToken writes a token to path with mode 0600, creating parent directories.
+func SaveToken(path string, tok *Token) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("creating token directory: %w", err)
+	}
+	data, err := json.MarshalIndent(tok, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding token: %w", err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return fmt.Errorf("writing token file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("writing token file: %w", err)
+	}
+	return nil
+}
+
+// Authenticator obtains access tokens via the OAuth2 device code flow and
+// keeps them fresh using the stored refresh token.
+type Authenticator struct {
+	AuthorityURL string
+	TenantID     string
+	ClientID     string
+	TokenPath    string
+	HTTPClient   *http.Client
+	// Prompt receives the device code sign-in instructions.
+	Prompt io.Writer
+	// Now and Sleep are overridable for tests.
+	Now   func() time.Time
+	Sleep func(time.Duration)
+}
+
+// tokenResponse is the token endpoint's success or error payload.
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// deviceCodeResponse is the device authorization endpoint's payload.
+type deviceCodeResponse struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int64  `json:"expires_in"`
+	Interval        int64  `json:"interval"`
+}
+
+// AccessToken returns a valid access token, refreshing or re-authenticating
+// as needed. New tokens are persisted to TokenPath.
+func (a *Authenticator) AccessToken(ctx context.Context) (string, error) {
+	tok, err := LoadToken(a.TokenPath)
+	if err != nil {
+		return "", err
+	}
+	if tok.Valid(a.now()) {
+		return tok.AccessToken, nil
+	}
+
+	var fresh *Token
+	if tok != nil && tok.RefreshToken != "" {
+		fresh, err = a.refresh(ctx, tok.RefreshToken)
+		if err != nil {
+			// The refresh token may have expired; fall back to interactive sign-in.
+			fresh = nil
+		}
+	}
+	if fresh == nil {
+		fresh, err = a.deviceCode(ctx)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if err := SaveToken(a.TokenPath, fresh); err != nil {
+		return "", err
+	}
+	return fresh.AccessToken, nil
+}
+
+func (a *Authenticator) endpoint(name string) string {
+	authority := a.AuthorityURL
+	if authority == "" {
+		authority = DefaultAuthorityURL
+	}
+	tenant := a.TenantID
+	if tenant == "" {
+		tenant = "common"
+	}
+	return strings.TrimRight(authority, "/") + "/" + url.PathEscape(tenant) + "/oauth2/v2.0/" + name
+}
+
+// refresh exchanges a refresh token for a new token set.
+func (a *Authenticator) refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {a.ClientID},
+		"refresh_token": {refreshToken},
+		"scope":         {Scopes},
+	}
+	var resp tokenResponse
+	if err := a.postForm(ctx, a.endpoint("token"), form, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("token refresh failed: %s: %s", resp.Error, resp.ErrorDescription)
+	}
+	return a.toToken(resp, refreshToken), nil
+}
+
+// deviceCode runs the interactive device code flow.
+func (a *Authenticator) deviceCode(ctx context.Context) (*Token, error) {
+	form := url.Values{
+		"client_id": {a.ClientID},
+		"scope":     {Scopes},
+	}
+	var dc deviceCodeResponse
+	if err := a.postForm(ctx, a.endpoint("devicecode"), form, &dc); err != nil {
+		return nil, err
+	}
+	if dc.DeviceCode == "" {
+		return nil, errors.New("device code request returned no device code")
+	}
+
+	if a.Prompt != nil {
+		fmt.Fprintln(a.Prompt, "To sign in, use a web browser to open the page:")
+		fmt.Fprintf(a.Prompt, "  %s\n", dc.VerificationURI)
+		fmt.Fprintf(a.Prompt, "Enter the code: %s\n\n", dc.UserCode)
+	}
+
+	interval := time.Duration(dc.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	deadline := a.now().Add(time.Duration(dc.ExpiresIn) * time.Second)
+
+	poll := url.Values{
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		"client_id":   {a.ClientID},
+		"device_code": {dc.DeviceCode},
+	}
+	for {
+		if dc.ExpiresIn > 0 && a.now().After(deadline) {
+			return nil, errors.New("device code expired before sign-in completed")
+		}
+		a.sleep(interval)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var resp tokenResponse
+		if err := a.postForm(ctx, a.endpoint("token"), poll, &resp); err != nil {
+			return nil, err
+		}
+		switch resp.Error {
+		case "":
+			return a.toToken(resp, ""), nil
+		case "authorization_pending":
+			continue
+		case "slow_down":
+			interval += 5 * time.Second
+			continue
+		default:
+			return nil, fmt.Errorf("sign-in failed: %s: %s", resp.Error, resp.ErrorDescription)
+		}
+	}
+}
+
+// toToken converts a token response into a Token, keeping the previous
+// refresh token if the server did not rotate it.
+func (a *Authenticator) toToken(resp tokenResponse, previousRefresh string) *Token {
+	refresh := resp.RefreshToken
+	if refresh == "" {
+		refresh = previousRefresh
+	}
+	return &Token{
+		AccessToken:  resp.AccessToken,
+		RefreshToken: refresh,
+		ExpiresAt:    a.now().Add(time.Duration(resp.ExpiresIn) * time.Second),
+	}
+}
+
+// postForm posts a URL-encoded form and decodes the JSON response into out.
+// OAuth2 error payloads are returned with HTTP 400, so non-2xx responses
+// carrying JSON are decoded rather than treated as transport errors.
+func (a *Authenticator) postForm(ctx context.Context, endpoint string, form url.Values, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := a.client().Do(req)
+	if err != nil {
+		return fmt.Errorf("contacting %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response from %s: %w", endpoint, err)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("unexpected response from %s (HTTP %d): %s", endpoint, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func (a *Authenticator) client() *http.Client {
+	if a.HTTPClient != nil {
+		return a.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+func (a *Authenticator) now() time.Time {
+	if a.Now != nil {
+		return a.Now()
+	}
+	return time.Now()
+}
+
+func (a *Authenticator) sleep(d time.Duration) {
+	if a.Sleep != nil {
+		a.Sleep(d)
+		return
+	}
+	time.Sleep(d)
+}
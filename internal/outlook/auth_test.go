@@ -0,0 +1,141 @@
+package outlook_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/outlook"
+)
+
+func TestAccessTokenDeviceCodeFlow(t *testing.T) {
+	polls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		switch r.URL.Path {
+		case "/tenant-x/oauth2/v2.0/devicecode":
+			if r.Form.Get("client_id") != "client-x" {
+				t.Errorf("client_id = %q, want %q", r.Form.Get("client_id"), "client-x")
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"device_code":      "dev-123",
+				"user_code":        "ABCD-1234",
+				"verification_uri": "https://microsoft.com/devicelogin",
+				"expires_in":       900,
+				"interval":         1,
+			})
+		case "/tenant-x/oauth2/v2.0/token":
+			if r.Form.Get("device_code") != "dev-123" {
+				t.Errorf("device_code = %q, want %q", r.Form.Get("device_code"), "dev-123")
+			}
+			polls++
+			if polls < 2 {
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": "authorization_pending"})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"access_token":  "access-1",
+				"refresh_token": "refresh-1",
+				"expires_in":    3600,
+			})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	var prompt bytes.Buffer
+	tokenPath := filepath.Join(t.TempDir(), "auth", "msgraph_tokens.json")
+	auth := &outlook.Authenticator{
+		AuthorityURL: srv.URL,
+		TenantID:     "tenant-x",
+		ClientID:     "client-x",
+		TokenPath:    tokenPath,
+		Prompt:       &prompt,
+		Sleep:        func(time.Duration) {},
+	}
+
+	tok, err := auth.AccessToken(context.Background())
+	if err != nil {
+		t.Fatalf("AccessToken: %v", err)
+	}
+	if tok != "access-1" {
+		t.Errorf("token = %q, want %q", tok, "access-1")
+	}
+	if !strings.Contains(prompt.String(), "ABCD-1234") {
+		t.Errorf("prompt %q does not contain user code", prompt.String())
+	}
+
+	saved, err := outlook.LoadToken(tokenPath)
+	if err != nil {
+		t.Fatalf("LoadToken: %v", err)
+	}
+	if saved == nil || saved.RefreshToken != "refresh-1" {
+		t.Errorf("saved token = %+v, want refresh token %q", saved, "refresh-1")
+	}
+
+	// A second call reuses the stored token without contacting the server.
+	polls = 100
+	tok, err = auth.AccessToken(context.Background())
+	if err != nil {
+		t.Fatalf("AccessToken (cached): %v", err)
+	}
+	if tok != "access-1" || polls != 100 {
+		t.Errorf("expected cached token without polling, got %q (polls=%d)", tok, polls)
+	}
+}
+
+func TestAccessTokenRefresh(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if r.URL.Path != "/common/oauth2/v2.0/token" || r.Form.Get("grant_type") != "refresh_token" {
+			t.Errorf("unexpected request %s grant_type=%q", r.URL.Path, r.Form.Get("grant_type"))
+		}
+		if r.Form.Get("refresh_token") != "old-refresh" {
+			t.Errorf("refresh_token = %q, want %q", r.Form.Get("refresh_token"), "old-refresh")
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "access-2",
+			"expires_in":   3600,
+		})
+	}))
+	defer srv.Close()
+
+	tokenPath := filepath.Join(t.TempDir(), "msgraph_tokens.json")
+	expired := &outlook.Token{
+		AccessToken:  "stale",
+		RefreshToken: "old-refresh",
+		ExpiresAt:    time.Now().Add(-time.Hour),
+	}
+	if err := outlook.SaveToken(tokenPath, expired); err != nil {
+		t.Fatal(err)
+	}
+
+	auth := &outlook.Authenticator{AuthorityURL: srv.URL, ClientID: "c", TokenPath: tokenPath}
+	tok, err := auth.AccessToken(context.Background())
+	if err != nil {
+		t.Fatalf("AccessToken: %v", err)
+	}
+	if tok != "access-2" {
+		t.Errorf("token = %q, want %q", tok, "access-2")
+	}
+
+	saved, err := outlook.LoadToken(tokenPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.RefreshToken != "old-refresh" {
+		t.Errorf("refresh token = %q, want the previous one to be kept", saved.RefreshToken)
+	}
+}
@@ -0,0 +1,150 @@
+package outlook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultGraphURL is the Microsoft Graph v1.0 API root.
+const DefaultGraphURL = "https://graph.microsoft.com/v1.0"
+
+// graphTimeLayout is the layout Graph uses for dateTimeTimeZone values.
+const graphTimeLayout = "2006-01-02T15:04:05.9999999"
+
+// DateTimeTimeZone mirrors Graph's dateTimeTimeZone resource.
+type DateTimeTimeZone struct {
+	DateTime string `json:"dateTime"`
+	TimeZone string `json:"timeZone"`
+}
+
+// Location mirrors the subset of Graph's location resource used by ttt.
+type Location struct {
+	DisplayName string `json:"displayName"`
+}
+
+// Event is the subset of a Graph calendar event used by ttt.
+type Event struct {
+	ID          string           `json:"id"`
+	Subject     string           `json:"subject"`
+	BodyPreview string           `json:"bodyPreview"`
+	IsCancelled bool             `json:"isCancelled"`
+	IsAllDay    bool             `json:"isAllDay"`
+	Sensitivity string           `json:"sensitivity"`
+	ShowAs      string           `json:"showAs"`
+	Start       DateTimeTimeZone `json:"start"`
+	End         DateTimeTimeZone `json:"end"`
+	Location    Location         `json:"location"`
+}
+
+// calendarViewPage is one page of a calendarView response.
+type calendarViewPage struct {
+	Value    []Event `json:"value"`
+	NextLink string  `json:"@odata.nextLink"`
+}
+
+// graphError is Graph's error envelope.
+type graphError struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Client is a minimal Microsoft Graph client for reading calendar events.
+type Client struct {
+	BaseURL     string
+	AccessToken string
+	HTTPClient  *http.Client
+}
+
+// CalendarView returns all events overlapping [from, to), following
+// @odata.nextLink paging. Event times are returned in the IANA timezone tz
+// (UTC when empty).
+func (c *Client) CalendarView(ctx context.Context, from, to time.Time, tz string) ([]Event, error) {
+	base := c.BaseURL
+	if base == "" {
+		base = DefaultGraphURL
+	}
+	q := url.Values{
+		"startDateTime": {from.UTC().Format(time.RFC3339)},
+		"endDateTime":   {to.UTC().Format(time.RFC3339)},
+		"$select":       {"id,subject,bodyPreview,isCancelled,isAllDay,sensitivity,showAs,start,end,location"},
+		"$orderby":      {"start/dateTime"},
+		"$top":          {"50"},
+	}
+	next := strings.TrimRight(base, "/") + "/me/calendarView?" + q.Encode()
+
+	if tz == "" {
+		tz = "UTC"
+	}
+
+	var events []Event
+	for next != "" {
+		var page calendarViewPage
+		if err := c.get(ctx, next, tz, &page); err != nil {
+			return nil, err
+		}
+		events = append(events, page.Value...)
+		next = page.NextLink
+	}
+	return events, nil
+}
+
+func (c *Client) get(ctx context.Context, endpoint, tz string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Prefer", fmt.Sprintf("outlook.timezone=%q", tz))
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("contacting Microsoft Graph: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading Microsoft Graph response: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		var ge graphError
+		if json.Unmarshal(body, &ge) == nil && ge.Error.Code != "" {
+			return fmt.Errorf("microsoft Graph error (HTTP %d): %s: %s", resp.StatusCode, ge.Error.Code, ge.Error.Message)
+		}
+		return fmt.Errorf("microsoft Graph error (HTTP %d)", resp.StatusCode)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decoding Microsoft Graph response: %w", err)
+	}
+	return nil
+}
+
+// ParseTime converts a Graph dateTimeTimeZone into a time.Time. Graph omits
+// the offset from dateTime, so the value is interpreted in its own timeZone
+// when that is a known IANA name, otherwise in fallback.
+func ParseTime(v DateTimeTimeZone, fallback *time.Location) (time.Time, error) {
+	loc := fallback
+	if v.TimeZone != "" {
+		if l, err := time.LoadLocation(v.TimeZone); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation(graphTimeLayout, v.DateTime, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid event time %q: %w", v.DateTime, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,81 @@
+package outlook_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/outlook"
+)
+
+func TestCalendarViewPaging(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer tok" {
+			t.Errorf("Authorization = %q, want %q", got, "Bearer tok")
+		}
+		if got := r.Header.Get("Prefer"); got != `outlook.timezone="Europe/Berlin"` {
+			t.Errorf("Prefer = %q", got)
+		}
+		if r.URL.Path != "/me/calendarView" {
+			t.Errorf("path = %q, want /me/calendarView", r.URL.Path)
+		}
+		page := map[string]any{}
+		if r.URL.Query().Get("page") == "2" {
+			page["value"] = []map[string]any{{"id": "b", "subject": "Second"}}
+		} else {
+			if r.URL.Query().Get("startDateTime") != "2026-02-26T23:00:00Z" {
+				t.Errorf("startDateTime = %q", r.URL.Query().Get("startDateTime"))
+			}
+			page["value"] = []map[string]any{{"id": "a", "subject": "First"}}
+			page["@odata.nextLink"] = srv.URL + "/me/calendarView?page=2"
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	}))
+	defer srv.Close()
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("tzdata not available")
+	}
+	from := time.Date(2026, 2, 27, 0, 0, 0, 0, berlin)
+	client := &outlook.Client{BaseURL: srv.URL, AccessToken: "tok"}
+	events, err := client.CalendarView(context.Background(), from, from.AddDate(0, 0, 1), "Europe/Berlin")
+	if err != nil {
+		t.Fatalf("CalendarView: %v", err)
+	}
+	if len(events) != 2 || events[0].ID != "a" || events[1].ID != "b" {
+		t.Errorf("events = %+v, want a then b", events)
+	}
+}
+
+func TestCalendarViewError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":{"code":"InvalidAuthenticationToken","message":"expired"}}`))
+	}))
+	defer srv.Close()
+
+	client := &outlook.Client{BaseURL: srv.URL, AccessToken: "tok"}
+	_, err := client.CalendarView(context.Background(), time.Now(), time.Now(), "")
+	if err == nil {
+		t.Fatal("expected error for HTTP 401, got nil")
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	got, err := outlook.ParseTime(outlook.DateTimeTimeZone{
+		DateTime: "2026-02-27T09:00:00.0000000",
+		TimeZone: "UTC",
+	}, time.Local)
+	if err != nil {
+		t.Fatalf("ParseTime: %v", err)
+	}
+	want := time.Date(2026, 2, 27, 9, 0, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("ParseTime = %v, want %v", got, want)
+	}
+}
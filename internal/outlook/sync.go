@@ -0,0 +1,161 @@
+package outlook
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
+)
+
+// Source is the model.Entry source value for imported calendar events.
+const Source = "outlook"
+
+// Action is the planned sync operation for a single event.
+type Action int
+
+const (
+	// ActionImport creates a new entry.
+	ActionImport Action = iota
+	// ActionSkip leaves local data untouched.
+	ActionSkip
+	// ActionUpdate rewrites an existing entry whose event changed.
+	ActionUpdate
+)
+
+// Result describes the planned operation for one calendar event.
+type Result struct {
+	Action Action
+	Event  Event
+	// Reason explains why an event was skipped.
+	Reason string
+	// Entry is the entry to write for ActionImport and ActionUpdate.
+	Entry model.Entry
+	// Previous is the existing entry replaced by ActionUpdate.
+	Previous *model.Entry
+}
+
+// SkipReason returns why an event should not be imported, or "" if it should.
+func SkipReason(ev Event) string {
+	switch {
+	case ev.IsCancelled:
+		return "cancelled"
+	case ev.IsAllDay:
+		return "all-day"
+	case strings.EqualFold(ev.Sensitivity, "private"):
+		return "private"
+	case strings.EqualFold(ev.ShowAs, "free"):
+		return "free"
+	}
+	return ""
+}
+
+// ToEntry converts an event into a new ttt entry assigned to project.
+func ToEntry(ev Event, project string, loc *time.Location) (model.Entry, error) {
+	start, err := ParseTime(ev.Start, loc)
+	if err != nil {
+		return model.Entry{}, err
+	}
+	end, err := ParseTime(ev.End, loc)
+	if err != nil {
+		return model.Entry{}, err
+	}
+	start = start.In(loc)
+	end = end.In(loc)
+	dur := int64(end.Sub(start).Seconds())
+
+	entry := model.Entry{
+		ID:              timecalc.GenerateID(start),
+		ExternalID:      ev.ID,
+		Project:         project,
+		Tags:            []string{"outlook"},
+		Start:           start,
+		End:             &end,
+		DurationSeconds: &dur,
+		Source:          Source,
+	}
+	if subject := strings.TrimSpace(ev.Subject); subject != "" {
+		entry.Task = &subject
+	}
+	var parts []string
+	if l := strings.TrimSpace(ev.Location.DisplayName); l != "" {
+		parts = append(parts, l)
+	}
+	if b := strings.TrimSpace(ev.BodyPreview); b != "" {
+		parts = append(parts, b)
+	}
+	if len(parts) > 0 {
+		comment := strings.Join(parts, "\n")
+		entry.Comment = &comment
+	}
+	return entry, nil
+}
+
+// Plan decides what to do with each event given the entries already stored.
+// Existing entries are matched by ExternalID; only entries whose source is
+// outlook are considered, so manual entries are never touched. A matched entry
+// is updated when the subject or time window changed, keeping its ID, project,
+// tags and comment so local edits survive re-syncs.
+func Plan(events []Event, existing []model.Entry, project string, loc *time.Location) ([]Result, error) {
+	byExternal := map[string]model.Entry{}
+	for _, e := range existing {
+		if e.Source == Source && e.ExternalID != "" {
+			byExternal[e.ExternalID] = e
+		}
+	}
+
+	results := make([]Result, 0, len(events))
+	for _, ev := range events {
+		if reason := SkipReason(ev); reason != "" {
+			results = append(results, Result{Action: ActionSkip, Event: ev, Reason: reason})
+			continue
+		}
+
+		fresh, err := ToEntry(ev, project, loc)
+		if err != nil {
+			return nil, err
+		}
+
+		prev, ok := byExternal[ev.ID]
+		if !ok {
+			results = append(results, Result{Action: ActionImport, Event: ev, Entry: fresh})
+			continue
+		}
+
+		if unchanged(prev, fresh) {
+			results = append(results, Result{Action: ActionSkip, Event: ev, Reason: "already exists"})
+			continue
+		}
+
+		updated := prev
+		updated.Task = fresh.Task
+		updated.Start = fresh.Start
+		updated.End = fresh.End
+		updated.DurationSeconds = fresh.DurationSeconds
+		p := prev
+		results = append(results, Result{Action: ActionUpdate, Event: ev, Entry: updated, Previous: &p})
+	}
+	return results, nil
+}
+
+// unchanged reports whether an existing entry still matches the event's
+// subject and time window.
+func unchanged(prev, fresh model.Entry) bool {
+	if derefString(prev.Task) != derefString(fresh.Task) {
+		return false
+	}
+	if !prev.Start.Equal(fresh.Start) {
+		return false
+	}
+	if prev.End == nil || fresh.End == nil {
+		return prev.End == fresh.End
+	}
+	return prev.End.Equal(*fresh.End)
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,106 @@
+package outlook_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/outlook"
+)
+
+func event(id, subject, start, end string) outlook.Event {
+	return outlook.Event{
+		ID:      id,
+		Subject: subject,
+		Start:   outlook.DateTimeTimeZone{DateTime: start, TimeZone: "UTC"},
+		End:     outlook.DateTimeTimeZone{DateTime: end, TimeZone: "UTC"},
+	}
+}
+
+func TestSkipReason(t *testing.T) {
+	tests := []struct {
+		ev   outlook.Event
+		want string
+	}{
+		{outlook.Event{}, ""},
+		{outlook.Event{IsCancelled: true}, "cancelled"},
+		{outlook.Event{IsAllDay: true}, "all-day"},
+		{outlook.Event{Sensitivity: "private"}, "private"},
+		{outlook.Event{ShowAs: "free"}, "free"},
+		{outlook.Event{ShowAs: "busy", Sensitivity: "normal"}, ""},
+	}
+	for _, tt := range tests {
+		if got := outlook.SkipReason(tt.ev); got != tt.want {
+			t.Errorf("SkipReason(%+v) = %q, want %q", tt.ev, got, tt.want)
+		}
+	}
+}
+
+func TestToEntry(t *testing.T) {
+	ev := event("ext-1", "Architecture Board", "2026-02-27T09:00:00.0000000", "2026-02-27T10:30:00.0000000")
+	ev.Location.DisplayName = "Zoom"
+	ev.BodyPreview = "Quarterly roadmap discussion"
+
+	e, err := outlook.ToEntry(ev, "Meetings", time.UTC)
+	if err != nil {
+		t.Fatalf("ToEntry: %v", err)
+	}
+	if e.ExternalID != "ext-1" || e.Project != "Meetings" || e.Source != "outlook" {
+		t.Errorf("entry = %+v", e)
+	}
+	if e.Task == nil || *e.Task != "Architecture Board" {
+		t.Errorf("task = %v, want %q", e.Task, "Architecture Board")
+	}
+	if e.Comment == nil || *e.Comment != "Zoom\nQuarterly roadmap discussion" {
+		t.Errorf("comment = %v", e.Comment)
+	}
+	if e.DurationSeconds == nil || *e.DurationSeconds != 5400 {
+		t.Errorf("duration = %v, want 5400", e.DurationSeconds)
+	}
+}
+
+func TestPlanIsIdempotent(t *testing.T) {
+	events := []outlook.Event{
+		event("new", "New Meeting", "2026-02-27T08:00:00", "2026-02-27T08:30:00"),
+		event("same", "Weekly Sync", "2026-02-27T09:00:00", "2026-02-27T09:30:00"),
+		event("moved", "Design Review", "2026-02-27T11:00:00", "2026-02-27T12:00:00"),
+		{ID: "gone", Subject: "Cancelled", IsCancelled: true},
+	}
+
+	same, err := outlook.ToEntry(events[1], "Meetings", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	moved, err := outlook.ToEntry(event("moved", "Design Review", "2026-02-27T11:00:00", "2026-02-27T11:30:00"), "Custom", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A manual entry carrying the same external ID must never be matched.
+	manual := model.Entry{ID: "m1", ExternalID: "new", Project: "X", Source: "manual"}
+
+	results, err := outlook.Plan(events, []model.Entry{same, moved, manual}, "Meetings", time.UTC)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+
+	want := []outlook.Action{outlook.ActionImport, outlook.ActionSkip, outlook.ActionUpdate, outlook.ActionSkip}
+	for i, r := range results {
+		if r.Action != want[i] {
+			t.Errorf("result[%d] (%s) action = %v, want %v", i, r.Event.ID, r.Action, want[i])
+		}
+	}
+
+	upd := results[2]
+	if upd.Entry.ID != moved.ID {
+		t.Errorf("updated entry ID = %q, want existing %q", upd.Entry.ID, moved.ID)
+	}
+	if upd.Entry.Project != "Custom" {
+		t.Errorf("updated entry project = %q, want local edit %q kept", upd.Entry.Project, "Custom")
+	}
+	if *upd.Entry.DurationSeconds != 3600 {
+		t.Errorf("updated duration = %d, want 3600", *upd.Entry.DurationSeconds)
+	}
+	if results[3].Reason != "cancelled" {
+		t.Errorf("skip reason = %q, want %q", results[3].Reason, "cancelled")
+	}
+}
@@ -0,0 +1,308 @@
+// Package overlap finds entries that cover the same time, resolves such
+// conflicts and computes durations that count shared time only once.
+package overlap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
+)
+
+// Strategy selects how Resolve settles a conflict.
+type Strategy string
+
+const (
+	// TrimEarlier ends the earlier entry where the later one starts. Any
+	// part of it after the later entry is dropped.
+	TrimEarlier Strategy = "trim-earlier"
+	// TrimLater starts the later entry where the earlier one ends.
+	TrimLater Strategy = "trim-later"
+	// Split cuts the earlier entry around the later one, keeping the parts
+	// before and after it.
+	Split Strategy = "split"
+	// Priority keeps the entry whose source ranks higher and cuts the other
+	// one around it.
+	Priority Strategy = "priority"
+)
+
+// Strategies lists the valid strategies in the order they are documented.
+var Strategies = []Strategy{TrimEarlier, TrimLater, Split, Priority}
+
+// DefaultPriority ranks calendar meetings above manually tracked time, which
+// is usually recorded more loosely.
+var DefaultPriority = []string{"outlook", "manual"}
+
+// ParseStrategy validates a strategy name.
+func ParseStrategy(s string) (Strategy, error) {
+	for _, st := range Strategies {
+		if string(st) == s {
+			return st, nil
+		}
+	}
+	names := make([]string, len(Strategies))
+	for i, st := range Strategies {
+		names[i] = string(st)
+	}
+	return "", fmt.Errorf("unknown strategy %q: expected one of %s", s, strings.Join(names, ", "))
+}
+
+// interval is a half-open span of time [Start, End).
+type interval struct {
+	Start, End time.Time
+}
+
+// worked returns the intervals of e that are not breaks. Open entries and
+// open breaks last until now.
+func worked(e model.Entry, now time.Time) []interval {
+	end := now
+	if e.End != nil {
+		end = *e.End
+	}
+	if !end.After(e.Start) {
+		return nil
+	}
+	var out []interval
+	cur := e.Start
+	breaks := model.ClipBreaks(e.Breaks, e.Start, end)
+	sort.Slice(breaks, func(i, j int) bool { return breaks[i].Start.Before(breaks[j].Start) })
+	for _, b := range breaks {
+		if b.Start.After(cur) {
+			out = append(out, interval{cur, b.Start})
+		}
+		if b.End.After(cur) {
+			cur = *b.End
+		}
+	}
+	if end.After(cur) {
+		out = append(out, interval{cur, end})
+	}
+	return out
+}
+
+// intersect returns the parts of a that also lie in b. Both must be sorted
+// and free of overlaps.
+func intersect(a, b []interval) []interval {
+	var out []interval
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		start, end := a[i].Start, a[i].End
+		if b[j].Start.After(start) {
+			start = b[j].Start
+		}
+		if b[j].End.Before(end) {
+			end = b[j].End
+		}
+		if end.After(start) {
+			out = append(out, interval{start, end})
+		}
+		if a[i].End.Before(b[j].End) {
+			i++
+		} else {
+			j++
+		}
+	}
+	return out
+}
+
+func total(ivs []interval) time.Duration {
+	var d time.Duration
+	for _, iv := range ivs {
+		d += iv.End.Sub(iv.Start)
+	}
+	return d
+}
+
+// Conflict is a pair of entries whose worked time overlaps. Earlier starts
+// no later than Later.
+type Conflict struct {
+	Earlier, Later model.Entry
+	// Start and End span the shared time.
+	Start, End time.Time
+	// Shared is the time counted by both entries; it is less than End-Start
+	// when a break of one entry falls into the other.
+	Shared time.Duration
+}
+
+// Find returns every pair of entries in entries whose worked time overlaps,
+// ordered by the start of the shared time. Time within a break does not
+// count, so a meeting during a paused timer is no conflict.
+func Find(entries []model.Entry, now time.Time) []Conflict {
+	sorted := append([]model.Entry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+	spans := make([][]interval, len(sorted))
+	for i, e := range sorted {
+		spans[i] = worked(e, now)
+	}
+
+	var out []Conflict
+	for i := range sorted {
+		if len(spans[i]) == 0 {
+			continue
+		}
+		last := spans[i][len(spans[i])-1].End
+		for j := i + 1; j < len(sorted) && sorted[j].Start.Before(last); j++ {
+			shared := intersect(spans[i], spans[j])
+			if len(shared) == 0 {
+				continue
+			}
+			out = append(out, Conflict{
+				Earlier: sorted[i],
+				Later:   sorted[j],
+				Start:   shared[0].Start,
+				End:     shared[len(shared)-1].End,
+				Shared:  total(shared),
+			})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return out
+}
+
+// Change replaces Entry with Result. An empty Result means the entry is
+// covered completely and should be removed. The first result keeps the ID
+// of Entry; further results are new entries.
+type Change struct {
+	Entry  model.Entry
+	Result []model.Entry
+	// Winner is the entry that keeps the shared time.
+	Winner model.Entry
+}
+
+// Resolve settles c with the given strategy. priority ranks sources for the
+// Priority strategy, highest first; unlisted sources rank below all listed
+// ones. Conflicts with a running entry, and Priority conflicts between
+// sources of equal rank, cannot be resolved.
+func Resolve(c Conflict, s Strategy, priority []string) (Change, error) {
+	if c.Earlier.End == nil || c.Later.End == nil {
+		return Change{}, fmt.Errorf("involves the running timer; stop it first")
+	}
+	earlier, later := c.Earlier, c.Later
+	switch s {
+	case TrimEarlier:
+		return Change{Entry: earlier, Winner: later, Result: keep(earlier, interval{earlier.Start, later.Start})}, nil
+	case TrimLater:
+		return Change{Entry: later, Winner: earlier, Result: keep(later, interval{*earlier.End, *later.End})}, nil
+	case Split:
+		return cutAround(earlier, later), nil
+	case Priority:
+		re, rl := rank(earlier.Source, priority), rank(later.Source, priority)
+		switch {
+		case re < rl:
+			return cutAround(later, earlier), nil
+		case rl < re:
+			return cutAround(earlier, later), nil
+		}
+		return Change{}, fmt.Errorf("both entries come from sources of equal priority (%s)", earlier.Source)
+	}
+	return Change{}, fmt.Errorf("unknown strategy %q", s)
+}
+
+// cutAround removes the time of winner from loser, keeping what remains
+// before and after it.
+func cutAround(loser, winner model.Entry) Change {
+	return Change{
+		Entry:  loser,
+		Winner: winner,
+		Result: keep(loser, interval{loser.Start, winner.Start}, interval{*winner.End, *loser.End}),
+	}
+}
+
+// keep returns the parts of e within the given intervals, each clipped to e
+// and dropped when empty. The first part keeps the ID of e; the others get
+// new IDs and no external ID, so a calendar sync only matches the first.
+func keep(e model.Entry, parts ...interval) []model.Entry {
+	var out []model.Entry
+	for _, p := range parts {
+		if p.Start.Before(e.Start) {
+			p.Start = e.Start
+		}
+		if p.End.After(*e.End) {
+			p.End = *e.End
+		}
+		if !p.End.After(p.Start) {
+			continue
+		}
+		part := e
+		part.Start = p.Start
+		end := p.End
+		part.End = &end
+		part.Breaks = model.ClipBreaks(e.Breaks, p.Start, p.End)
+		dur := model.WorkedSeconds(p.Start, p.End, part.Breaks)
+		part.DurationSeconds = &dur
+		if len(out) > 0 {
+			part.ID = timecalc.GenerateID(p.Start)
+			part.ExternalID = ""
+		}
+		out = append(out, part)
+	}
+	return out
+}
+
+// rank returns the position of source in priority, or len(priority) when it
+// is not listed.
+func rank(source string, priority []string) int {
+	for i, p := range priority {
+		if strings.EqualFold(p, source) {
+			return i
+		}
+	}
+	return len(priority)
+}
+
+// Dedupe returns copies of entries whose durations count time shared with
+// other entries only once. Shared time goes to the entry whose source ranks
+// highest in priority, then to the one that started first. Running entries
+// have no duration and are returned unchanged.
+func Dedupe(entries []model.Entry, priority []string) []model.Entry {
+	order := make([]int, len(entries))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ea, eb := entries[order[a]], entries[order[b]]
+		if ra, rb := rank(ea.Source, priority), rank(eb.Source, priority); ra != rb {
+			return ra < rb
+		}
+		return ea.Start.Before(eb.Start)
+	})
+
+	out := append([]model.Entry(nil), entries...)
+	var covered []interval
+	for _, i := range order {
+		e := entries[i]
+		if e.End == nil || e.DurationSeconds == nil {
+			continue
+		}
+		spans := worked(e, *e.End)
+		shared := total(intersect(spans, covered))
+		if shared > 0 {
+			dur := *e.DurationSeconds - int64(shared.Seconds())
+			if dur < 0 {
+				dur = 0
+			}
+			out[i].DurationSeconds = &dur
+		}
+		covered = union(covered, spans)
+	}
+	return out
+}
+
+// union merges two sorted, non-overlapping interval lists.
+func union(a, b []interval) []interval {
+	all := append(append([]interval(nil), a...), b...)
+	sort.Slice(all, func(i, j int) bool { return all[i].Start.Before(all[j].Start) })
+	var out []interval
+	for _, iv := range all {
+		if n := len(out); n > 0 && !iv.Start.After(out[n-1].End) {
+			if iv.End.After(out[n-1].End) {
+				out[n-1].End = iv.End
+			}
+			continue
+		}
+		out = append(out, iv)
+	}
+	return out
+}
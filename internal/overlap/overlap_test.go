@@ -0,0 +1,120 @@
+package overlap_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/overlap"
+)
+
+var day = time.Date(2026, 2, 27, 0, 0, 0, 0, time.Local)
+
+// at returns the time h:m on day.
+func at(h, m int) time.Time {
+	return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute)
+}
+
+func entry(id, source string, from, to time.Time) model.Entry {
+	dur := int64(to.Sub(from).Seconds())
+	return model.Entry{ID: id, Project: "P", Start: from, End: &to, DurationSeconds: &dur, Source: source}
+}
+
+// spans formats the times of entries, e.g. "09:00-10:00, 11:00-12:00".
+func spans(entries []model.Entry) string {
+	var out []string
+	for _, e := range entries {
+		out = append(out, e.Start.Format("15:04")+"-"+e.End.Format("15:04"))
+	}
+	return strings.Join(out, ", ")
+}
+
+func TestFind(t *testing.T) {
+	bEnd := at(10, 30)
+	paused := entry("paused", "manual", at(8, 0), at(12, 0))
+	paused.Breaks = []model.Break{{Start: at(10, 0), End: &bEnd}}
+	entries := []model.Entry{
+		paused,
+		entry("meeting", "outlook", at(10, 0), at(10, 30)), // during the break
+		entry("late", "manual", at(11, 30), at(13, 0)),
+		entry("inside", "manual", at(12, 15), at(12, 45)),
+	}
+
+	got := overlap.Find(entries, at(18, 0))
+	var desc []string
+	for _, c := range got {
+		desc = append(desc, c.Earlier.ID+"/"+c.Later.ID+" "+c.Start.Format("15:04")+"-"+c.End.Format("15:04")+" "+c.Shared.String())
+	}
+	want := "paused/late 11:30-12:00 30m0s, late/inside 12:15-12:45 30m0s"
+	if strings.Join(desc, ", ") != want {
+		t.Errorf("Find = %s, want %s", strings.Join(desc, ", "), want)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	manual := entry("m", "manual", at(9, 0), at(12, 0))
+	meeting := entry("o", "outlook", at(10, 0), at(11, 0))
+	c := overlap.Find([]model.Entry{manual, meeting}, at(18, 0))[0]
+
+	tests := []struct {
+		strategy overlap.Strategy
+		priority []string
+		changed  string
+		want     string
+	}{
+		{overlap.TrimEarlier, nil, "m", "09:00-10:00"},
+		{overlap.TrimLater, nil, "o", ""},
+		{overlap.Split, nil, "m", "09:00-10:00, 11:00-12:00"},
+		{overlap.Priority, overlap.DefaultPriority, "m", "09:00-10:00, 11:00-12:00"},
+		{overlap.Priority, []string{"manual"}, "o", ""},
+	}
+	for _, tt := range tests {
+		ch, err := overlap.Resolve(c, tt.strategy, tt.priority)
+		if err != nil {
+			t.Errorf("Resolve(%s): %v", tt.strategy, err)
+			continue
+		}
+		if ch.Entry.ID != tt.changed || spans(ch.Result) != tt.want {
+			t.Errorf("Resolve(%s, %v) changes %s to %q, want %s to %q", tt.strategy, tt.priority, ch.Entry.ID, spans(ch.Result), tt.changed, tt.want)
+		}
+		if len(ch.Result) > 0 && ch.Result[0].ID != ch.Entry.ID {
+			t.Errorf("Resolve(%s): first part has ID %s, want %s", tt.strategy, ch.Result[0].ID, ch.Entry.ID)
+		}
+		if len(ch.Result) == 2 && (ch.Result[1].ID == ch.Entry.ID || *ch.Result[1].DurationSeconds != 3600) {
+			t.Errorf("Resolve(%s): second part = %+v, want a new 1h entry", tt.strategy, ch.Result[1])
+		}
+	}
+
+	if _, err := overlap.Resolve(c, overlap.Priority, []string{"jira"}); err == nil {
+		t.Error("Resolve(priority) with equally ranked sources succeeded, want error")
+	}
+	running := c
+	running.Later.End = nil
+	if _, err := overlap.Resolve(running, overlap.Split, nil); err == nil {
+		t.Error("Resolve with a running entry succeeded, want error")
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	entries := []model.Entry{
+		entry("m", "manual", at(9, 0), at(12, 0)),
+		entry("o", "outlook", at(10, 0), at(11, 0)),
+		entry("m2", "manual", at(11, 30), at(12, 30)),
+	}
+	got := overlap.Dedupe(entries, overlap.DefaultPriority)
+	want := map[string]int64{"m": 2 * 3600, "o": 3600, "m2": 1800}
+	var sum int64
+	for _, e := range got {
+		if *e.DurationSeconds != want[e.ID] {
+			t.Errorf("%s: duration %d, want %d", e.ID, *e.DurationSeconds, want[e.ID])
+		}
+		sum += *e.DurationSeconds
+	}
+	if sum != int64(at(12, 30).Sub(at(9, 0)).Seconds()) {
+		t.Errorf("total %d, want the wall time 09:00-12:30", sum)
+	}
+	if *entries[0].DurationSeconds != 3*3600 {
+		t.Error("Dedupe modified its input")
+	}
+}
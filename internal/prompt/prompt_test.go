@@ -0,0 +1,132 @@
+package prompt_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/prompt"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+)
+
+var day = time.Date(2026, 2, 27, 0, 0, 0, 0, time.Local)
+
+// at returns the time h:m on day.
+func at(h, m int) time.Time {
+	return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute)
+}
+
+func closed(id string, from, to time.Time) model.Entry {
+	dur := int64(to.Sub(from).Seconds())
+	return model.Entry{ID: id, Project: "ops", Start: from, End: &to, DurationSeconds: &dur}
+}
+
+// running returns a state with a timer on ECM started at 9:00 and a
+// 30-minute break, after an hour of closed entries.
+func running() prompt.State {
+	task := "REST refactor"
+	bEnd := at(10, 30)
+	return prompt.State{
+		Date: day.Format("2006-01-02"),
+		Active: &model.Entry{ID: "e1", Project: "ECM", Task: &task, Start: at(9, 0),
+			Breaks: []model.Break{{Start: at(10, 0), End: &bEnd}}},
+		ClosedSeconds: 3600,
+	}
+}
+
+func TestRender(t *testing.T) {
+	now := at(11, 15)
+	paused := running()
+	paused.Active.Breaks = append(paused.Active.Breaks, model.Break{Start: at(11, 0)})
+
+	tests := []struct {
+		src  string
+		st   prompt.State
+		want string
+	}{
+		{"{project}:{task} {elapsed}", running(), "ECM:REST refactor 1:45"},
+		{"{start} {state} {today} {today_seconds}", running(), "09:00 running 2:45 9900"},
+		{"{project}[ ({paused})]", running(), "ECM"},
+		{"{project}[ ({paused})] {elapsed}", paused, "ECM (paused) 1:30"},
+		{"{project}[ #{tags}]", running(), "ECM"},
+		{`\{{project}\} \[x\]`, running(), "{ECM} [x]"},
+		{"{state} [{project}]{today}", prompt.State{ClosedSeconds: 600}, "idle 0:10"},
+	}
+	for _, tt := range tests {
+		tmpl, err := prompt.Compile(tt.src)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", tt.src, err)
+		}
+		if got := tmpl.Render(tt.st, now); got != tt.want {
+			t.Errorf("Render(%q) = %q, want %q", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct{ src, want string }{
+		{"{project", "column 1: unclosed {"},
+		{"x {nope}", "column 3: unknown field {nope}"},
+		{"[a [b]]", "column 4: groups cannot be nested"},
+		{"a]", "column 2: ] without ["},
+		{"[{task}", "unclosed ["},
+		{`a\`, "column 2: trailing backslash"},
+	}
+	for _, tt := range tests {
+		_, err := prompt.Compile(tt.src)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Compile(%q) = %v, want %q", tt.src, err, tt.want)
+		}
+	}
+}
+
+func TestTodaySecondsSinceMidnight(t *testing.T) {
+	st := prompt.State{Active: &model.Entry{Project: "ECM", Start: at(-2, 0)}, ClosedSeconds: 60}
+	if got := st.TodaySeconds(at(1, 0)); got != 3660 {
+		t.Errorf("TodaySeconds = %d, want 3660", got)
+	}
+	if got := st.ElapsedSeconds(at(1, 0)); got != 3*3600 {
+		t.Errorf("ElapsedSeconds = %d, want %d", got, 3*3600)
+	}
+}
+
+func TestBuildSaveLoad(t *testing.T) {
+	store := storage.NewMemoryStore()
+	for _, e := range []model.Entry{closed("a", at(8, 0), at(9, 0)), closed("b", at(9, 0), at(9, 30))} {
+		if err := store.UpdateEntry(day, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	active := model.Entry{ID: "c", Project: "ECM", Start: at(9, 30)}
+	if err := store.UpdateEntry(day, active); err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := prompt.Build(store, at(10, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Active == nil || st.Active.ID != "c" || st.ClosedSeconds != 5400 {
+		t.Fatalf("Build = %+v, want active c and 5400 closed seconds", st)
+	}
+
+	path := filepath.Join(t.TempDir(), prompt.FileName)
+	if _, err := prompt.Load(path); err == nil {
+		t.Error("Load of a missing file succeeded")
+	}
+	if err := prompt.Save(path, st); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := prompt.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Active == nil || loaded.Active.ID != "c" || loaded.ClosedSeconds != 5400 {
+		t.Errorf("Load = %+v, want the saved state", loaded)
+	}
+	if !loaded.Current(at(23, 59)) || loaded.Current(at(24, 1)) {
+		t.Error("Current should hold until midnight only")
+	}
+}
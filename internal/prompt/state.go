@@ -0,0 +1,135 @@
+// Package prompt renders the running timer for shell prompts and status
+// bars. Prompts are drawn constantly, so they read a small state file that
+// ttt rewrites after every command instead of opening the store.
+package prompt
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
+)
+
+// FileName is the name of the state file in the data directory.
+const FileName = "prompt.json"
+
+// stateVersion changes whenever the state file format changes; a file with
+// another version is rebuilt.
+const stateVersion = 1
+
+// State is what a prompt shows: the active entry and today's total.
+type State struct {
+	Version int `json:"version"`
+	// Date is the day ClosedSeconds refers to, as YYYY-MM-DD.
+	Date string `json:"date"`
+	// Active is the running or paused entry, or nil.
+	Active *model.Entry `json:"active"`
+	// ClosedSeconds is the time of the stopped entries stored for Date.
+	ClosedSeconds int64 `json:"closed_seconds"`
+}
+
+// Build reads the state from store.
+func Build(s storage.Store, now time.Time) (State, error) {
+	active, _, err := s.FindActiveEntry()
+	if err != nil {
+		return State{}, err
+	}
+	df, err := s.LoadDay(now)
+	if err != nil {
+		return State{}, err
+	}
+	st := State{Version: stateVersion, Date: now.Format("2006-01-02"), Active: active}
+	for _, e := range df.Entries {
+		if e.DurationSeconds != nil {
+			st.ClosedSeconds += *e.DurationSeconds
+		}
+	}
+	return st, nil
+}
+
+// Current reports whether st was built on the day of now. Today's total
+// starts from zero at midnight, so an older state has to be rebuilt.
+func (st State) Current(now time.Time) bool {
+	return st.Date == now.Format("2006-01-02")
+}
+
+// Paused reports whether the active entry is on a break.
+func (st State) Paused() bool {
+	if st.Active == nil {
+		return false
+	}
+	n := len(st.Active.Breaks)
+	return n > 0 && st.Active.Breaks[n-1].End == nil
+}
+
+// ElapsedSeconds returns the time worked on the active entry until now,
+// without breaks, or 0 if no timer is running.
+func (st State) ElapsedSeconds(now time.Time) int64 {
+	if st.Active == nil {
+		return 0
+	}
+	return model.WorkedSeconds(st.Active.Start, now, st.Active.Breaks)
+}
+
+// TodaySeconds returns the time logged today, including the part of the
+// active entry since midnight.
+func (st State) TodaySeconds(now time.Time) int64 {
+	total := st.ClosedSeconds
+	if st.Active != nil {
+		since := st.Active.Start
+		if midnight := timecalc.StartOfDay(now); since.Before(midnight) {
+			since = midnight
+		}
+		total += model.WorkedSeconds(since, now, st.Active.Breaks)
+	}
+	return total
+}
+
+// Load reads the state file at path. A missing, damaged or outdated file is
+// an error; callers rebuild it with Build.
+func Load(path string) (State, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return State{}, err
+	}
+	var st State
+	if err := json.Unmarshal(data, &st); err != nil {
+		return State{}, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if st.Version != stateVersion {
+		return State{}, fmt.Errorf("%s has version %d, want %d", path, st.Version, stateVersion)
+	}
+	return st, nil
+}
+
+// Save atomically writes st to path.
+func Save(path string, st State) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("prompt state error encoding: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("prompt state error creating directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+FileName+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("prompt state error writing: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("prompt state error writing: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("prompt state error writing: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("prompt state error renaming: %w", err)
+	}
+	return nil
+}
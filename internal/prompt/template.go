@@ -0,0 +1,194 @@
+package prompt
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fields maps the placeholders of a template to their values. Entry fields
+// are empty while no timer is running.
+var fields = map[string]func(st State, now time.Time) string{
+	"state": func(st State, _ time.Time) string {
+		switch {
+		case st.Active == nil:
+			return "idle"
+		case st.Paused():
+			return "paused"
+		}
+		return "running"
+	},
+	"paused": func(st State, _ time.Time) string {
+		if st.Paused() {
+			return "paused"
+		}
+		return ""
+	},
+	"id":      entryField(func(st State) string { return st.Active.ID }),
+	"project": entryField(func(st State) string { return st.Active.Project }),
+	"task":    entryField(func(st State) string { return deref(st.Active.Task) }),
+	"comment": entryField(func(st State) string { return deref(st.Active.Comment) }),
+	"tags":    entryField(func(st State) string { return strings.Join(st.Active.Tags, ",") }),
+	"start":   entryField(func(st State) string { return st.Active.Start.Format("15:04") }),
+	"elapsed": func(st State, now time.Time) string {
+		if st.Active == nil {
+			return ""
+		}
+		return clock(st.ElapsedSeconds(now))
+	},
+	"elapsed_seconds": func(st State, now time.Time) string {
+		if st.Active == nil {
+			return ""
+		}
+		return strconv.FormatInt(st.ElapsedSeconds(now), 10)
+	},
+	"today":         func(st State, now time.Time) string { return clock(st.TodaySeconds(now)) },
+	"today_seconds": func(st State, now time.Time) string { return strconv.FormatInt(st.TodaySeconds(now), 10) },
+}
+
+func entryField(get func(st State) string) func(State, time.Time) string {
+	return func(st State, _ time.Time) string {
+		if st.Active == nil {
+			return ""
+		}
+		return get(st)
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// clock formats seconds as H:MM.
+func clock(seconds int64) string {
+	return fmt.Sprintf("%d:%02d", seconds/3600, seconds%3600/60)
+}
+
+// Fields returns the placeholder names a template may use, sorted.
+func Fields() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Template is a compiled prompt template: literal text with {field}
+// placeholders and [optional] groups, which are left out when any
+// placeholder inside them is empty. A backslash escapes the next character.
+type Template struct {
+	parts []part
+}
+
+// part is literal text, a placeholder or an optional group.
+type part struct {
+	text  string
+	field string
+	group []part
+}
+
+// Compile parses a template.
+func Compile(src string) (*Template, error) {
+	var top, group []part
+	inGroup := false
+	var lit strings.Builder
+	flush := func() {
+		if lit.Len() == 0 {
+			return
+		}
+		p := part{text: lit.String()}
+		lit.Reset()
+		if inGroup {
+			group = append(group, p)
+		} else {
+			top = append(top, p)
+		}
+	}
+
+	for i := 0; i < len(src); i++ {
+		switch c := src[i]; c {
+		case '\\':
+			if i+1 == len(src) {
+				return nil, fmt.Errorf("column %d: trailing backslash", i+1)
+			}
+			i++
+			lit.WriteByte(src[i])
+		case '{':
+			end := strings.IndexByte(src[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("column %d: unclosed {", i+1)
+			}
+			name := src[i+1 : i+end]
+			if _, ok := fields[name]; !ok {
+				return nil, fmt.Errorf("column %d: unknown field {%s}; use one of %s", i+1, name, strings.Join(Fields(), ", "))
+			}
+			flush()
+			if inGroup {
+				group = append(group, part{field: name})
+			} else {
+				top = append(top, part{field: name})
+			}
+			i += end
+		case '[':
+			if inGroup {
+				return nil, fmt.Errorf("column %d: groups cannot be nested", i+1)
+			}
+			flush()
+			inGroup, group = true, nil
+		case ']':
+			if !inGroup {
+				return nil, fmt.Errorf("column %d: ] without [", i+1)
+			}
+			flush()
+			inGroup = false
+			top = append(top, part{group: group})
+		default:
+			lit.WriteByte(c)
+		}
+	}
+	if inGroup {
+		return nil, fmt.Errorf("unclosed [")
+	}
+	flush()
+	return &Template{parts: top}, nil
+}
+
+// Render fills in the template for st at now.
+func (t *Template) Render(st State, now time.Time) string {
+	var b strings.Builder
+	for _, p := range t.parts {
+		switch {
+		case p.field != "":
+			b.WriteString(fields[p.field](st, now))
+		case p.group != nil:
+			b.WriteString(renderGroup(p.group, st, now))
+		default:
+			b.WriteString(p.text)
+		}
+	}
+	return b.String()
+}
+
+// renderGroup renders an optional group, or returns "" if any of its
+// placeholders is empty.
+func renderGroup(parts []part, st State, now time.Time) string {
+	var b strings.Builder
+	for _, p := range parts {
+		if p.field == "" {
+			b.WriteString(p.text)
+			continue
+		}
+		v := fields[p.field](st, now)
+		if v == "" {
+			return ""
+		}
+		b.WriteString(v)
+	}
+	return b.String()
+}
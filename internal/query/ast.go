@@ -0,0 +1,74 @@
+// Package query implements the entry query language used by ttt query and
+// the --where flag, e.g.
+//
+//	project = "ECM" and tags contains "backend" and duration > 30m and start >= 2026-02-01
+//
+// Queries are parsed into an Expr tree, checked against the fields of
+// model.Entry and compiled into a predicate. Errors carry the position in
+// the query they refer to.
+package query
+
+// Expr is a node of a parsed query.
+type Expr interface {
+	// Pos returns the byte offset of the node in the query source.
+	Pos() int
+}
+
+// And matches entries matching both operands.
+type And struct {
+	Left, Right Expr
+}
+
+// Or matches entries matching either operand.
+type Or struct {
+	Left, Right Expr
+}
+
+// Not matches entries not matching X.
+type Not struct {
+	X   Expr
+	pos int
+}
+
+// Compare is a predicate on a single field, e.g. duration > 30m or
+// tags contains "api". Op is one of =, !=, <, <=, >, >=, contains or
+// matches.
+type Compare struct {
+	Field Ident
+	Op    string
+	Value Value
+}
+
+// In matches entries whose field equals any of Values.
+type In struct {
+	Field  Ident
+	Values []Value
+}
+
+// IsNull matches entries whose field is unset, or set when Negate is true.
+type IsNull struct {
+	Field  Ident
+	Negate bool
+}
+
+// Ident is a field name.
+type Ident struct {
+	Name string
+	pos  int
+}
+
+// Value is a literal. Quoted is true for string literals; bare words such
+// as 30m or 2026-02-01 are interpreted according to the field they are
+// compared with.
+type Value struct {
+	Text   string
+	Quoted bool
+	pos    int
+}
+
+func (e And) Pos() int     { return e.Left.Pos() }
+func (e Or) Pos() int      { return e.Left.Pos() }
+func (e Not) Pos() int     { return e.pos }
+func (e Compare) Pos() int { return e.Field.pos }
+func (e In) Pos() int      { return e.Field.pos }
+func (e IsNull) Pos() int  { return e.Field.pos }
@@ -0,0 +1,353 @@
+package query
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timeparse"
+)
+
+// fieldType is the type of a queryable field.
+type fieldType int
+
+const (
+	typeString fieldType = iota
+	typeList
+	typeTime
+	typeDuration
+)
+
+// field describes a queryable model.Entry field. Exactly one accessor is set,
+// matching typ. Accessors return nil for unset values.
+type field struct {
+	typ fieldType
+	// jsonName is the model.Entry JSON field the query field reads.
+	jsonName string
+	str      func(e model.Entry) *string
+	list     func(e model.Entry) []string
+	time     func(e model.Entry) *time.Time
+	seconds  func(e model.Entry) *int64
+	nullable bool
+}
+
+// fields are the queryable fields by name.
+var fields = map[string]field{
+	"id":          {typ: typeString, jsonName: "id", str: func(e model.Entry) *string { return &e.ID }},
+	"external_id": {typ: typeString, jsonName: "external_id", str: func(e model.Entry) *string { return &e.ExternalID }},
+	"project":     {typ: typeString, jsonName: "project", str: func(e model.Entry) *string { return &e.Project }},
+	"task":        {typ: typeString, jsonName: "task", str: func(e model.Entry) *string { return e.Task }, nullable: true},
+	"comment":     {typ: typeString, jsonName: "comment", str: func(e model.Entry) *string { return e.Comment }, nullable: true},
+	"source":      {typ: typeString, jsonName: "source", str: func(e model.Entry) *string { return &e.Source }},
+	"tags":        {typ: typeList, jsonName: "tags", list: func(e model.Entry) []string { return e.Tags }, nullable: true},
+	"start":       {typ: typeTime, jsonName: "start", time: func(e model.Entry) *time.Time { return &e.Start }},
+	"end":         {typ: typeTime, jsonName: "end", time: func(e model.Entry) *time.Time { return e.End }, nullable: true},
+	"duration":    {typ: typeDuration, jsonName: "duration_seconds", seconds: func(e model.Entry) *int64 { return e.DurationSeconds }, nullable: true},
+}
+
+// Fields returns the names of all queryable fields in alphabetical order.
+func Fields() []string {
+	names := make([]string, 0, len(fields))
+	for n := range fields {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Query is a parsed and type-checked query.
+type Query struct {
+	match func(e model.Entry) bool
+}
+
+// Match reports whether e matches the query.
+func (q *Query) Match(e model.Entry) bool {
+	return q.match(e)
+}
+
+// Compile parses and type-checks a query. Relative times such as yesterday
+// or now-1h are resolved against now.
+//
+// String comparisons and contains ignore case; matches takes a regular
+// expression. Time fields accept dates, which cover the whole day, so
+// start = 2026-02-27 matches any entry started that day. Running entries
+// have no end and no duration and only match "is null" on those fields.
+func Compile(src string, now time.Time) (*Query, error) {
+	expr, err := Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	c := compiler{src: src, now: now}
+	match, err := c.compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &Query{match: match}, nil
+}
+
+type compiler struct {
+	src string
+	now time.Time
+}
+
+type predicate = func(e model.Entry) bool
+
+func (c compiler) compile(expr Expr) (predicate, error) {
+	switch x := expr.(type) {
+	case And:
+		l, r, err := c.compileBoth(x.Left, x.Right)
+		if err != nil {
+			return nil, err
+		}
+		return func(e model.Entry) bool { return l(e) && r(e) }, nil
+	case Or:
+		l, r, err := c.compileBoth(x.Left, x.Right)
+		if err != nil {
+			return nil, err
+		}
+		return func(e model.Entry) bool { return l(e) || r(e) }, nil
+	case Not:
+		p, err := c.compile(x.X)
+		if err != nil {
+			return nil, err
+		}
+		return func(e model.Entry) bool { return !p(e) }, nil
+	case IsNull:
+		return c.compileIsNull(x)
+	case In:
+		return c.compileIn(x)
+	case Compare:
+		return c.compileCompare(x)
+	}
+	return nil, errorAt(c.src, expr.Pos(), "unsupported expression")
+}
+
+func (c compiler) compileBoth(a, b Expr) (predicate, predicate, error) {
+	l, err := c.compile(a)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := c.compile(b)
+	return l, r, err
+}
+
+func (c compiler) lookup(id Ident) (field, error) {
+	f, ok := fields[id.Name]
+	if !ok {
+		return f, errorAt(c.src, id.pos, "unknown field %q; fields are %s", id.Name, strings.Join(Fields(), ", "))
+	}
+	return f, nil
+}
+
+func (c compiler) compileIsNull(x IsNull) (predicate, error) {
+	f, err := c.lookup(x.Field)
+	if err != nil {
+		return nil, err
+	}
+	if !f.nullable {
+		return nil, errorAt(c.src, x.Field.pos, "field %s is never null", x.Field.Name)
+	}
+	var isNull predicate
+	switch f.typ {
+	case typeString:
+		isNull = func(e model.Entry) bool { s := f.str(e); return s == nil || *s == "" }
+	case typeList:
+		isNull = func(e model.Entry) bool { return len(f.list(e)) == 0 }
+	case typeTime:
+		isNull = func(e model.Entry) bool { return f.time(e) == nil }
+	case typeDuration:
+		isNull = func(e model.Entry) bool { return f.seconds(e) == nil }
+	}
+	if x.Negate {
+		return func(e model.Entry) bool { return !isNull(e) }, nil
+	}
+	return isNull, nil
+}
+
+func (c compiler) compileIn(x In) (predicate, error) {
+	f, err := c.lookup(x.Field)
+	if err != nil {
+		return nil, err
+	}
+	var values []string
+	for _, v := range x.Values {
+		values = append(values, v.Text)
+	}
+	switch f.typ {
+	case typeString:
+		return func(e model.Entry) bool {
+			s := deref(f.str(e))
+			for _, v := range values {
+				if equalFold(x.Field.Name, s, v) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case typeList:
+		return func(e model.Entry) bool {
+			for _, v := range values {
+				if containsFold(f.list(e), v) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}
+	return nil, errorAt(c.src, x.Field.pos, "in is not supported for %s field %s", typeName(f.typ), x.Field.Name)
+}
+
+func (c compiler) compileCompare(x Compare) (predicate, error) {
+	f, err := c.lookup(x.Field)
+	if err != nil {
+		return nil, err
+	}
+	switch f.typ {
+	case typeString:
+		return c.compileString(x, f)
+	case typeList:
+		if x.Op != "contains" {
+			return nil, errorAt(c.src, x.Field.pos, "operator %s is not supported for list field %s; use contains", x.Op, x.Field.Name)
+		}
+		return func(e model.Entry) bool { return containsFold(f.list(e), x.Value.Text) }, nil
+	case typeTime:
+		return c.compileTime(x, f)
+	default:
+		return c.compileDuration(x, f)
+	}
+}
+
+func (c compiler) compileString(x Compare, f field) (predicate, error) {
+	v := x.Value.Text
+	switch x.Op {
+	case "=":
+		return func(e model.Entry) bool { return equalFold(x.Field.Name, deref(f.str(e)), v) }, nil
+	case "!=":
+		return func(e model.Entry) bool { return !equalFold(x.Field.Name, deref(f.str(e)), v) }, nil
+	case "contains":
+		lv := strings.ToLower(v)
+		return func(e model.Entry) bool { return strings.Contains(strings.ToLower(deref(f.str(e))), lv) }, nil
+	case "matches":
+		re, err := regexp.Compile(v)
+		if err != nil {
+			return nil, errorAt(c.src, x.Value.pos, "invalid regular expression: %v", err)
+		}
+		return func(e model.Entry) bool { return re.MatchString(deref(f.str(e))) }, nil
+	}
+	return nil, errorAt(c.src, x.Field.pos, "operator %s is not supported for string field %s", x.Op, x.Field.Name)
+}
+
+func (c compiler) compileTime(x Compare, f field) (predicate, error) {
+	if x.Op == "contains" || x.Op == "matches" {
+		return nil, errorAt(c.src, x.Field.pos, "operator %s is not supported for time field %s", x.Op, x.Field.Name)
+	}
+
+	// A date covers the whole day: [from, to).
+	var from, to time.Time
+	if d, err := timeparse.Day(x.Value.Text, c.now); err == nil {
+		from, to = d, d.AddDate(0, 0, 1)
+	} else if t, err := timeparse.Time(x.Value.Text, c.now); err == nil {
+		from, to = t, t
+	} else {
+		return nil, errorAt(c.src, x.Value.pos, "expected a date or time such as 2026-02-27, \"yesterday 14:00\" or now-1h, got %q", x.Value.Text)
+	}
+	instant := from.Equal(to)
+
+	var cmp func(t time.Time) bool
+	switch x.Op {
+	case "=":
+		cmp = func(t time.Time) bool { return t.Equal(from) || (!instant && t.After(from) && t.Before(to)) }
+	case "!=":
+		cmp = func(t time.Time) bool { return !(t.Equal(from) || (!instant && t.After(from) && t.Before(to))) }
+	case "<":
+		cmp = func(t time.Time) bool { return t.Before(from) }
+	case "<=":
+		if instant {
+			cmp = func(t time.Time) bool { return !t.After(from) }
+		} else {
+			cmp = func(t time.Time) bool { return t.Before(to) }
+		}
+	case ">":
+		if instant {
+			cmp = func(t time.Time) bool { return t.After(from) }
+		} else {
+			cmp = func(t time.Time) bool { return !t.Before(to) }
+		}
+	case ">=":
+		cmp = func(t time.Time) bool { return !t.Before(from) }
+	}
+	return func(e model.Entry) bool {
+		t := f.time(e)
+		return t != nil && cmp(*t)
+	}, nil
+}
+
+func (c compiler) compileDuration(x Compare, f field) (predicate, error) {
+	if x.Op == "contains" || x.Op == "matches" {
+		return nil, errorAt(c.src, x.Field.pos, "operator %s is not supported for duration field %s", x.Op, x.Field.Name)
+	}
+	d, err := timeparse.Duration(x.Value.Text)
+	if err != nil {
+		return nil, errorAt(c.src, x.Value.pos, "expected a duration such as 30m or 1h30m, got %q", x.Value.Text)
+	}
+	v := int64(d.Seconds())
+
+	var cmp func(s int64) bool
+	switch x.Op {
+	case "=":
+		cmp = func(s int64) bool { return s == v }
+	case "!=":
+		cmp = func(s int64) bool { return s != v }
+	case "<":
+		cmp = func(s int64) bool { return s < v }
+	case "<=":
+		cmp = func(s int64) bool { return s <= v }
+	case ">":
+		cmp = func(s int64) bool { return s > v }
+	case ">=":
+		cmp = func(s int64) bool { return s >= v }
+	}
+	return func(e model.Entry) bool {
+		s := f.seconds(e)
+		return s != nil && cmp(*s)
+	}, nil
+}
+
+func typeName(t fieldType) string {
+	switch t {
+	case typeString:
+		return "string"
+	case typeList:
+		return "list"
+	case typeTime:
+		return "time"
+	}
+	return "duration"
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// equalFold compares field values case-insensitively; project names also
+// ignore surrounding whitespace, like everywhere else in ttt.
+func equalFold(field, a, b string) bool {
+	if field == "project" {
+		return model.SameProject(a, b)
+	}
+	return strings.EqualFold(a, b)
+}
+
+func containsFold(list []string, v string) bool {
+	for _, s := range list {
+		if strings.EqualFold(s, v) {
+			return true
+		}
+	}
+	return false
+}
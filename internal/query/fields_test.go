@@ -0,0 +1,23 @@
+package query
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+)
+
+func TestFieldsMatchEntry(t *testing.T) {
+	tags := map[string]bool{}
+	typ := reflect.TypeOf(model.Entry{})
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		tags[name] = true
+	}
+	for name, f := range fields {
+		if !tags[f.jsonName] {
+			t.Errorf("field %s reads %q, which is not a model.Entry JSON field", name, f.jsonName)
+		}
+	}
+}
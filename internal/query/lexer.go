@@ -0,0 +1,125 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// tokenKind classifies lexical tokens.
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+// token is a lexical token. Pos is the byte offset of its first character.
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// keywords are reserved words, matched case-insensitively.
+var keywords = map[string]bool{
+	"and": true, "or": true, "not": true, "contains": true, "matches": true,
+	"in": true, "is": true, "null": true,
+}
+
+// isKeyword reports whether t is the keyword kw.
+func (t token) isKeyword(kw string) bool {
+	return t.kind == tokWord && strings.EqualFold(t.text, kw)
+}
+
+// describe returns the token as shown in error messages.
+func (t token) describe() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of query"
+	case tokString:
+		return fmt.Sprintf("string %q", t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// lex splits src into tokens. Words are maximal runs of characters that are
+// neither whitespace, quotes, parentheses, commas nor comparison operators,
+// so 2026-02-01, 1h30m and now-15m are single words.
+func lex(src string) ([]token, error) {
+	var toks []token
+	i := 0
+	for i < len(src) {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '(':
+			toks = append(toks, token{tokLParen, "(", i})
+			i++
+		case r == ')':
+			toks = append(toks, token{tokRParen, ")", i})
+			i++
+		case r == ',':
+			toks = append(toks, token{tokComma, ",", i})
+			i++
+		case r == '"' || r == '\'':
+			s, n, err := lexString(src, i)
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, token{tokString, s, i})
+			i += n
+		case r == '=':
+			toks = append(toks, token{tokOp, "=", i})
+			i++
+		case r == '!' || r == '<' || r == '>':
+			if strings.HasPrefix(src[i+1:], "=") {
+				toks = append(toks, token{tokOp, src[i : i+2], i})
+				i += 2
+			} else if r == '!' {
+				return nil, errorAt(src, i, "unexpected \"!\"; use != or not")
+			} else {
+				toks = append(toks, token{tokOp, string(r), i})
+				i++
+			}
+		default:
+			start := i
+			for i < len(src) {
+				r, size := utf8.DecodeRuneInString(src[i:])
+				if unicode.IsSpace(r) || strings.ContainsRune(`()"',=!<>`, r) {
+					break
+				}
+				i += size
+			}
+			toks = append(toks, token{tokWord, src[start:i], start})
+		}
+	}
+	return append(toks, token{tokEOF, "", len(src)}), nil
+}
+
+// lexString reads a quoted string starting at src[start]. A backslash
+// escapes the next character. It returns the unquoted value and the number
+// of bytes consumed.
+func lexString(src string, start int) (string, int, error) {
+	quote := src[start]
+	var b strings.Builder
+	for i := start + 1; i < len(src); i++ {
+		switch c := src[i]; {
+		case c == '\\' && i+1 < len(src):
+			i++
+			b.WriteByte(src[i])
+		case c == quote:
+			return b.String(), i + 1 - start, nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, errorAt(src, start, "unterminated string")
+}
@@ -0,0 +1,212 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Error is a query error at a position in the source.
+type Error struct {
+	// Query is the full query source.
+	Query string
+	// Pos is the byte offset the error refers to.
+	Pos int
+	Msg string
+}
+
+// Column returns the 1-based column of the error in characters.
+func (e *Error) Column() int {
+	return len([]rune(e.Query[:e.Pos])) + 1
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column(), e.Msg)
+}
+
+// Caret returns the query followed by a line marking the error position.
+func (e *Error) Caret() string {
+	return e.Query + "\n" + strings.Repeat(" ", e.Column()-1) + "^"
+}
+
+func errorAt(src string, pos int, format string, args ...any) *Error {
+	return &Error{Query: src, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Parse parses a query into its syntax tree without checking field names or
+// literal types; use Compile for that.
+//
+// Grammar:
+//
+//	expr      = and { "or" and }
+//	and       = unary { "and" unary }
+//	unary     = "not" unary | "(" expr ")" | predicate
+//	predicate = field op value
+//	          | field [ "not" ] "in" "(" value { "," value } ")"
+//	          | field "is" [ "not" ] "null"
+//	op        = "=" | "!=" | "<" | "<=" | ">" | ">=" | "contains" | "matches"
+func Parse(src string) (Expr, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{src: src, toks: toks}
+	if p.peek().kind == tokEOF {
+		return nil, errorAt(src, 0, "empty query")
+	}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.unexpected(t, "and, or or end of query")
+	}
+	return e, nil
+}
+
+type parser struct {
+	src  string
+	toks []token
+	i    int
+}
+
+func (p *parser) peek() token { return p.toks[p.i] }
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) unexpected(t token, want string) *Error {
+	return errorAt(p.src, t.pos, "unexpected %s, expected %s", t.describe(), want)
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = Or{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().isKeyword("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = And{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	t := p.peek()
+	switch {
+	case t.isKeyword("not"):
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not{X: x, pos: t.pos}, nil
+	case t.kind == tokLParen:
+		p.next()
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokRParen {
+			return nil, p.unexpected(t, "\")\"")
+		}
+		return e, nil
+	}
+	return p.parsePredicate()
+}
+
+func (p *parser) parsePredicate() (Expr, error) {
+	t := p.next()
+	if t.kind != tokWord || keywords[strings.ToLower(t.text)] {
+		return nil, p.unexpected(t, "a field name")
+	}
+	field := Ident{Name: strings.ToLower(t.text), pos: t.pos}
+
+	op := p.next()
+	switch {
+	case op.kind == tokOp:
+		v, err := p.parseValue()
+		return Compare{Field: field, Op: op.text, Value: v}, err
+	case op.isKeyword("contains"), op.isKeyword("matches"):
+		v, err := p.parseValue()
+		return Compare{Field: field, Op: strings.ToLower(op.text), Value: v}, err
+	case op.isKeyword("in"):
+		return p.parseIn(field)
+	case op.isKeyword("not"):
+		if t := p.next(); !t.isKeyword("in") {
+			return nil, p.unexpected(t, "in")
+		}
+		in, err := p.parseIn(field)
+		if err != nil {
+			return nil, err
+		}
+		return Not{X: in, pos: op.pos}, nil
+	case op.isKeyword("is"):
+		negate := false
+		if p.peek().isKeyword("not") {
+			p.next()
+			negate = true
+		}
+		if t := p.next(); !t.isKeyword("null") {
+			return nil, p.unexpected(t, "null")
+		}
+		return IsNull{Field: field, Negate: negate}, nil
+	}
+	return nil, p.unexpected(op, "an operator (=, !=, <, <=, >, >=, contains, matches, in, is)")
+}
+
+func (p *parser) parseIn(field Ident) (Expr, error) {
+	if t := p.next(); t.kind != tokLParen {
+		return nil, p.unexpected(t, "\"(\"")
+	}
+	var values []Value
+	for {
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+		t := p.next()
+		if t.kind == tokRParen {
+			return In{Field: field, Values: values}, nil
+		}
+		if t.kind != tokComma {
+			return nil, p.unexpected(t, "\",\" or \")\"")
+		}
+	}
+}
+
+func (p *parser) parseValue() (Value, error) {
+	t := p.next()
+	switch {
+	case t.kind == tokString:
+		return Value{Text: t.text, Quoted: true, pos: t.pos}, nil
+	case t.kind == tokWord && !keywords[strings.ToLower(t.text)]:
+		return Value{Text: t.text, pos: t.pos}, nil
+	}
+	return Value{}, p.unexpected(t, "a value")
+}
@@ -0,0 +1,95 @@
+package query_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/query"
+)
+
+var now = time.Date(2026, 2, 27, 15, 0, 0, 0, time.UTC)
+
+func entry(project, task string, start time.Time, minutes int64, tags ...string) model.Entry {
+	end := start.Add(time.Duration(minutes) * time.Minute)
+	sec := minutes * 60
+	e := model.Entry{Project: project, Tags: tags, Start: start, End: &end, DurationSeconds: &sec, Source: "manual"}
+	if task != "" {
+		e.Task = &task
+	}
+	return e
+}
+
+func TestCompileMatch(t *testing.T) {
+	feb20 := time.Date(2026, 2, 20, 9, 0, 0, 0, time.UTC)
+	feb27 := time.Date(2026, 2, 27, 9, 0, 0, 0, time.UTC)
+	rest := entry("ECM", "REST refactor", feb27, 90, "backend", "api")
+	review := entry("ecm", "Code review", feb20, 20, "backend")
+	running := model.Entry{Project: "Admin", Start: now.Add(-time.Hour), Source: "manual"}
+
+	tests := []struct {
+		query string
+		want  [3]bool // rest, review, running
+	}{
+		{`project = "ECM" and tags contains "backend" and duration > 30m and start >= 2026-02-01`, [3]bool{true, false, false}},
+		{`project = ecm`, [3]bool{true, true, false}},
+		{`project != "ECM"`, [3]bool{false, false, true}},
+		{`task contains "REVIEW" or task is null`, [3]bool{false, true, true}},
+		{`task matches "^REST"`, [3]bool{true, false, false}},
+		{`start = 2026-02-27`, [3]bool{true, false, true}},
+		{`start < 2026-02-27`, [3]bool{false, true, false}},
+		{`start <= 2026-02-20`, [3]bool{false, true, false}},
+		{`start > "yesterday 12:00"`, [3]bool{true, false, true}},
+		{`end is null`, [3]bool{false, false, true}},
+		{`not (tags contains "api")`, [3]bool{false, true, true}},
+		{`project not in ("Admin", "Meetings")`, [3]bool{true, true, false}},
+		{`tags in ("api", "frontend")`, [3]bool{true, false, false}},
+		{`duration <= 20m`, [3]bool{false, true, false}},
+		{`source = manual and (duration >= 1h or end is null)`, [3]bool{true, false, true}},
+	}
+	for _, tt := range tests {
+		q, err := query.Compile(tt.query, now)
+		if err != nil {
+			t.Errorf("Compile(%s): %v", tt.query, err)
+			continue
+		}
+		for i, e := range []model.Entry{rest, review, running} {
+			if got := q.Match(e); got != tt.want[i] {
+				t.Errorf("%s: entry %d matched = %v, want %v", tt.query, i, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		query  string
+		column int
+	}{
+		{``, 1},
+		{`projet = "ECM"`, 1},
+		{`project = "ECM" and`, 20},
+		{`project = "ECM`, 11},
+		{`project > "ECM"`, 1},
+		{`tags = "api"`, 1},
+		{`duration > soon`, 12},
+		{`start >= someday`, 10},
+		{`project is null`, 1},
+		{`task matches "("`, 14},
+		{`(project = "ECM"`, 17},
+		{`project = "ECM" duration > 1h`, 17},
+		{`project ! "ECM"`, 9},
+	}
+	for _, tt := range tests {
+		_, err := query.Compile(tt.query, now)
+		var qe *query.Error
+		if !errors.As(err, &qe) {
+			t.Errorf("Compile(%s) error = %v, want *query.Error", tt.query, err)
+			continue
+		}
+		if qe.Column() != tt.column {
+			t.Errorf("Compile(%s) error %q at column %d, want %d", tt.query, qe.Msg, qe.Column(), tt.column)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+// Package search implements full-text search over stored entries. Entries
+// are tokenized into an inverted index kept under ~/.ttt/index, which is
+// brought up to date incrementally before every search: only days whose
+// storage.Store day stamp changed since the last run are re-read.
+package search
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+)
+
+// indexVersion changes whenever the on-disk format or tokenization changes;
+// an index with another version is rebuilt from scratch.
+const indexVersion = 2
+
+// Field weights: a match in the task counts most, one in the comment least.
+const (
+	weightTask    = 3
+	weightProject = 2
+	weightTags    = 2
+	weightComment = 1
+)
+
+// Index is an inverted index over all stored entries.
+type Index struct {
+	Version int
+	// Days records the state of every indexed day by YYYY-MM-DD.
+	Days map[string]DayState
+	// Docs holds the indexed entries by ID.
+	Docs map[string]Doc
+	// Postings maps each term to the entries containing it.
+	Postings map[string][]Posting
+	// TotalLen is the sum of all document lengths, for length normalisation.
+	TotalLen int
+}
+
+// DayState is the store state a day was indexed at.
+type DayState struct {
+	Stamp  string
+	DocIDs []string
+}
+
+// Doc is an indexed entry.
+type Doc struct {
+	Entry model.Entry
+	Day   string
+	// Terms lists the distinct terms of the entry, so its postings can be
+	// removed when the day is re-indexed.
+	Terms []string
+	// Len is the weighted number of tokens in the entry.
+	Len int
+}
+
+// Posting records a term occurring in a document with a field-weighted
+// term frequency.
+type Posting struct {
+	DocID  string
+	Weight int
+}
+
+// indexFilePath returns the path of the index file.
+func indexFilePath(base string) string {
+	return filepath.Join(base, "index", "index.gob")
+}
+
+// Open loads the index under base and updates it with the current entries
+// of store. The index is rebuilt when missing, unreadable or outdated, and
+// saved when anything changed.
+func Open(base string, store storage.Store) (*Index, error) {
+	ix := load(base)
+	changed, err := ix.update(store)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		if err := ix.save(base); err != nil {
+			return nil, err
+		}
+	}
+	return ix, nil
+}
+
+// load reads the index file, falling back to an empty index. The index is
+// a cache, so a damaged file is simply rebuilt.
+func load(base string) *Index {
+	empty := &Index{
+		Version:  indexVersion,
+		Days:     map[string]DayState{},
+		Docs:     map[string]Doc{},
+		Postings: map[string][]Posting{},
+	}
+	data, err := os.ReadFile(indexFilePath(base))
+	if err != nil {
+		return empty
+	}
+	var ix Index
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&ix); err != nil || ix.Version != indexVersion {
+		return empty
+	}
+	if ix.Days == nil {
+		ix.Days = map[string]DayState{}
+	}
+	if ix.Docs == nil {
+		ix.Docs = map[string]Doc{}
+	}
+	if ix.Postings == nil {
+		ix.Postings = map[string][]Posting{}
+	}
+	return &ix
+}
+
+// save atomically writes the index file.
+func (ix *Index) save(base string) error {
+	path := indexFilePath(base)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("search index error creating directory: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(ix); err != nil {
+		return fmt.Errorf("search index error encoding: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "index-*.tmp")
+	if err != nil {
+		return fmt.Errorf("search index error writing: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("search index error writing: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("search index error writing: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("search index error renaming: %w", err)
+	}
+	return nil
+}
+
+// update re-indexes changed and new days and drops deleted ones. It reports
+// whether the index changed.
+func (ix *Index) update(store storage.Store) (bool, error) {
+	days, err := store.Days()
+	if err != nil {
+		return false, err
+	}
+
+	changed := false
+	seen := map[string]bool{}
+	for _, d := range days {
+		key := d.Format("2006-01-02")
+		seen[key] = true
+		stamp, err := store.DayStamp(d)
+		if err != nil {
+			return changed, err
+		}
+		state, ok := ix.Days[key]
+		if ok && state.Stamp == stamp {
+			continue
+		}
+
+		df, err := store.LoadDay(d)
+		if err != nil {
+			return changed, err
+		}
+		ix.removeDay(key)
+		state = DayState{Stamp: stamp}
+		for _, e := range df.Entries {
+			ix.add(e, key)
+			state.DocIDs = append(state.DocIDs, e.ID)
+		}
+		ix.Days[key] = state
+		changed = true
+	}
+
+	for key := range ix.Days {
+		if !seen[key] {
+			ix.removeDay(key)
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// add indexes an entry stored in the given day file.
+func (ix *Index) add(e model.Entry, day string) {
+	// An ID indexed under another day (e.g. after a move) is replaced.
+	ix.removeDoc(e.ID)
+
+	weights := map[string]int{}
+	length := 0
+	addText := func(text string, weight int) {
+		for _, t := range Tokenize(text) {
+			weights[t] += weight
+			length += weight
+		}
+	}
+	addText(e.Project, weightProject)
+	if e.Task != nil {
+		addText(*e.Task, weightTask)
+	}
+	if e.Comment != nil {
+		addText(*e.Comment, weightComment)
+	}
+	for _, tag := range e.Tags {
+		addText(tag, weightTags)
+	}
+
+	doc := Doc{Entry: e, Day: day, Len: length}
+	for t, w := range weights {
+		doc.Terms = append(doc.Terms, t)
+		ix.Postings[t] = append(ix.Postings[t], Posting{DocID: e.ID, Weight: w})
+	}
+	ix.Docs[e.ID] = doc
+	ix.TotalLen += length
+}
+
+// removeDay drops every document indexed for the given day.
+func (ix *Index) removeDay(day string) {
+	for _, id := range ix.Days[day].DocIDs {
+		if d, ok := ix.Docs[id]; ok && d.Day == day {
+			ix.removeDoc(id)
+		}
+	}
+	delete(ix.Days, day)
+}
+
+// removeDoc drops a document and its postings.
+func (ix *Index) removeDoc(id string) {
+	doc, ok := ix.Docs[id]
+	if !ok {
+		return
+	}
+	for _, t := range doc.Terms {
+		ps := ix.Postings[t]
+		for i, p := range ps {
+			if p.DocID == id {
+				ps = append(ps[:i], ps[i+1:]...)
+				break
+			}
+		}
+		if len(ps) == 0 {
+			delete(ix.Postings, t)
+		} else {
+			ix.Postings[t] = ps
+		}
+	}
+	ix.TotalLen -= doc.Len
+	delete(ix.Docs, id)
+}
+
+// Tokenize splits text into lower-case words of letters and digits.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !isWordRune(r) })
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
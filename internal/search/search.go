@@ -0,0 +1,234 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+)
+
+// BM25 parameters.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// prefixPenalty scales the score of a term that only matches as a prefix,
+// e.g. "map" matching "mapping", below an exact match.
+const prefixPenalty = 0.5
+
+// snippetWidth is the approximate length of a snippet in characters.
+const snippetWidth = 80
+
+// Options restricts a search.
+type Options struct {
+	// From and To limit results to entries in day files between them,
+	// inclusive. Zero values leave the range open.
+	From, To time.Time
+	// Limit caps the number of results; 0 means no limit.
+	Limit int
+}
+
+// Result is a matching entry with its score and a highlighted snippet.
+type Result struct {
+	Entry model.Entry
+	Score float64
+	// Snippet is the part of the task or comment around the first match,
+	// or the project and tags when only those matched.
+	Snippet string
+	// Highlights are the byte ranges of matched words in Snippet.
+	Highlights [][2]int
+}
+
+// Search returns entries containing every query term, best match first.
+// Terms match whole words or word prefixes, case-insensitively. Ties are
+// broken by start time, most recent first.
+func (ix *Index) Search(query string, opts Options) []Result {
+	terms := Tokenize(query)
+	if len(terms) == 0 || len(ix.Docs) == 0 {
+		return nil
+	}
+
+	var fromKey, toKey string
+	if !opts.From.IsZero() {
+		fromKey = opts.From.Format("2006-01-02")
+	}
+	if !opts.To.IsZero() {
+		toKey = opts.To.Format("2006-01-02")
+	}
+
+	avgLen := float64(ix.TotalLen) / float64(len(ix.Docs))
+	n := float64(len(ix.Docs))
+	var scores map[string]float64
+	for _, qt := range terms {
+		termScores := map[string]float64{}
+		for _, t := range ix.expand(qt) {
+			ps := ix.Postings[t]
+			idf := math.Log(1 + (n-float64(len(ps))+0.5)/(float64(len(ps))+0.5))
+			factor := 1.0
+			if t != qt {
+				factor = prefixPenalty
+			}
+			for _, p := range ps {
+				doc := ix.Docs[p.DocID]
+				if (fromKey != "" && doc.Day < fromKey) || (toKey != "" && doc.Day > toKey) {
+					continue
+				}
+				w := float64(p.Weight)
+				norm := 1 - bm25B + bm25B*float64(doc.Len)/avgLen
+				s := factor * idf * w * (bm25K1 + 1) / (w + bm25K1*norm)
+				if s > termScores[p.DocID] {
+					termScores[p.DocID] = s
+				}
+			}
+		}
+		// Every query term must match.
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for id := range scores {
+			if s, ok := termScores[id]; ok {
+				scores[id] += s
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for id, s := range scores {
+		e := ix.Docs[id].Entry
+		snippet, highlights := makeSnippet(e, terms)
+		results = append(results, Result{Entry: e, Score: s, Snippet: snippet, Highlights: highlights})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Entry.Start.After(results[j].Entry.Start)
+	})
+	if opts.Limit > 0 && len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	return results
+}
+
+// expand returns the indexed terms equal to or starting with term.
+func (ix *Index) expand(term string) []string {
+	var out []string
+	for t := range ix.Postings {
+		if strings.HasPrefix(t, term) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// makeSnippet picks the first of task and comment containing a query term
+// and cuts a window of about snippetWidth characters around the first match.
+// Newlines are flattened to spaces.
+func makeSnippet(e model.Entry, terms []string) (string, [][2]int) {
+	var texts []string
+	if e.Task != nil {
+		texts = append(texts, *e.Task)
+	}
+	if e.Comment != nil {
+		texts = append(texts, *e.Comment)
+	}
+	for _, text := range texts {
+		text = strings.Join(strings.Fields(text), " ")
+		hs := highlights(text, terms)
+		if len(hs) == 0 {
+			continue
+		}
+		start, end := window(text, hs[0][0])
+		snippet := text[start:end]
+		var shifted [][2]int
+		for _, h := range hs {
+			if h[0] >= start && h[1] <= end {
+				shifted = append(shifted, [2]int{h[0] - start, h[1] - start})
+			}
+		}
+		prefix := ""
+		if start > 0 {
+			prefix = "…"
+			for i := range shifted {
+				shifted[i][0] += len(prefix)
+				shifted[i][1] += len(prefix)
+			}
+		}
+		if end < len(text) {
+			snippet += "…"
+		}
+		return prefix + snippet, shifted
+	}
+
+	text := e.Project
+	if len(e.Tags) > 0 {
+		text += " [" + strings.Join(e.Tags, ", ") + "]"
+	}
+	return text, highlights(text, terms)
+}
+
+// highlights returns the byte ranges of words in text that start with any
+// of terms.
+func highlights(text string, terms []string) [][2]int {
+	var out [][2]int
+	inWord := false
+	wordStart := 0
+	check := func(end int) {
+		word := strings.ToLower(text[wordStart:end])
+		for _, t := range terms {
+			if strings.HasPrefix(word, t) {
+				out = append(out, [2]int{wordStart, end})
+				return
+			}
+		}
+	}
+	for i, r := range text {
+		isWord := isWordRune(r)
+		switch {
+		case isWord && !inWord:
+			wordStart, inWord = i, true
+		case !isWord && inWord:
+			check(i)
+			inWord = false
+		}
+	}
+	if inWord {
+		check(len(text))
+	}
+	return out
+}
+
+// window returns byte bounds of a snippet around pos, aligned to spaces
+// where possible.
+func window(text string, pos int) (int, int) {
+	if len(text) <= snippetWidth {
+		return 0, len(text)
+	}
+	start := pos - snippetWidth/3
+	if start <= 0 {
+		start = 0
+	} else if i := strings.IndexByte(text[start:pos], ' '); i >= 0 {
+		start += i + 1
+	}
+	for start < pos && !utf8.RuneStart(text[start]) {
+		start++
+	}
+	end := start + snippetWidth
+	if end >= len(text) {
+		return start, len(text)
+	}
+	if i := strings.LastIndexByte(text[pos:end], ' '); i > 0 {
+		end = pos + i
+	}
+	for end > pos && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	return start, end
+}
@@ -0,0 +1,108 @@
+package search_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/search"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+)
+
+func save(t *testing.T, base string, id, project, task, comment string, start time.Time, tags ...string) {
+	t.Helper()
+	e := model.Entry{ID: id, Project: project, Tags: tags, Start: start, Source: "manual"}
+	if task != "" {
+		e.Task = &task
+	}
+	if comment != "" {
+		e.Comment = &comment
+	}
+	if err := storage.NewJSONStore(base).UpdateEntry(start, e); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func ids(rs []search.Result) []string {
+	var out []string
+	for _, r := range rs {
+		out = append(out, r.Entry.ID)
+	}
+	return out
+}
+
+func TestSearchRanksAndHighlights(t *testing.T) {
+	base := t.TempDir()
+	d1 := time.Date(2025, 11, 3, 9, 0, 0, 0, time.Local)
+	d2 := time.Date(2026, 2, 27, 9, 0, 0, 0, time.Local)
+	save(t, base, "a", "ECM", "Mapping issue in REST layer", "", d1, "backend")
+	save(t, base, "b", "ECM", "Code review", "Looked at the mapping issue again", d2)
+	save(t, base, "c", "Meetings", "Standup", "", d2, "mapping")
+	save(t, base, "d", "Admin", "Timesheets", "", d2)
+
+	ix, err := search.Open(base, storage.NewJSONStore(base))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	got := ix.Search("mapping issue", search.Options{})
+	if strings.Join(ids(got), ",") != "a,b" {
+		t.Fatalf("Search(mapping issue) = %v, want a (task match) before b (comment match)", ids(got))
+	}
+	r := got[1]
+	if r.Snippet != "Looked at the mapping issue again" {
+		t.Errorf("snippet = %q", r.Snippet)
+	}
+	if len(r.Highlights) != 2 || r.Snippet[r.Highlights[0][0]:r.Highlights[0][1]] != "mapping" {
+		t.Errorf("highlights = %v, want mapping and issue", r.Highlights)
+	}
+
+	// Prefixes match, tags are searched and date scoping applies.
+	got = ix.Search("map", search.Options{From: d2, To: d2})
+	if strings.Join(ids(got), ",") != "b,c" && strings.Join(ids(got), ",") != "c,b" {
+		t.Errorf("Search(map) on %s = %v, want b and c", d2.Format("2006-01-02"), ids(got))
+	}
+	if got := ix.Search("nothing", search.Options{}); len(got) != 0 {
+		t.Errorf("Search(nothing) = %v, want none", ids(got))
+	}
+}
+
+func TestOpenUpdatesIncrementally(t *testing.T) {
+	base := t.TempDir()
+	d1 := time.Date(2026, 2, 26, 9, 0, 0, 0, time.Local)
+	d2 := time.Date(2026, 2, 27, 9, 0, 0, 0, time.Local)
+	save(t, base, "a", "ECM", "Deploy pipeline", "", d1)
+
+	if _, err := search.Open(base, storage.NewJSONStore(base)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(base, "index", "index.gob")); err != nil {
+		t.Fatalf("index file not written: %v", err)
+	}
+
+	// A new day and an edited entry are picked up on the next Open.
+	save(t, base, "b", "ECM", "Deploy hotfix", "", d2)
+	save(t, base, "a", "ECM", "Pipeline cleanup", "", d1)
+	ix, err := search.Open(base, storage.NewJSONStore(base))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(ix.Search("deploy", search.Options{})); strings.Join(got, ",") != "b" {
+		t.Errorf("Search(deploy) = %v, want b", got)
+	}
+
+	// Removed day files disappear from the index.
+	if err := os.Remove(filepath.Join(base, "2026", "02", "27.json")); err != nil {
+		t.Fatal(err)
+	}
+	ix, err = search.Open(base, storage.NewJSONStore(base))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ix.Search("hotfix", search.Options{}); len(got) != 0 {
+		t.Errorf("Search(hotfix) after removal = %v, want none", ids(got))
+	}
+}
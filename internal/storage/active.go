@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+)
+
+// activePointer is the content of ~/.ttt/active.json. It names the open entry
+// and the day storing it; an empty ID records that no timer is running.
+type activePointer struct {
+	ID  string `json:"id"`
+	Day string `json:"day,omitempty"`
+}
+
+// activeFilePath returns the path of the active-timer pointer.
+func activeFilePath(base string) string {
+	return filepath.Join(base, "active.json")
+}
+
+// readActive returns the pointer and whether a valid one exists.
+func (s *JSONStore) readActive() (activePointer, bool) {
+	var p activePointer
+	data, err := os.ReadFile(activeFilePath(s.base))
+	if err != nil {
+		return p, false
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return p, false
+	}
+	return p, true
+}
+
+// writeActive replaces the pointer. Callers hold the storage lock.
+func (s *JSONStore) writeActive(p activePointer) error {
+	if err := os.MkdirAll(s.base, 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(activeFilePath(s.base), data)
+}
+
+// pointedEntry returns the open entry p names and its day, or nil when p
+// names no entry or one that has been closed.
+func (s *JSONStore) pointedEntry(p activePointer) (*model.Entry, time.Time, error) {
+	if p.ID == "" {
+		return nil, time.Time{}, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", p.Day, time.Local)
+	if err != nil {
+		return nil, time.Time{}, nil
+	}
+	df, err := s.LoadDay(day)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	for i := range df.Entries {
+		if df.Entries[i].ID == p.ID && df.Entries[i].End == nil {
+			return &df.Entries[i], day, nil
+		}
+	}
+	return nil, time.Time{}, nil
+}
+
+// rebuildActive points the pointer at the newest open entry left in any day,
+// or records that none is running. Callers hold the storage lock.
+func (s *JSONStore) rebuildActive() error {
+	active, day, err := findActiveInAll(s)
+	if err != nil {
+		return err
+	}
+	p := activePointer{}
+	if active != nil {
+		p = activePointer{ID: active.ID, Day: dayKey(day)}
+	}
+	return s.writeActive(p)
+}
+
+// trackActive updates the pointer after entry was written under day. An
+// open entry becomes the active one unless the running timer started later,
+// as when an old entry is restored from the trash. Closing the active entry
+// moves the pointer to any other open entry. Callers hold the storage lock.
+func (s *JSONStore) trackActive(day time.Time, entry model.Entry) error {
+	p, _ := s.readActive()
+	if entry.End != nil {
+		if p.ID == entry.ID {
+			return s.rebuildActive()
+		}
+		return nil
+	}
+	if p.ID != entry.ID {
+		current, _, err := s.pointedEntry(p)
+		if err != nil {
+			return err
+		}
+		if current != nil && current.Start.After(entry.Start) {
+			return nil
+		}
+	}
+	return s.writeActive(activePointer{ID: entry.ID, Day: dayKey(day)})
+}
+
+// untrackActive moves the pointer away from the removed entry if it names
+// it. Callers hold the storage lock.
+func (s *JSONStore) untrackActive(id string) error {
+	if p, _ := s.readActive(); p.ID == id && id != "" {
+		return s.rebuildActive()
+	}
+	return nil
+}
+
+// FindActiveEntry returns the open entry named by ~/.ttt/active.json. When
+// the pointer is missing or stale, every day file is scanned instead and the
+// pointer is rebuilt from the result.
+func (s *JSONStore) FindActiveEntry() (*model.Entry, time.Time, error) {
+	if p, ok := s.readActive(); ok {
+		// An empty pointer records that no entry was open when it was
+		// written. It holds until a day file changes behind the store's
+		// back, e.g. by a hand edit or a sync from another machine.
+		if p.ID == "" {
+			changed, err := s.daysChangedSinceActive()
+			if err != nil {
+				return nil, time.Time{}, err
+			}
+			if !changed {
+				return nil, time.Time{}, nil
+			}
+		} else {
+			active, day, err := s.pointedEntry(p)
+			if err != nil || active != nil {
+				return active, day, err
+			}
+		}
+	}
+
+	// Scan under the lock so a timer started meanwhile cannot be overwritten
+	// by a stale result. The pointer is only a cache, so failing to lock or
+	// rebuild it is not an error.
+	if unlock, err := lock(s.base); err == nil {
+		defer unlock()
+		active, day, err := findActiveInAll(s)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		p := activePointer{}
+		if active != nil {
+			p = activePointer{ID: active.ID, Day: dayKey(day)}
+		}
+		_ = s.writeActive(p)
+		return active, day, nil
+	}
+	return findActiveInAll(s)
+}
+
+// daysChangedSinceActive reports whether any day file was modified after the
+// pointer was last written.
+func (s *JSONStore) daysChangedSinceActive() (bool, error) {
+	info, err := os.Stat(activeFilePath(s.base))
+	if err != nil {
+		return true, nil
+	}
+	days, err := s.Days()
+	if err != nil {
+		return false, err
+	}
+	for _, d := range days {
+		fi, err := os.Stat(dayFilePath(s.base, d))
+		if err != nil || fi.ModTime().After(info.ModTime()) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Kinds of FileProblem.
+const (
+	// FileUnparsable is a JSON file that cannot be decoded.
+	FileUnparsable = "unparsable"
+	// FileTemp is a temp file left behind by an interrupted write.
+	FileTemp = "temp"
+	// FileCorrupt is a file that was backed up after failing to decode.
+	FileCorrupt = "corrupt"
+)
+
+// FileProblem is a file in the data directory that the JSON backend cannot
+// use as it is.
+type FileProblem struct {
+	// Path is relative to the data directory.
+	Path string
+	Kind string
+	// Day is the date of an unparsable day file, and zero otherwise.
+	Day time.Time
+	Err error
+}
+
+// ScanFiles walks the data directory and reports unparsable JSON files,
+// leftover temp files and backups of corrupt files. It never modifies files,
+// unlike LoadDay, which moves corrupt day files aside.
+func (s *JSONStore) ScanFiles() ([]FileProblem, error) {
+	var problems []FileProblem
+	err := filepath.WalkDir(s.base, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == s.base {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			// Named workspaces below the default one have their own data.
+			if path == filepath.Join(s.base, "workspaces") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(s.base, path)
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		switch {
+		case strings.HasSuffix(name, ".tmp"):
+			problems = append(problems, FileProblem{Path: rel, Kind: FileTemp})
+		case strings.HasSuffix(name, ".corrupt"):
+			problems = append(problems, FileProblem{Path: rel, Kind: FileCorrupt})
+		case filepath.Ext(name) == ".json" && isDataFile(rel):
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			var v any
+			if err := json.Unmarshal(data, &v); err != nil {
+				day, _ := time.ParseInLocation("2006/01/02.json", filepath.ToSlash(rel), time.Local)
+				problems = append(problems, FileProblem{Path: rel, Kind: FileUnparsable, Day: day, Err: err})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("storage error scanning %s: %w", s.base, err)
+	}
+	return problems, nil
+}
+
+// isDataFile reports whether rel names a JSON file written by the store:
+// a day file, a trash file or one of the registry files. The user-edited
+// config.json is not checked here.
+func isDataFile(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	switch {
+	case rel == "projects.json", rel == "active.json":
+		return true
+	case strings.HasPrefix(rel, "trash/"):
+		return true
+	}
+	_, err := time.Parse("2006/01/02.json", rel)
+	return err == nil
+}
+
+// RepairFile fixes a problem found by ScanFiles: temp files are deleted and
+// unparsable files are moved aside to <name>.corrupt, as LoadDay would.
+// Backups of corrupt files are left for the user to inspect.
+func (s *JSONStore) RepairFile(p FileProblem) error {
+	unlock, err := lock(s.base)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	path := filepath.Join(s.base, p.Path)
+	switch p.Kind {
+	case FileTemp:
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("storage error removing %s: %w", p.Path, err)
+		}
+	case FileUnparsable:
+		if err := os.Rename(path, path+".corrupt"); err != nil {
+			return fmt.Errorf("storage error moving %s aside: %w", p.Path, err)
+		}
+	default:
+		return fmt.Errorf("cannot repair %s file %s", p.Kind, p.Path)
+	}
+	return nil
+}
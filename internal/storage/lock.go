@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// lockFilePath returns the path of the lock file guarding all writes below
+// base.
+func lockFilePath(base string) string {
+	return filepath.Join(base, ".lock")
+}
+
+// lock takes the exclusive advisory lock on ~/.ttt/.lock, blocking until it
+// is available, and returns a function releasing it. Every read-modify-write
+// of a storage file holds the lock, so concurrent ttt processes (or
+// goroutines) cannot lose each other's updates. The lock is not reentrant.
+func lock(base string) (func(), error) {
+	if err := os.MkdirAll(base, 0o700); err != nil {
+		return nil, fmt.Errorf("storage error creating directories: %w", err)
+	}
+	f, err := os.OpenFile(lockFilePath(base), os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("storage error opening lock file: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("storage error acquiring lock: %w", err)
+	}
+	return func() {
+		_ = unlockFile(f)
+		f.Close()
+	}, nil
+}
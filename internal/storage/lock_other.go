@@ -0,0 +1,21 @@
+//go:build !unix && !windows
+
+package storage
+
+import (
+	"os"
+	"sync"
+)
+
+// Platforms without file locking only serialise writers within one process.
+var processLock sync.Mutex
+
+func lockFile(*os.File) error {
+	processLock.Lock()
+	return nil
+}
+
+func unlockFile(*os.File) error {
+	processLock.Unlock()
+	return nil
+}
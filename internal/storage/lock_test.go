@@ -0,0 +1,114 @@
+package storage_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+)
+
+var lockTestDay = time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+// appendEntries adds n distinct entries to lockTestDay via UpdateEntry.
+func appendEntries(base, prefix string, n int) error {
+	store := storage.NewJSONStore(base)
+	for i := 0; i < n; i++ {
+		e := model.Entry{
+			ID:      fmt.Sprintf("%s-%d", prefix, i),
+			Project: "ECM",
+			Tags:    []string{},
+			Start:   lockTestDay.Add(time.Duration(i) * time.Minute),
+			Source:  "manual",
+		}
+		if err := store.UpdateEntry(lockTestDay, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func assertEntryCount(t *testing.T, base string, want int) {
+	t.Helper()
+	df, err := storage.NewJSONStore(base).LoadDay(lockTestDay)
+	if err != nil {
+		t.Fatalf("LoadDay: %v", err)
+	}
+	if len(df.Entries) != want {
+		t.Errorf("entries = %d, want %d", len(df.Entries), want)
+	}
+	tmps, _ := filepath.Glob(filepath.Join(base, "2026", "03", "*.tmp"))
+	if len(tmps) != 0 {
+		t.Errorf("leftover temp files: %v", tmps)
+	}
+}
+
+func TestUpdateEntryConcurrentGoroutines(t *testing.T) {
+	base := t.TempDir()
+	const workers, perWorker = 16, 20
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			errs <- appendEntries(base, "g"+strconv.Itoa(w), perWorker)
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("UpdateEntry: %v", err)
+		}
+	}
+	assertEntryCount(t, base, workers*perWorker)
+}
+
+// TestUpdateEntryHelperProcess is run as a child process by
+// TestUpdateEntryConcurrentProcesses; it is a no-op otherwise.
+func TestUpdateEntryHelperProcess(t *testing.T) {
+	base := os.Getenv("TTT_LOCK_TEST_BASE")
+	if base == "" {
+		t.Skip("helper process only")
+	}
+	n, _ := strconv.Atoi(os.Getenv("TTT_LOCK_TEST_N"))
+	if err := appendEntries(base, os.Getenv("TTT_LOCK_TEST_PREFIX"), n); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpdateEntryConcurrentProcesses(t *testing.T) {
+	if testing.Short() {
+		t.Skip("spawns processes")
+	}
+	base := t.TempDir()
+	const procs, perProc = 6, 25
+
+	cmds := make([]*exec.Cmd, procs)
+	for p := range cmds {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestUpdateEntryHelperProcess$")
+		cmd.Env = append(os.Environ(),
+			"TTT_LOCK_TEST_BASE="+base,
+			"TTT_LOCK_TEST_PREFIX=p"+strconv.Itoa(p),
+			"TTT_LOCK_TEST_N="+strconv.Itoa(perProc),
+		)
+		if err := cmd.Start(); err != nil {
+			t.Fatalf("start helper: %v", err)
+		}
+		cmds[p] = cmd
+	}
+	for _, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Fatalf("helper process: %v", err)
+		}
+	}
+	assertEntryCount(t, base, procs*perProc)
+}
@@ -0,0 +1,253 @@
+package storage
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+)
+
+// MemoryStore is a Store that keeps everything in memory. It is meant for
+// tests and is safe for concurrent use.
+type MemoryStore struct {
+	mu       sync.Mutex
+	days     map[string][]model.Entry
+	revs     map[string]int
+	trash    map[string]model.TrashedEntry
+	projects []model.Project
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		days:  map[string][]model.Entry{},
+		revs:  map[string]int{},
+		trash: map[string]model.TrashedEntry{},
+	}
+}
+
+// dayKey returns the map key of the day containing t.
+func dayKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// cloneEntry copies the slices of e, so callers cannot modify stored data.
+func cloneEntry(e model.Entry) model.Entry {
+	if e.Tags != nil {
+		e.Tags = append([]string{}, e.Tags...)
+	}
+	if e.Breaks != nil {
+		e.Breaks = append([]model.Break{}, e.Breaks...)
+	}
+	return e
+}
+
+// Days returns the days that have entries in ascending order.
+func (s *MemoryStore) Days() ([]time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var days []time.Time
+	for key, entries := range s.days {
+		if len(entries) == 0 {
+			continue
+		}
+		d, err := time.ParseInLocation("2006-01-02", key, time.Local)
+		if err != nil {
+			continue
+		}
+		days = append(days, d)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days, nil
+}
+
+// DayStamp returns a revision counter incremented by every write to day.
+func (s *MemoryStore) DayStamp(day time.Time) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.days[dayKey(day)]) == 0 {
+		return "", nil
+	}
+	return strconv.Itoa(s.revs[dayKey(day)]), nil
+}
+
+// LoadDay returns the entries of day, or an empty DayFile.
+func (s *MemoryStore) LoadDay(day time.Time) (model.DayFile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loadDay(day), nil
+}
+
+func (s *MemoryStore) loadDay(day time.Time) model.DayFile {
+	df := model.DayFile{Date: dayKey(day), Entries: []model.Entry{}}
+	for _, e := range s.days[dayKey(day)] {
+		df.Entries = append(df.Entries, cloneEntry(e))
+	}
+	return df
+}
+
+// LoadRange loads all entries in [from, to] inclusive.
+func (s *MemoryStore) LoadRange(from, to time.Time) ([]model.Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var entries []model.Entry
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		entries = append(entries, s.loadDay(d).Entries...)
+	}
+	return entries, nil
+}
+
+// UpdateEntry replaces or appends an entry for the given day.
+func (s *MemoryStore) UpdateEntry(day time.Time, entry model.Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updateEntry(day, entry)
+	return nil
+}
+
+func (s *MemoryStore) updateEntry(day time.Time, entry model.Entry) {
+	key := dayKey(day)
+	s.revs[key]++
+	entries := s.days[key]
+	for i, e := range entries {
+		if e.ID == entry.ID {
+			entries[i] = cloneEntry(entry)
+			return
+		}
+	}
+	s.days[key] = append(entries, cloneEntry(entry))
+}
+
+// RemoveEntry deletes the entry with the given ID from day and returns it.
+// It returns nil if no such entry exists.
+func (s *MemoryStore) RemoveEntry(day time.Time, id string) (*model.Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.removeEntry(day, id), nil
+}
+
+func (s *MemoryStore) removeEntry(day time.Time, id string) *model.Entry {
+	key := dayKey(day)
+	entries := s.days[key]
+	for i, e := range entries {
+		if e.ID == id {
+			s.revs[key]++
+			s.days[key] = append(entries[:i:i], entries[i+1:]...)
+			return &e
+		}
+	}
+	return nil
+}
+
+// FindActiveEntry searches every day, most recent first, for an entry with
+// end == nil.
+func (s *MemoryStore) FindActiveEntry() (*model.Entry, time.Time, error) {
+	return findActiveInAll(s)
+}
+
+// EachReverse calls fn for every stored entry, newest start time first.
+func (s *MemoryStore) EachReverse(fn func(e model.Entry, day time.Time) bool) error {
+	return eachReverseByDay(s, fn)
+}
+
+// TrashEntry moves the entry from day into the trash. It returns nil if no
+// such entry exists.
+func (s *MemoryStore) TrashEntry(day time.Time, id string, now time.Time) (*model.TrashedEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	removed := s.removeEntry(day, id)
+	if removed == nil {
+		return nil, nil
+	}
+	trashed := model.TrashedEntry{Entry: *removed, DeletedAt: now}
+	s.trash[id] = trashed
+	return &trashed, nil
+}
+
+// ListTrash returns all trashed entries, most recently deleted first.
+func (s *MemoryStore) ListTrash() ([]model.TrashedEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []model.TrashedEntry
+	for _, t := range s.trash {
+		t.Entry = cloneEntry(t.Entry)
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].DeletedAt.After(out[j].DeletedAt) })
+	return out, nil
+}
+
+// RestoreEntry moves a trashed entry back to the day of its start time. It
+// returns nil if the ID is not in the trash.
+func (s *MemoryStore) RestoreEntry(id string) (*model.Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.trash[id]
+	if !ok {
+		return nil, nil
+	}
+	s.updateEntry(t.Entry.Start, t.Entry)
+	delete(s.trash, id)
+	return &t.Entry, nil
+}
+
+// PurgeTrash permanently deletes entries trashed before cutoff, or all of
+// them when cutoff is zero.
+func (s *MemoryStore) PurgeTrash(cutoff time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for id, t := range s.trash {
+		if !cutoff.IsZero() && !t.DeletedAt.Before(cutoff) {
+			continue
+		}
+		delete(s.trash, id)
+		n++
+	}
+	return n, nil
+}
+
+// LoadProjects returns the registered projects.
+func (s *MemoryStore) LoadProjects() ([]model.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.projects) == 0 {
+		return nil, nil
+	}
+	return append([]model.Project(nil), s.projects...), nil
+}
+
+// SaveProjects replaces the project registry, sorted by name.
+func (s *MemoryStore) SaveProjects(projects []model.Project) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.projects = sortProjects(projects)
+	return nil
+}
+
+// RenameProject sets the project of every stored and trashed entry matching
+// from to to and returns the number of entries changed.
+func (s *MemoryStore) RenameProject(from, to string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for key, entries := range s.days {
+		for i := range entries {
+			if entries[i].Project != to && model.SameProject(entries[i].Project, from) {
+				entries[i].Project = to
+				s.revs[key]++
+				n++
+			}
+		}
+	}
+	for id, t := range s.trash {
+		if t.Entry.Project != to && model.SameProject(t.Entry.Project, from) {
+			t.Entry.Project = to
+			s.trash[id] = t
+			n++
+		}
+	}
+	return n, nil
+}
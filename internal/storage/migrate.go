@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// IsEmpty reports whether s holds no entries, trashed entries or projects.
+// Days left without entries, e.g. by Clear, do not count.
+func IsEmpty(s Store) (bool, error) {
+	days, err := s.Days()
+	if err != nil {
+		return false, err
+	}
+	for _, d := range days {
+		df, err := s.LoadDay(d)
+		if err != nil || len(df.Entries) > 0 {
+			return false, err
+		}
+	}
+	trashed, err := s.ListTrash()
+	if err != nil || len(trashed) > 0 {
+		return false, err
+	}
+	projects, err := s.LoadProjects()
+	return err == nil && len(projects) == 0, err
+}
+
+// Clear deletes every entry, trashed entry and project from s.
+func Clear(s Store) error {
+	days, err := s.Days()
+	if err != nil {
+		return err
+	}
+	for _, d := range days {
+		df, err := s.LoadDay(d)
+		if err != nil {
+			return err
+		}
+		for _, e := range df.Entries {
+			if _, err := s.RemoveEntry(d, e.ID); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := s.PurgeTrash(time.Time{}); err != nil {
+		return err
+	}
+	return s.SaveProjects(nil)
+}
+
+// DuplicateIDError reports entries stored under one day that share an ID.
+// Stores address entries by ID, so copying them would keep only one.
+type DuplicateIDError struct {
+	Day   time.Time
+	ID    string
+	Count int
+}
+
+func (e *DuplicateIDError) Error() string {
+	return fmt.Sprintf("%d entries on %s share the ID %s; run ttt doctor --fix to give them unique IDs",
+		e.Count, e.Day.Format("2006-01-02"), e.ID)
+}
+
+// CheckUniqueIDs returns a *DuplicateIDError for the first day of s that
+// stores several entries with the same ID.
+func CheckUniqueIDs(s Store) error {
+	days, err := s.Days()
+	if err != nil {
+		return err
+	}
+	for _, d := range days {
+		df, err := s.LoadDay(d)
+		if err != nil {
+			return err
+		}
+		count := map[string]int{}
+		for _, e := range df.Entries {
+			count[e.ID]++
+		}
+		for _, e := range df.Entries {
+			if count[e.ID] > 1 {
+				return &DuplicateIDError{Day: d, ID: e.ID, Count: count[e.ID]}
+			}
+		}
+	}
+	return nil
+}
+
+// Copy copies every entry, trashed entry and the project registry from src
+// to dst, keeping the day each entry is stored under and the order within
+// it. It returns the number of entries and trashed entries copied. Nothing
+// is copied when src stores duplicate IDs (see CheckUniqueIDs).
+func Copy(dst, src Store) (int, int, error) {
+	if err := CheckUniqueIDs(src); err != nil {
+		return 0, 0, err
+	}
+	days, err := src.Days()
+	if err != nil {
+		return 0, 0, err
+	}
+	entries := 0
+	for _, d := range days {
+		df, err := src.LoadDay(d)
+		if err != nil {
+			return entries, 0, err
+		}
+		for _, e := range df.Entries {
+			if err := dst.UpdateEntry(d, e); err != nil {
+				return entries, 0, err
+			}
+			entries++
+		}
+	}
+
+	// Stores only accept trash from their own entries, so each trashed
+	// entry passes through a day first.
+	trashed, err := src.ListTrash()
+	if err != nil {
+		return entries, 0, err
+	}
+	for _, t := range trashed {
+		if err := dst.UpdateEntry(t.Entry.Start, t.Entry); err != nil {
+			return entries, 0, err
+		}
+		if _, err := dst.TrashEntry(t.Entry.Start, t.Entry.ID, t.DeletedAt); err != nil {
+			return entries, 0, err
+		}
+	}
+
+	projects, err := src.LoadProjects()
+	if err != nil {
+		return entries, len(trashed), err
+	}
+	return entries, len(trashed), dst.SaveProjects(projects)
+}
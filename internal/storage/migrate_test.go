@@ -0,0 +1,130 @@
+package storage_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+)
+
+func TestCopyJSONToSQLiteAndBackIsLossless(t *testing.T) {
+	srcBase := t.TempDir()
+	src := storage.NewJSONStore(srcBase)
+
+	berlin := time.FixedZone("CET", 3600)
+	start := time.Date(2026, 2, 27, 9, 0, 0, 123456789, berlin)
+	end := start.Add(90 * time.Minute)
+	breakEnd := start.Add(20 * time.Minute)
+	dur := int64(4800)
+	task, comment := "Review", "line one\nline two"
+	entries := []model.Entry{
+		{ID: "e1", ExternalID: "AAMk", Project: "Meetings", Task: &task, Comment: &comment,
+			Tags: []string{"outlook", "b"}, Start: start, End: &end, DurationSeconds: &dur, Source: "outlook",
+			Breaks: []model.Break{{Start: start.Add(10 * time.Minute), End: &breakEnd}}},
+		{ID: "e2", Project: "ECM", Tags: []string{}, Start: start.UTC().Add(time.Hour), Source: "manual"},
+		{ID: "20260228-090000-eeee3", Project: "ECM", Tags: []string{}, Start: start.AddDate(0, 0, 1), Source: "manual"},
+	}
+	for _, e := range entries {
+		if err := src.UpdateEntry(e.Start, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := src.TrashEntry(entries[2].Start, "20260228-090000-eeee3", start.AddDate(0, 0, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.SaveProjects([]model.Project{{Name: "ECM", Client: "ACME", Billable: true}}); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := storage.OpenSQLite(filepath.Join(t.TempDir(), "ttt.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if n, trashed, err := storage.Copy(db, src); err != nil || n != 2 || trashed != 1 {
+		t.Fatalf("Copy to sqlite = %d, %d, %v; want 2, 1", n, trashed, err)
+	}
+
+	dstBase := t.TempDir()
+	if _, _, err := storage.Copy(storage.NewJSONStore(dstBase), db); err != nil {
+		t.Fatalf("Copy to json: %v", err)
+	}
+
+	for _, rel := range []string{
+		"2026/02/27.json",
+		"projects.json",
+		"trash/20260228-090000-eeee3.json",
+	} {
+		want, err := os.ReadFile(filepath.Join(srcBase, rel))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(filepath.Join(dstBase, rel))
+		if err != nil {
+			t.Fatalf("%s not written: %v", rel, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s differs after round trip:\n got: %s\nwant: %s", rel, got, want)
+		}
+	}
+}
+
+func TestClearAndIsEmpty(t *testing.T) {
+	stores := map[string]storage.Store{
+		"memory": storage.NewMemoryStore(),
+		// Clear leaves the day files of a JSON store behind, empty.
+		"json": storage.NewJSONStore(t.TempDir()),
+	}
+	for name, s := range stores {
+		if empty, err := storage.IsEmpty(s); err != nil || !empty {
+			t.Fatalf("%s: IsEmpty(new) = %v, %v; want true", name, empty, err)
+		}
+		day := time.Date(2026, 2, 27, 9, 0, 0, 0, time.Local)
+		for _, id := range []string{"20260227-090000-aaaaa", "20260227-090000-bbbbb"} {
+			if err := s.UpdateEntry(day, model.Entry{ID: id, Project: "P", Tags: []string{}, Start: day, Source: "manual"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := s.TrashEntry(day, "20260227-090000-bbbbb", day); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SaveProjects([]model.Project{{Name: "P"}}); err != nil {
+			t.Fatal(err)
+		}
+		if empty, _ := storage.IsEmpty(s); empty {
+			t.Fatalf("%s: IsEmpty = true with data", name)
+		}
+		if err := storage.Clear(s); err != nil {
+			t.Fatalf("%s: Clear: %v", name, err)
+		}
+		if empty, err := storage.IsEmpty(s); err != nil || !empty {
+			t.Errorf("%s: IsEmpty after Clear = %v, %v; want true", name, empty, err)
+		}
+	}
+}
+
+func TestCopyRefusesDuplicateIDs(t *testing.T) {
+	src := storage.NewJSONStore(t.TempDir())
+	day := time.Date(2026, 2, 27, 9, 0, 0, 0, time.Local)
+	a := model.Entry{ID: "20260227-090000-aaaaa", Project: "A", Tags: []string{}, Start: day, Source: "manual"}
+	b := a
+	b.Project = "B"
+	if err := src.SaveDay(day, model.DayFile{Date: "2026-02-27", Entries: []model.Entry{a, b}}); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := storage.NewMemoryStore()
+	_, _, err := storage.Copy(dst, src)
+	var dup *storage.DuplicateIDError
+	if !errors.As(err, &dup) || dup.ID != a.ID || dup.Count != 2 {
+		t.Fatalf("Copy = %v, want a DuplicateIDError for %s", err, a.ID)
+	}
+	if empty, err := storage.IsEmpty(dst); err != nil || !empty {
+		t.Errorf("IsEmpty(dst) = %v, %v; want nothing copied", empty, err)
+	}
+}
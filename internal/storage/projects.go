@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+)
+
+// projectsFilePath returns the path of the project registry.
+func projectsFilePath(base string) string {
+	return filepath.Join(base, "projects.json")
+}
+
+// LoadProjects returns the registered projects. A missing registry yields no
+// projects and no error.
+func (s *JSONStore) LoadProjects() ([]model.Project, error) {
+	path := projectsFilePath(s.base)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage error reading %s: %w", path, err)
+	}
+	var pf model.ProjectFile
+	if err := json.Unmarshal(data, &pf); err != nil {
+		return nil, fmt.Errorf("corrupt JSON in %s: %w", path, err)
+	}
+	return pf.Projects, nil
+}
+
+// SaveProjects atomically writes the project registry, sorted by name.
+func (s *JSONStore) SaveProjects(projects []model.Project) error {
+	unlock, err := lock(s.base)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, err := json.MarshalIndent(model.ProjectFile{Projects: sortProjects(projects)}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("storage error marshalling JSON: %w", err)
+	}
+	return writeFileAtomic(projectsFilePath(s.base), data)
+}
+
+// RenameProject sets the project of every stored entry matching from (see
+// model.SameProject) to to, in all day files and in the trash. Only files
+// that contain a matching entry are rewritten. It returns the number of
+// entries changed.
+func (s *JSONStore) RenameProject(from, to string) (int, error) {
+	unlock, err := lock(s.base)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	days, err := s.Days()
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, d := range days {
+		df, err := s.LoadDay(d)
+		if err != nil {
+			return n, err
+		}
+		changed := false
+		for i := range df.Entries {
+			if df.Entries[i].Project != to && model.SameProject(df.Entries[i].Project, from) {
+				df.Entries[i].Project = to
+				changed = true
+				n++
+			}
+		}
+		if changed {
+			if err := s.saveDay(d, df); err != nil {
+				return n, err
+			}
+		}
+	}
+
+	trashed, err := s.ListTrash()
+	if err != nil {
+		return n, err
+	}
+	for _, t := range trashed {
+		if t.Entry.Project == to || !model.SameProject(t.Entry.Project, from) {
+			continue
+		}
+		t.Entry.Project = to
+		data, err := json.MarshalIndent(t, "", "  ")
+		if err != nil {
+			return n, fmt.Errorf("storage error marshalling JSON: %w", err)
+		}
+		path, err := trashFilePath(s.base, t.Entry.ID)
+		if err != nil {
+			return n, err
+		}
+		if err := writeFileAtomic(path, data); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,84 @@
+package storage_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+)
+
+func TestProjectsRoundTrip(t *testing.T) {
+	base := t.TempDir()
+	store := storage.NewJSONStore(base)
+	projects, err := store.LoadProjects()
+	if err != nil || len(projects) != 0 {
+		t.Fatalf("LoadProjects on empty dir = %v, %v; want none", projects, err)
+	}
+
+	in := []model.Project{
+		{Name: "ecm", Client: "ACME", Billable: true},
+		{Name: "Admin", DefaultTags: []string{"internal"}},
+	}
+	if err := store.SaveProjects(in); err != nil {
+		t.Fatalf("SaveProjects: %v", err)
+	}
+	out, err := store.LoadProjects()
+	if err != nil {
+		t.Fatalf("LoadProjects: %v", err)
+	}
+	if len(out) != 2 || out[0].Name != "Admin" || out[1].Name != "ecm" || !out[1].Billable {
+		t.Errorf("LoadProjects = %+v, want Admin then ecm", out)
+	}
+}
+
+func TestRenameProject(t *testing.T) {
+	base := t.TempDir()
+	store := storage.NewJSONStore(base)
+	d1 := time.Date(2026, 2, 26, 9, 0, 0, 0, time.Local)
+	d2 := time.Date(2026, 2, 27, 9, 0, 0, 0, time.Local)
+	ids := []string{"20260226-090000-ecm01", "20260227-090000-ecm02", "20260226-090000-ecm03", "20260227-090000-other"}
+	for i, p := range []string{"ECM", "ecm", "ECM ", "Other"} {
+		day := d1
+		if i%2 == 1 {
+			day = d2
+		}
+		e := model.Entry{ID: ids[i], Project: p, Tags: []string{}, Start: day, Source: "manual"}
+		if err := store.UpdateEntry(day, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := store.TrashEntry(d1, ids[2], d2); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := store.RenameProject("ecm", "ECM")
+	if err != nil {
+		t.Fatalf("RenameProject: %v", err)
+	}
+	// "ecm" in the day file and "ECM " in the trash; "ECM" is unchanged.
+	if n != 2 {
+		t.Errorf("RenameProject changed %d entries, want 2", n)
+	}
+
+	all, err := storage.LoadAll(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range all {
+		want := "ECM"
+		if e.ID == ids[3] {
+			want = "Other"
+		}
+		if e.Project != want {
+			t.Errorf("entry %s project = %q, want %q", e.ID, e.Project, want)
+		}
+	}
+	trashed, err := store.ListTrash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trashed) != 1 || trashed[0].Entry.Project != "ECM" {
+		t.Errorf("trash = %+v, want renamed entry", trashed)
+	}
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+)
+
+// SchemaVersion is the newest day file format this binary understands. Day
+// files are always written in this format.
+const SchemaVersion = 1
+
+// dayMigrations[v] upgrades a decoded day file from schema version v to v+1.
+// Files written before schema_version existed are version 0. To change the
+// format, bump SchemaVersion and append a migration; numbers in the map are
+// json.Number, so integers survive the round trip unchanged.
+var dayMigrations = []func(day map[string]any) error{
+	// 0 → 1: schema_version was introduced; the layout is unchanged.
+	func(map[string]any) error { return nil },
+}
+
+// NewerSchemaError reports a file written by a newer ttt than this one.
+type NewerSchemaError struct {
+	Path    string
+	Version int
+	// Supported is the newest version this binary understands.
+	Supported int
+}
+
+func (e *NewerSchemaError) Error() string {
+	return fmt.Sprintf("%s uses schema version %d, but this ttt only understands up to version %d; upgrade ttt to read it",
+		e.Path, e.Version, e.Supported)
+}
+
+// schemaVersionOf returns the schema_version field of a JSON object, or 0
+// when it is missing.
+func schemaVersionOf(data []byte) (int, error) {
+	var v struct {
+		SchemaVersion int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return 0, err
+	}
+	return v.SchemaVersion, nil
+}
+
+// decodeDayFile parses a day file, upgrading it to SchemaVersion in memory.
+// It returns the version found in the file. Files from a newer ttt are
+// rejected with a *NewerSchemaError rather than read with missing fields.
+func decodeDayFile(path string, data []byte) (model.DayFile, int, error) {
+	var df model.DayFile
+	version, err := schemaVersionOf(data)
+	if err != nil {
+		return df, 0, err
+	}
+	if version > SchemaVersion {
+		return df, version, &NewerSchemaError{Path: path, Version: version, Supported: SchemaVersion}
+	}
+	if version < SchemaVersion {
+		if data, err = migrateDay(data, version); err != nil {
+			return df, version, err
+		}
+	}
+	if err := json.Unmarshal(data, &df); err != nil {
+		return df, version, err
+	}
+	return df, version, nil
+}
+
+// migrateDay applies the migrations from version up to SchemaVersion.
+func migrateDay(data []byte, version int) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var day map[string]any
+	if err := dec.Decode(&day); err != nil {
+		return nil, err
+	}
+	for v := version; v < SchemaVersion; v++ {
+		if err := dayMigrations[v](day); err != nil {
+			return nil, fmt.Errorf("migrating from schema version %d: %w", v, err)
+		}
+	}
+	day["schema_version"] = SchemaVersion
+	return json.Marshal(day)
+}
+
+// UpgradeFiles rewrites every day file older than SchemaVersion in the
+// current format and returns the days rewritten. With dryRun set, nothing is
+// written. Reading upgrades files in memory anyway; rewriting them only
+// saves the work on later reads and lets older data be edited by hand in
+// the current format.
+func (s *JSONStore) UpgradeFiles(dryRun bool) ([]time.Time, error) {
+	unlock, err := lock(s.base)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	days, err := s.Days()
+	if err != nil {
+		return nil, err
+	}
+	var upgraded []time.Time
+	for _, day := range days {
+		path := dayFilePath(s.base, day)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return upgraded, fmt.Errorf("storage error reading %s: %w", path, err)
+		}
+		df, version, err := decodeDayFile(path, data)
+		var newer *NewerSchemaError
+		if errors.As(err, &newer) {
+			return upgraded, err
+		}
+		if err != nil {
+			return upgraded, fmt.Errorf("corrupt JSON in %s: %w", path, err)
+		}
+		if version == SchemaVersion {
+			continue
+		}
+		if !dryRun {
+			if err := s.saveDay(day, df); err != nil {
+				return upgraded, err
+			}
+		}
+		upgraded = append(upgraded, day)
+	}
+	return upgraded, nil
+}
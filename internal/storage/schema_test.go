@@ -0,0 +1,110 @@
+package storage_test
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+)
+
+const unversionedDay = `{
+  "date": "2026-02-27",
+  "entries": [
+    {"id": "a", "project": "ECM", "task": null, "comment": null, "tags": [],
+     "start": "2026-02-27T09:00:00+01:00", "end": "2026-02-27T10:00:00+01:00",
+     "duration_seconds": 3600, "source": "manual"}
+  ]
+}`
+
+func TestUnversionedDayFileIsUpgraded(t *testing.T) {
+	base := t.TempDir()
+	s := storage.NewJSONStore(base)
+	day := time.Date(2026, 2, 27, 0, 0, 0, 0, time.Local)
+	path := filepath.Join(base, "2026", "02", "27.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(unversionedDay), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	df, err := s.LoadDay(day)
+	if err != nil {
+		t.Fatalf("LoadDay: %v", err)
+	}
+	if df.SchemaVersion != storage.SchemaVersion || len(df.Entries) != 1 || *df.Entries[0].DurationSeconds != 3600 {
+		t.Errorf("LoadDay = %+v, want one entry at schema version %d", df, storage.SchemaVersion)
+	}
+
+	upgraded, err := s.UpgradeFiles(true)
+	if err != nil || len(upgraded) != 1 {
+		t.Fatalf("UpgradeFiles(dry run) = %v, %v; want one day", upgraded, err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != unversionedDay {
+		t.Error("dry run rewrote the file")
+	}
+
+	if _, err := s.UpgradeFiles(false); err != nil {
+		t.Fatalf("UpgradeFiles: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `"schema_version": 1`) {
+		t.Errorf("upgraded file lacks schema_version:\n%s", data)
+	}
+	if upgraded, err := s.UpgradeFiles(false); err != nil || len(upgraded) != 0 {
+		t.Errorf("second UpgradeFiles = %v, %v; want nothing to do", upgraded, err)
+	}
+}
+
+func TestNewerDayFileIsRefused(t *testing.T) {
+	base := t.TempDir()
+	s := storage.NewJSONStore(base)
+	day := time.Date(2026, 2, 27, 0, 0, 0, 0, time.Local)
+	path := filepath.Join(base, "2026", "02", "27.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	newer := `{"schema_version": 99, "date": "2026-02-27", "entries": []}`
+	if err := os.WriteFile(path, []byte(newer), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var schemaErr *storage.NewerSchemaError
+	if _, err := s.LoadDay(day); !errors.As(err, &schemaErr) || schemaErr.Version != 99 {
+		t.Fatalf("LoadDay error = %v, want NewerSchemaError for version 99", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("newer file was moved aside: %v", err)
+	}
+	if _, err := s.UpgradeFiles(false); !errors.As(err, &schemaErr) {
+		t.Errorf("UpgradeFiles error = %v, want NewerSchemaError", err)
+	}
+}
+
+func TestNewerSQLiteDatabaseIsRefused(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ttt.db")
+	s, err := storage.OpenSQLite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("PRAGMA user_version = 99"); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	var schemaErr *storage.NewerSchemaError
+	if _, err := storage.OpenSQLite(path); !errors.As(err, &schemaErr) || schemaErr.Version != 99 {
+		t.Errorf("OpenSQLite error = %v, want NewerSchemaError for version 99", err)
+	}
+}
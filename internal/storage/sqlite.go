@@ -0,0 +1,598 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+
+	// Pure-Go SQLite driver, so the binary stays static and cgo-free.
+	_ "modernc.org/sqlite"
+)
+
+// sqliteSchema creates the tables of an SQLiteStore. Entries keep their
+// insertion order within a day through seq; revs counts the writes to each
+// day for DayStamp.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS entries (
+	seq              INTEGER PRIMARY KEY AUTOINCREMENT,
+	day              TEXT    NOT NULL,
+	id               TEXT    NOT NULL,
+	external_id      TEXT    NOT NULL DEFAULT '',
+	project          TEXT    NOT NULL,
+	task             TEXT,
+	comment          TEXT,
+	start            TEXT    NOT NULL,
+	start_ns         INTEGER NOT NULL,
+	"end"            TEXT,
+	duration_seconds INTEGER,
+	source           TEXT    NOT NULL,
+	breaks           TEXT,
+	UNIQUE (day, id)
+);
+CREATE INDEX IF NOT EXISTS entries_start ON entries (start_ns);
+CREATE INDEX IF NOT EXISTS entries_project ON entries (project);
+CREATE INDEX IF NOT EXISTS entries_open ON entries (day) WHERE "end" IS NULL;
+
+CREATE TABLE IF NOT EXISTS entry_tags (
+	entry_seq INTEGER NOT NULL REFERENCES entries (seq) ON DELETE CASCADE,
+	position  INTEGER NOT NULL,
+	tag       TEXT    NOT NULL,
+	PRIMARY KEY (entry_seq, position)
+);
+CREATE INDEX IF NOT EXISTS entry_tags_tag ON entry_tags (tag);
+
+CREATE TABLE IF NOT EXISTS revs (
+	day TEXT PRIMARY KEY,
+	rev INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS trash (
+	id         TEXT PRIMARY KEY,
+	deleted_at TEXT NOT NULL,
+	entry      TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS projects (
+	name TEXT PRIMARY KEY,
+	data TEXT NOT NULL
+);
+`
+
+// sqliteSchemaVersion is stored in PRAGMA user_version. Databases created by
+// a newer ttt are refused, like day files with a newer schema_version.
+const sqliteSchemaVersion = 1
+
+// entryColumns selects an entry row in the order scanEntry expects; the
+// tags are aggregated into a JSON array.
+const entryColumns = `e.id, e.external_id, e.project, e.task, e.comment, e.start, e."end",
+	e.duration_seconds, e.source, e.breaks,
+	(SELECT json_group_array(tag) FROM (SELECT tag FROM entry_tags WHERE entry_seq = e.seq ORDER BY position))`
+
+// SQLiteStore is a Store backed by a single SQLite database, which answers
+// range queries without reading one file per day.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// sqliteFilePath returns the path of the SQLite database under base.
+func sqliteFilePath(base string) string {
+	return filepath.Join(base, "ttt.db")
+}
+
+// OpenSQLite opens or creates the SQLite database at path.
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("storage error creating directories: %w", err)
+	}
+	dsn := "file:" + path + "?_pragma=busy_timeout(10000)&_pragma=foreign_keys(1)&_txlock=immediate"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("storage error opening %s: %w", path, err)
+	}
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("storage error opening %s: %w", path, err)
+	}
+	if version > sqliteSchemaVersion {
+		db.Close()
+		return nil, &NewerSchemaError{Path: path, Version: version, Supported: sqliteSchemaVersion}
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("storage error initialising %s: %w", path, err)
+	}
+	if version < sqliteSchemaVersion {
+		if _, err := db.Exec(fmt.Sprintf("PRAGMA user_version = %d", sqliteSchemaVersion)); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("storage error initialising %s: %w", path, err)
+		}
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// Close closes the database.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanEntry reads an entry selected with entryColumns.
+func scanEntry(r rowScanner) (model.Entry, error) {
+	var (
+		e                  model.Entry
+		task, comment, end sql.NullString
+		breaks             sql.NullString
+		dur                sql.NullInt64
+		start, tags        string
+	)
+	if err := r.Scan(&e.ID, &e.ExternalID, &e.Project, &task, &comment, &start, &end, &dur, &e.Source, &breaks, &tags); err != nil {
+		return e, fmt.Errorf("storage error reading entry: %w", err)
+	}
+	if task.Valid {
+		e.Task = &task.String
+	}
+	if comment.Valid {
+		e.Comment = &comment.String
+	}
+	t, err := time.Parse(time.RFC3339Nano, start)
+	if err != nil {
+		return e, fmt.Errorf("corrupt start of entry %s: %w", e.ID, err)
+	}
+	e.Start = t
+	if end.Valid {
+		t, err := time.Parse(time.RFC3339Nano, end.String)
+		if err != nil {
+			return e, fmt.Errorf("corrupt end of entry %s: %w", e.ID, err)
+		}
+		e.End = &t
+	}
+	if dur.Valid {
+		e.DurationSeconds = &dur.Int64
+	}
+	if breaks.Valid {
+		if err := json.Unmarshal([]byte(breaks.String), &e.Breaks); err != nil {
+			return e, fmt.Errorf("corrupt breaks of entry %s: %w", e.ID, err)
+		}
+	}
+	if err := json.Unmarshal([]byte(tags), &e.Tags); err != nil {
+		return e, fmt.Errorf("corrupt tags of entry %s: %w", e.ID, err)
+	}
+	return e, nil
+}
+
+// queryEntries runs a query selecting entryColumns.
+func (s *SQLiteStore) queryEntries(query string, args ...any) ([]model.Entry, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("storage error querying entries: %w", err)
+	}
+	defer rows.Close()
+	var entries []model.Entry
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("storage error querying entries: %w", err)
+	}
+	return entries, nil
+}
+
+// Days returns the days that have entries in ascending order.
+func (s *SQLiteStore) Days() ([]time.Time, error) {
+	rows, err := s.db.Query(`SELECT DISTINCT day FROM entries ORDER BY day`)
+	if err != nil {
+		return nil, fmt.Errorf("storage error listing days: %w", err)
+	}
+	defer rows.Close()
+	var days []time.Time
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("storage error listing days: %w", err)
+		}
+		d, err := time.ParseInLocation("2006-01-02", key, time.Local)
+		if err != nil {
+			continue
+		}
+		days = append(days, d)
+	}
+	return days, rows.Err()
+}
+
+// DayStamp returns the write counter of day, or an empty stamp when it has
+// no entries.
+func (s *SQLiteStore) DayStamp(day time.Time) (string, error) {
+	var rev int64
+	err := s.db.QueryRow(`SELECT r.rev FROM revs r WHERE r.day = ?1 AND EXISTS (SELECT 1 FROM entries WHERE day = ?1)`, dayKey(day)).Scan(&rev)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("storage error reading %s: %w", dayKey(day), err)
+	}
+	return strconv.FormatInt(rev, 10), nil
+}
+
+// LoadDay returns the entries of day in insertion order, or an empty DayFile.
+func (s *SQLiteStore) LoadDay(day time.Time) (model.DayFile, error) {
+	entries, err := s.queryEntries(`SELECT `+entryColumns+` FROM entries e WHERE e.day = ? ORDER BY e.seq`, dayKey(day))
+	if err != nil {
+		return model.DayFile{}, err
+	}
+	if entries == nil {
+		entries = []model.Entry{}
+	}
+	return model.DayFile{Date: dayKey(day), Entries: entries}, nil
+}
+
+// LoadRange loads all entries stored for the days in [from, to] with a
+// single query.
+func (s *SQLiteStore) LoadRange(from, to time.Time) ([]model.Entry, error) {
+	return s.queryEntries(`SELECT `+entryColumns+` FROM entries e WHERE e.day BETWEEN ? AND ? ORDER BY e.day, e.seq`,
+		dayKey(from), dayKey(to))
+}
+
+// UpdateEntry replaces or appends an entry for the given day.
+func (s *SQLiteStore) UpdateEntry(day time.Time, entry model.Entry) error {
+	return s.inTx(func(tx *sql.Tx) error { return upsertEntry(tx, dayKey(day), entry) })
+}
+
+// inTx runs fn in a write transaction.
+func (s *SQLiteStore) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("storage error starting transaction: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("storage error committing: %w", err)
+	}
+	return nil
+}
+
+// bumpRev records a write to day.
+func bumpRev(tx *sql.Tx, day string) error {
+	if _, err := tx.Exec(`INSERT INTO revs (day, rev) VALUES (?, 1) ON CONFLICT (day) DO UPDATE SET rev = rev + 1`, day); err != nil {
+		return fmt.Errorf("storage error writing %s: %w", day, err)
+	}
+	return nil
+}
+
+// upsertEntry writes entry under day, keeping the position of an existing
+// entry with the same ID.
+func upsertEntry(tx *sql.Tx, day string, e model.Entry) error {
+	var end sql.NullString
+	if e.End != nil {
+		end = sql.NullString{String: e.End.Format(time.RFC3339Nano), Valid: true}
+	}
+	var breaks sql.NullString
+	if e.Breaks != nil {
+		data, err := json.Marshal(e.Breaks)
+		if err != nil {
+			return fmt.Errorf("storage error marshalling JSON: %w", err)
+		}
+		breaks = sql.NullString{String: string(data), Valid: true}
+	}
+	var seq int64
+	err := tx.QueryRow(`
+INSERT INTO entries (day, id, external_id, project, task, comment, start, start_ns, "end", duration_seconds, source, breaks)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (day, id) DO UPDATE SET
+	external_id = excluded.external_id, project = excluded.project, task = excluded.task,
+	comment = excluded.comment, start = excluded.start, start_ns = excluded.start_ns,
+	"end" = excluded."end", duration_seconds = excluded.duration_seconds,
+	source = excluded.source, breaks = excluded.breaks
+RETURNING seq`,
+		day, e.ID, e.ExternalID, e.Project, e.Task, e.Comment, e.Start.Format(time.RFC3339Nano), e.Start.UnixNano(),
+		end, e.DurationSeconds, e.Source, breaks).Scan(&seq)
+	if err != nil {
+		return fmt.Errorf("storage error writing entry %s: %w", e.ID, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM entry_tags WHERE entry_seq = ?`, seq); err != nil {
+		return fmt.Errorf("storage error writing tags of %s: %w", e.ID, err)
+	}
+	for i, tag := range e.Tags {
+		if _, err := tx.Exec(`INSERT INTO entry_tags (entry_seq, position, tag) VALUES (?, ?, ?)`, seq, i, tag); err != nil {
+			return fmt.Errorf("storage error writing tags of %s: %w", e.ID, err)
+		}
+	}
+	return bumpRev(tx, day)
+}
+
+// RemoveEntry deletes the entry with the given ID from day and returns it.
+// It returns nil if no such entry exists.
+func (s *SQLiteStore) RemoveEntry(day time.Time, id string) (*model.Entry, error) {
+	var removed *model.Entry
+	err := s.inTx(func(tx *sql.Tx) error {
+		var err error
+		removed, err = removeEntryTx(tx, dayKey(day), id)
+		return err
+	})
+	return removed, err
+}
+
+// removeEntryTx deletes and returns the entry with the given ID stored
+// under day, or nil.
+func removeEntryTx(tx *sql.Tx, day, id string) (*model.Entry, error) {
+	e, err := scanEntry(tx.QueryRow(`SELECT `+entryColumns+` FROM entries e WHERE e.day = ? AND e.id = ?`, day, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if _, err := tx.Exec(`DELETE FROM entries WHERE day = ? AND id = ?`, day, id); err != nil {
+		return nil, fmt.Errorf("storage error deleting entry %s: %w", id, err)
+	}
+	return &e, bumpRev(tx, day)
+}
+
+// FindActiveEntry returns the newest entry with end == nil in the whole
+// history, using a partial index over open entries.
+func (s *SQLiteStore) FindActiveEntry() (*model.Entry, time.Time, error) {
+	var key string
+	err := s.db.QueryRow(`SELECT day FROM entries WHERE "end" IS NULL ORDER BY day DESC LIMIT 1`).Scan(&key)
+	if err == sql.ErrNoRows {
+		return nil, time.Time{}, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("storage error finding active entry: %w", err)
+	}
+	day, err := time.ParseInLocation("2006-01-02", key, time.Local)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("corrupt day %q: %w", key, err)
+	}
+	e, err := scanEntry(s.db.QueryRow(`SELECT `+entryColumns+` FROM entries e WHERE e.day = ? AND e."end" IS NULL ORDER BY e.seq DESC LIMIT 1`, key))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return &e, day, nil
+}
+
+// EachReverse calls fn for every stored entry, newest start time first.
+func (s *SQLiteStore) EachReverse(fn func(e model.Entry, day time.Time) bool) error {
+	return eachReverseByDay(s, fn)
+}
+
+// TrashEntry moves the entry from day into the trash. It returns nil if no
+// such entry exists.
+func (s *SQLiteStore) TrashEntry(day time.Time, id string, now time.Time) (*model.TrashedEntry, error) {
+	var trashed *model.TrashedEntry
+	err := s.inTx(func(tx *sql.Tx) error {
+		removed, err := removeEntryTx(tx, dayKey(day), id)
+		if err != nil || removed == nil {
+			return err
+		}
+		trashed = &model.TrashedEntry{Entry: *removed, DeletedAt: now}
+		return putTrash(tx, *trashed)
+	})
+	return trashed, err
+}
+
+// putTrash writes a trashed entry, replacing one with the same ID.
+func putTrash(tx *sql.Tx, t model.TrashedEntry) error {
+	data, err := json.Marshal(t.Entry)
+	if err != nil {
+		return fmt.Errorf("storage error marshalling JSON: %w", err)
+	}
+	if _, err := tx.Exec(`INSERT OR REPLACE INTO trash (id, deleted_at, entry) VALUES (?, ?, ?)`,
+		t.Entry.ID, t.DeletedAt.Format(time.RFC3339Nano), string(data)); err != nil {
+		return fmt.Errorf("storage error writing trash: %w", err)
+	}
+	return nil
+}
+
+// ListTrash returns all trashed entries, most recently deleted first.
+func (s *SQLiteStore) ListTrash() ([]model.TrashedEntry, error) {
+	return listTrash(s.db)
+}
+
+// querier is implemented by *sql.DB and *sql.Tx.
+type querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+func listTrash(q querier) ([]model.TrashedEntry, error) {
+	rows, err := q.Query(`SELECT deleted_at, entry FROM trash`)
+	if err != nil {
+		return nil, fmt.Errorf("storage error reading trash: %w", err)
+	}
+	defer rows.Close()
+	var out []model.TrashedEntry
+	for rows.Next() {
+		var deletedAt, data string
+		if err := rows.Scan(&deletedAt, &data); err != nil {
+			return nil, fmt.Errorf("storage error reading trash: %w", err)
+		}
+		var t model.TrashedEntry
+		if err := json.Unmarshal([]byte(data), &t.Entry); err != nil {
+			return nil, fmt.Errorf("corrupt trash entry: %w", err)
+		}
+		if t.DeletedAt, err = time.Parse(time.RFC3339Nano, deletedAt); err != nil {
+			return nil, fmt.Errorf("corrupt trash entry %s: %w", t.Entry.ID, err)
+		}
+		out = append(out, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("storage error reading trash: %w", err)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].DeletedAt.After(out[j].DeletedAt) })
+	return out, nil
+}
+
+// RestoreEntry moves a trashed entry back to the day of its start time. It
+// returns nil if the ID is not in the trash.
+func (s *SQLiteStore) RestoreEntry(id string) (*model.Entry, error) {
+	var restored *model.Entry
+	err := s.inTx(func(tx *sql.Tx) error {
+		var data string
+		err := tx.QueryRow(`SELECT entry FROM trash WHERE id = ?`, id).Scan(&data)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("storage error reading trash: %w", err)
+		}
+		var e model.Entry
+		if err := json.Unmarshal([]byte(data), &e); err != nil {
+			return fmt.Errorf("corrupt trash entry %s: %w", id, err)
+		}
+		if err := upsertEntry(tx, dayKey(e.Start), e); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM trash WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("storage error removing trash entry: %w", err)
+		}
+		restored = &e
+		return nil
+	})
+	return restored, err
+}
+
+// PurgeTrash permanently deletes entries trashed before cutoff, or all of
+// them when cutoff is zero.
+func (s *SQLiteStore) PurgeTrash(cutoff time.Time) (int, error) {
+	n := 0
+	err := s.inTx(func(tx *sql.Tx) error {
+		trashed, err := listTrash(tx)
+		if err != nil {
+			return err
+		}
+		for _, t := range trashed {
+			if !cutoff.IsZero() && !t.DeletedAt.Before(cutoff) {
+				continue
+			}
+			if _, err := tx.Exec(`DELETE FROM trash WHERE id = ?`, t.Entry.ID); err != nil {
+				return fmt.Errorf("storage error purging %s: %w", t.Entry.ID, err)
+			}
+			n++
+		}
+		return nil
+	})
+	return n, err
+}
+
+// LoadProjects returns the registered projects, sorted by name.
+func (s *SQLiteStore) LoadProjects() ([]model.Project, error) {
+	rows, err := s.db.Query(`SELECT data FROM projects`)
+	if err != nil {
+		return nil, fmt.Errorf("storage error reading projects: %w", err)
+	}
+	defer rows.Close()
+	var projects []model.Project
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("storage error reading projects: %w", err)
+		}
+		var p model.Project
+		if err := json.Unmarshal([]byte(data), &p); err != nil {
+			return nil, fmt.Errorf("corrupt project: %w", err)
+		}
+		projects = append(projects, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("storage error reading projects: %w", err)
+	}
+	if projects == nil {
+		return nil, nil
+	}
+	return sortProjects(projects), nil
+}
+
+// SaveProjects replaces the project registry.
+func (s *SQLiteStore) SaveProjects(projects []model.Project) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM projects`); err != nil {
+			return fmt.Errorf("storage error writing projects: %w", err)
+		}
+		for _, p := range projects {
+			data, err := json.Marshal(p)
+			if err != nil {
+				return fmt.Errorf("storage error marshalling JSON: %w", err)
+			}
+			if _, err := tx.Exec(`INSERT OR REPLACE INTO projects (name, data) VALUES (?, ?)`, p.Name, string(data)); err != nil {
+				return fmt.Errorf("storage error writing projects: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// RenameProject sets the project of every stored and trashed entry matching
+// from to to and returns the number of entries changed.
+func (s *SQLiteStore) RenameProject(from, to string) (int, error) {
+	n := 0
+	err := s.inTx(func(tx *sql.Tx) error {
+		type match struct {
+			seq int64
+			day string
+		}
+		rows, err := tx.Query(`SELECT seq, day, project FROM entries WHERE project <> ?`, to)
+		if err != nil {
+			return fmt.Errorf("storage error reading entries: %w", err)
+		}
+		var matches []match
+		for rows.Next() {
+			var m match
+			var project string
+			if err := rows.Scan(&m.seq, &m.day, &project); err != nil {
+				rows.Close()
+				return fmt.Errorf("storage error reading entries: %w", err)
+			}
+			if model.SameProject(project, from) {
+				matches = append(matches, m)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("storage error reading entries: %w", err)
+		}
+		for _, m := range matches {
+			if _, err := tx.Exec(`UPDATE entries SET project = ? WHERE seq = ?`, to, m.seq); err != nil {
+				return fmt.Errorf("storage error renaming project: %w", err)
+			}
+			if err := bumpRev(tx, m.day); err != nil {
+				return err
+			}
+			n++
+		}
+
+		trashed, err := listTrash(tx)
+		if err != nil {
+			return err
+		}
+		for _, t := range trashed {
+			if t.Entry.Project == to || !model.SameProject(t.Entry.Project, from) {
+				continue
+			}
+			t.Entry.Project = to
+			if err := putTrash(tx, t); err != nil {
+				return err
+			}
+			n++
+		}
+		return nil
+	})
+	return n, err
+}
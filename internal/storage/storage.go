@@ -2,21 +2,26 @@ package storage
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"time"
 
 	"github.com/Tiliavir/trivial-time-tracker/internal/model"
 )
 
-// BaseDir returns the root data directory (~/.ttt).
-func BaseDir() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("cannot determine home directory: %w", err)
-	}
-	return filepath.Join(home, ".ttt"), nil
+// JSONStore is the default Store. It keeps one human-readable JSON file per
+// day under base (YYYY/MM/DD.json), plus the trash and project registry.
+type JSONStore struct {
+	base string
+}
+
+// NewJSONStore returns a JSONStore with its data under base.
+func NewJSONStore(base string) *JSONStore {
+	return &JSONStore{base: base}
 }
 
 // dayFilePath returns the path for the given date's JSON file.
@@ -24,19 +29,36 @@ func dayFilePath(base string, t time.Time) string {
 	return filepath.Join(base, t.Format("2006"), t.Format("01"), t.Format("02")+".json")
 }
 
+// DayStamp returns the size and modification time of the day's file, or an
+// empty stamp when it does not exist.
+func (s *JSONStore) DayStamp(t time.Time) (string, error) {
+	info, err := os.Stat(dayFilePath(s.base, t))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("storage error reading %s: %w", t.Format("2006-01-02"), err)
+	}
+	return fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size()), nil
+}
+
 // LoadDay loads the DayFile for the given date. Returns an empty DayFile if not found.
-func LoadDay(base string, t time.Time) (model.DayFile, error) {
-	path := dayFilePath(base, t)
+func (s *JSONStore) LoadDay(t time.Time) (model.DayFile, error) {
+	path := dayFilePath(s.base, t)
 	data, err := os.ReadFile(path)
 	if os.IsNotExist(err) {
-		return model.DayFile{Date: t.Format("2006-01-02"), Entries: []model.Entry{}}, nil
+		return model.DayFile{SchemaVersion: SchemaVersion, Date: t.Format("2006-01-02"), Entries: []model.Entry{}}, nil
 	}
 	if err != nil {
 		return model.DayFile{}, fmt.Errorf("storage error reading %s: %w", path, err)
 	}
 
-	var df model.DayFile
-	if err := json.Unmarshal(data, &df); err != nil {
+	df, _, err := decodeDayFile(path, data)
+	var newer *NewerSchemaError
+	if errors.As(err, &newer) {
+		return model.DayFile{}, err
+	}
+	if err != nil {
 		// Back up corrupt file and abort.
 		backupPath := path + ".corrupt"
 		_ = os.Rename(path, backupPath)
@@ -46,70 +68,147 @@ func LoadDay(base string, t time.Time) (model.DayFile, error) {
 }
 
 // SaveDay atomically writes a DayFile for the given date.
-func SaveDay(base string, t time.Time, df model.DayFile) error {
-	path := dayFilePath(base, t)
+func (s *JSONStore) SaveDay(t time.Time, df model.DayFile) error {
+	unlock, err := lock(s.base)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return s.saveDay(t, df)
+}
+
+// saveDay is SaveDay for callers already holding the storage lock.
+func (s *JSONStore) saveDay(t time.Time, df model.DayFile) error {
+	path := dayFilePath(s.base, t)
 	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
 		return fmt.Errorf("storage error creating directories: %w", err)
 	}
 
+	df.SchemaVersion = SchemaVersion
 	data, err := json.MarshalIndent(df, "", "  ")
 	if err != nil {
 		return fmt.Errorf("storage error marshalling JSON: %w", err)
 	}
 
-	// Atomic write: write to temp file then rename.
-	tmpPath := path + ".tmp"
-	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic writes data to a uniquely named temp file next to path,
+// syncs it and renames it into place, so readers never observe a partially
+// written file and concurrent writers never share a temp file. The directory
+// is synced afterwards so the rename survives a crash.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("storage error creating temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
 		return fmt.Errorf("storage error writing temp file: %w", err)
 	}
-	if err := os.Rename(tmpPath, path); err != nil {
-		_ = os.Remove(tmpPath)
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("storage error syncing temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("storage error writing temp file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
 		return fmt.Errorf("storage error renaming temp file: %w", err)
 	}
-	return nil
+	return syncDir(dir)
 }
 
-// FindActiveEntry searches all day files (most recent first) for an entry with end == nil.
-// It returns the entry, the date it was found on, and an error if any.
-func FindActiveEntry(base string) (*model.Entry, time.Time, error) {
-	// Check today and the past few days to handle crash-recovery across midnight.
-	now := time.Now()
-	for i := 0; i < 7; i++ {
-		day := now.AddDate(0, 0, -i)
-		df, err := LoadDay(base, day)
-		if err != nil {
-			return nil, time.Time{}, err
-		}
-		for j := len(df.Entries) - 1; j >= 0; j-- {
-			if df.Entries[j].End == nil {
-				return &df.Entries[j], day, nil
-		}
-		}
+// syncDir flushes a directory entry change to disk. Windows cannot sync
+// directories, so it is skipped there.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
 	}
-	return nil, time.Time{}, nil
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("storage error opening %s: %w", dir, err)
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("storage error syncing %s: %w", dir, err)
+	}
+	return nil
 }
 
 // UpdateEntry replaces or appends an entry in the DayFile for the given date.
-func UpdateEntry(base string, day time.Time, entry model.Entry) error {
-	df, err := LoadDay(base, day)
+// The load and save happen under the storage lock, so concurrent updates are
+// never lost.
+func (s *JSONStore) UpdateEntry(day time.Time, entry model.Entry) error {
+	unlock, err := lock(s.base)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return s.updateEntry(day, entry)
+}
+
+// updateEntry is UpdateEntry for callers already holding the storage lock.
+func (s *JSONStore) updateEntry(day time.Time, entry model.Entry) error {
+	df, err := s.LoadDay(day)
 	if err != nil {
 		return err
 	}
 	for i, e := range df.Entries {
 		if e.ID == entry.ID {
 			df.Entries[i] = entry
-			return SaveDay(base, day, df)
+			return s.saveAndTrack(day, df, entry)
 		}
 	}
 	df.Entries = append(df.Entries, entry)
-	return SaveDay(base, day, df)
+	return s.saveAndTrack(day, df, entry)
+}
+
+// saveAndTrack saves df and updates the active-timer pointer for entry.
+func (s *JSONStore) saveAndTrack(day time.Time, df model.DayFile, entry model.Entry) error {
+	if err := s.saveDay(day, df); err != nil {
+		return err
+	}
+	return s.trackActive(day, entry)
+}
+
+// RemoveEntry deletes the entry with the given ID from the DayFile for the
+// given date and returns it. It returns nil if no such entry exists.
+func (s *JSONStore) RemoveEntry(day time.Time, id string) (*model.Entry, error) {
+	unlock, err := lock(s.base)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return s.removeEntry(day, id)
+}
+
+// removeEntry is RemoveEntry for callers already holding the storage lock.
+func (s *JSONStore) removeEntry(day time.Time, id string) (*model.Entry, error) {
+	df, err := s.LoadDay(day)
+	if err != nil {
+		return nil, err
+	}
+	for i, e := range df.Entries {
+		if e.ID == id {
+			removed := e
+			df.Entries = append(df.Entries[:i], df.Entries[i+1:]...)
+			if err := s.saveDay(day, df); err != nil {
+				return nil, err
+			}
+			return &removed, s.untrackActive(id)
+		}
+	}
+	return nil, nil
 }
 
 // LoadRange loads all entries in [from, to] inclusive.
-func LoadRange(base string, from, to time.Time) ([]model.Entry, error) {
+func (s *JSONStore) LoadRange(from, to time.Time) ([]model.Entry, error) {
 	var entries []model.Entry
 	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
-		df, err := LoadDay(base, d)
+		df, err := s.LoadDay(d)
 		if err != nil {
 			return nil, err
 		}
@@ -117,3 +216,55 @@ func LoadRange(base string, from, to time.Time) ([]model.Entry, error) {
 	}
 	return entries, nil
 }
+
+// Days returns the dates of all existing day files in ascending order. Only
+// the YYYY/MM/DD.json layout is considered; other files are ignored.
+func (s *JSONStore) Days() ([]time.Time, error) {
+	base := s.base
+	var days []time.Time
+	years, err := os.ReadDir(base)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage error reading %s: %w", base, err)
+	}
+	for _, y := range years {
+		if !y.IsDir() || len(y.Name()) != 4 {
+			continue
+		}
+		months, err := os.ReadDir(filepath.Join(base, y.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("storage error reading %s: %w", y.Name(), err)
+		}
+		for _, m := range months {
+			if !m.IsDir() || len(m.Name()) != 2 {
+				continue
+			}
+			files, err := os.ReadDir(filepath.Join(base, y.Name(), m.Name()))
+			if err != nil {
+				return nil, fmt.Errorf("storage error reading %s/%s: %w", y.Name(), m.Name(), err)
+			}
+			for _, f := range files {
+				name := f.Name()
+				if f.IsDir() || len(name) != len("02.json") || filepath.Ext(name) != ".json" {
+					continue
+				}
+				d, err := time.ParseInLocation("2006/01/02", y.Name()+"/"+m.Name()+"/"+name[:2], time.Local)
+				if err != nil {
+					continue
+				}
+				days = append(days, d)
+			}
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days, nil
+}
+
+// EachReverse calls fn for every stored entry, newest start time first,
+// together with the day whose file stores it. Iteration stops early when fn
+// returns false.
+func (s *JSONStore) EachReverse(fn func(e model.Entry, day time.Time) bool) error {
+	return eachReverseByDay(s, fn)
+}
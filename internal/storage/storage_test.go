@@ -7,12 +7,14 @@ import (
 
 	"github.com/Tiliavir/trivial-time-tracker/internal/model"
 	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
 )
 
 func TestLoadDayNotExist(t *testing.T) {
 	base := t.TempDir()
+	store := storage.NewJSONStore(base)
 	day := time.Date(2026, 2, 27, 0, 0, 0, 0, time.UTC)
-	df, err := storage.LoadDay(base, day)
+	df, err := store.LoadDay(day)
 	if err != nil {
 		t.Fatalf("LoadDay on missing file: %v", err)
 	}
@@ -26,6 +28,7 @@ func TestLoadDayNotExist(t *testing.T) {
 
 func TestSaveDayAndLoadDay(t *testing.T) {
 	base := t.TempDir()
+	store := storage.NewJSONStore(base)
 	day := time.Date(2026, 2, 27, 0, 0, 0, 0, time.UTC)
 
 	project := "ECM"
@@ -42,11 +45,11 @@ func TestSaveDayAndLoadDay(t *testing.T) {
 		},
 	}
 
-	if err := storage.SaveDay(base, day, df); err != nil {
+	if err := store.SaveDay(day, df); err != nil {
 		t.Fatalf("SaveDay: %v", err)
 	}
 
-	loaded, err := storage.LoadDay(base, day)
+	loaded, err := store.LoadDay(day)
 	if err != nil {
 		t.Fatalf("LoadDay after save: %v", err)
 	}
@@ -61,6 +64,7 @@ func TestSaveDayAndLoadDay(t *testing.T) {
 func TestSaveDayAtomicOnCorruptTmp(t *testing.T) {
 	// Verify that a corrupt JSON file is backed up and returns an error.
 	base := t.TempDir()
+	store := storage.NewJSONStore(base)
 	day := time.Date(2026, 2, 27, 0, 0, 0, 0, time.UTC)
 
 	// Write corrupt JSON directly to the path.
@@ -72,7 +76,7 @@ func TestSaveDayAtomicOnCorruptTmp(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	_, err := storage.LoadDay(base, day)
+	_, err := store.LoadDay(day)
 	if err == nil {
 		t.Fatal("expected error for corrupt JSON, got nil")
 	}
@@ -85,6 +89,7 @@ func TestSaveDayAtomicOnCorruptTmp(t *testing.T) {
 
 func TestUpdateEntry(t *testing.T) {
 	base := t.TempDir()
+	store := storage.NewJSONStore(base)
 	day := time.Date(2026, 2, 27, 0, 0, 0, 0, time.UTC)
 
 	entry := model.Entry{
@@ -95,18 +100,18 @@ func TestUpdateEntry(t *testing.T) {
 		Source:  "manual",
 	}
 
-	if err := storage.UpdateEntry(base, day, entry); err != nil {
+	if err := store.UpdateEntry(day, entry); err != nil {
 		t.Fatalf("UpdateEntry (insert): %v", err)
 	}
 
 	// Update the same entry.
 	task := "updated task"
 	entry.Task = &task
-	if err := storage.UpdateEntry(base, day, entry); err != nil {
+	if err := store.UpdateEntry(day, entry); err != nil {
 		t.Fatalf("UpdateEntry (update): %v", err)
 	}
 
-	df, err := storage.LoadDay(base, day)
+	df, err := store.LoadDay(day)
 	if err != nil {
 		t.Fatalf("LoadDay: %v", err)
 	}
@@ -120,10 +125,11 @@ func TestUpdateEntry(t *testing.T) {
 
 func TestFindActiveEntry(t *testing.T) {
 	base := t.TempDir()
+	store := storage.NewJSONStore(base)
 	day := time.Now()
 
 	// No entries — expect nil.
-	active, _, err := storage.FindActiveEntry(base)
+	active, _, err := store.FindActiveEntry()
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -139,11 +145,11 @@ func TestFindActiveEntry(t *testing.T) {
 		Start:   day,
 		Source:  "manual",
 	}
-	if err := storage.UpdateEntry(base, day, entry); err != nil {
+	if err := store.UpdateEntry(day, entry); err != nil {
 		t.Fatal(err)
 	}
 
-	active, _, err = storage.FindActiveEntry(base)
+	active, _, err = store.FindActiveEntry()
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -154,3 +160,159 @@ func TestFindActiveEntry(t *testing.T) {
 		t.Errorf("active ID = %q, want %q", active.ID, "active-1")
 	}
 }
+
+func TestDaysAndFindByPrefix(t *testing.T) {
+	base := t.TempDir()
+	store := storage.NewJSONStore(base)
+	d1 := time.Date(2025, 12, 31, 9, 0, 0, 0, time.Local)
+	d2 := time.Date(2026, 1, 2, 9, 0, 0, 0, time.Local)
+	for _, e := range []model.Entry{
+		{ID: "20251231-090000-aaaaa", Project: "A", Tags: []string{}, Start: d1, Source: "manual"},
+		{ID: "20260102-090000-bbbbb", Project: "B", Tags: []string{}, Start: d2, Source: "manual"},
+	} {
+		if err := store.UpdateEntry(e.Start, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Stray files must be ignored.
+	if err := os.WriteFile(base+"/2026/01/02.json.corrupt", []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	days, err := store.Days()
+	if err != nil {
+		t.Fatalf("Days: %v", err)
+	}
+	if len(days) != 2 || !timecalc.SameDay(days[0], d1) || !timecalc.SameDay(days[1], d2) {
+		t.Errorf("Days = %v, want [%v %v]", days, d1, d2)
+	}
+
+	matches, err := storage.FindByPrefix(store, "2026")
+	if err != nil {
+		t.Fatalf("FindByPrefix: %v", err)
+	}
+	if len(matches) != 1 || matches[0].Entry.Project != "B" {
+		t.Errorf("FindByPrefix = %+v, want only B", matches)
+	}
+
+	last, _, err := storage.LastEntry(store)
+	if err != nil {
+		t.Fatalf("LastEntry: %v", err)
+	}
+	if last == nil || last.Project != "B" {
+		t.Errorf("LastEntry = %+v, want B", last)
+	}
+}
+
+func TestFindActiveEntryPointer(t *testing.T) {
+	base := t.TempDir()
+	store := storage.NewJSONStore(base)
+	old := time.Date(2025, 8, 1, 9, 0, 0, 0, time.Local)
+	newer := old.AddDate(0, 0, 1)
+	for _, e := range []model.Entry{
+		{ID: "old", Project: "A", Tags: []string{}, Start: old, Source: "manual"},
+		{ID: "newer", Project: "B", Tags: []string{}, Start: newer, Source: "manual"},
+	} {
+		if err := store.UpdateEntry(e.Start, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	active, _, err := store.FindActiveEntry()
+	if err != nil || active == nil || active.ID != "newer" {
+		t.Fatalf("FindActiveEntry = %+v, %v; want newer", active, err)
+	}
+
+	// Both open entries are reported, newest first.
+	open, err := storage.OpenEntries(store)
+	if err != nil || len(open) != 2 || open[0].Entry.ID != "newer" {
+		t.Errorf("OpenEntries = %+v, %v; want newer and old", open, err)
+	}
+
+	// Closing the active entry moves the pointer to the open entry left.
+	end := newer.Add(time.Hour)
+	closed := model.Entry{ID: "newer", Project: "B", Tags: []string{}, Start: newer, End: &end, Source: "manual"}
+	if err := store.UpdateEntry(newer, closed); err != nil {
+		t.Fatal(err)
+	}
+	if active, _, err := store.FindActiveEntry(); err != nil || active == nil || active.ID != "old" {
+		t.Errorf("FindActiveEntry after stop = %+v, %v; want old", active, err)
+	}
+
+	// A missing pointer falls back to a full scan and is rebuilt.
+	if err := os.Remove(base + "/active.json"); err != nil {
+		t.Fatal(err)
+	}
+	active, day, err := store.FindActiveEntry()
+	if err != nil || active == nil || active.ID != "old" || !timecalc.SameDay(day, old) {
+		t.Errorf("FindActiveEntry after scan = %+v on %v, %v; want old", active, day, err)
+	}
+	if _, err := os.Stat(base + "/active.json"); err != nil {
+		t.Errorf("pointer not rebuilt: %v", err)
+	}
+}
+
+func TestFindActiveEntryAfterHandEdit(t *testing.T) {
+	base := t.TempDir()
+	store := storage.NewJSONStore(base)
+	start := time.Date(2026, 2, 27, 9, 0, 0, 0, time.Local)
+	end := start.Add(time.Hour)
+	stopped := model.Entry{ID: "20260227-090000-stop1", Project: "A", Tags: []string{}, Start: start, End: &end, Source: "manual"}
+	if err := store.UpdateEntry(start, stopped); err != nil {
+		t.Fatal(err)
+	}
+	if active, _, err := store.FindActiveEntry(); err != nil || active != nil {
+		t.Fatalf("FindActiveEntry = %+v, %v; want none", active, err)
+	}
+
+	// An open entry written behind the store's back, and later than the
+	// empty pointer, is still found.
+	open := model.Entry{ID: "20260227-110000-open1", Project: "B", Tags: []string{}, Start: start.Add(2 * time.Hour), Source: "manual"}
+	if err := store.SaveDay(start, model.DayFile{Date: "2026-02-27", Entries: []model.Entry{stopped, open}}); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(base+"/2026/02/27.json", later, later); err != nil {
+		t.Fatal(err)
+	}
+	if active, _, err := store.FindActiveEntry(); err != nil || active == nil || active.ID != open.ID {
+		t.Errorf("FindActiveEntry after hand edit = %+v, %v; want %s", active, err, open.ID)
+	}
+}
+
+func TestRestoredEntryKeepsRunningTimer(t *testing.T) {
+	store := storage.NewJSONStore(t.TempDir())
+	start := time.Date(2026, 2, 27, 9, 0, 0, 0, time.Local)
+	old := model.Entry{ID: "20260227-090000-old01", Project: "OLD", Tags: []string{}, Start: start, Source: "manual"}
+	if err := store.UpdateEntry(start, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.TrashEntry(start, old.ID, start.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	later := start.Add(2 * time.Hour)
+	running := model.Entry{ID: "20260227-110000-new01", Project: "NEW", Tags: []string{}, Start: later, Source: "manual"}
+	if err := store.UpdateEntry(later, running); err != nil {
+		t.Fatal(err)
+	}
+
+	// Restoring the older open entry leaves the running timer active.
+	if _, err := store.RestoreEntry(old.ID); err != nil {
+		t.Fatal(err)
+	}
+	active, _, err := store.FindActiveEntry()
+	if err != nil || active == nil || active.ID != running.ID {
+		t.Fatalf("FindActiveEntry after restore = %+v, %v; want NEW", active, err)
+	}
+
+	// Stopping it moves the pointer to the restored entry, which is still open.
+	end := later.Add(time.Hour)
+	running.End = &end
+	if err := store.UpdateEntry(later, running); err != nil {
+		t.Fatal(err)
+	}
+	active, _, err = store.FindActiveEntry()
+	if err != nil || active == nil || active.ID != old.ID {
+		t.Errorf("FindActiveEntry after stop = %+v, %v; want OLD", active, err)
+	}
+}
@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+)
+
+// Store is a storage backend for entries, the trash and the project
+// registry. Entries are grouped by the day they are stored under, which is
+// normally the day of their start time.
+type Store interface {
+	// Days returns the days that have stored entries, in ascending order.
+	Days() ([]time.Time, error)
+	// DayStamp returns a token that changes whenever the entries stored
+	// for day change, so callers can cache derived data per day.
+	DayStamp(day time.Time) (string, error)
+	// LoadDay returns the entries stored for day, or an empty DayFile.
+	LoadDay(day time.Time) (model.DayFile, error)
+	// LoadRange returns all entries stored for the days in [from, to].
+	LoadRange(from, to time.Time) ([]model.Entry, error)
+	// UpdateEntry replaces the entry with the same ID stored for day, or
+	// appends it.
+	UpdateEntry(day time.Time, entry model.Entry) error
+	// RemoveEntry deletes the entry with the given ID stored for day and
+	// returns it, or nil if no such entry exists.
+	RemoveEntry(day time.Time, id string) (*model.Entry, error)
+	// FindActiveEntry returns the most recent open entry in the whole
+	// history and its day, or nil if no timer is running.
+	FindActiveEntry() (*model.Entry, time.Time, error)
+	// EachReverse calls fn for every stored entry, newest start time first,
+	// together with its day. Iteration stops early when fn returns false.
+	EachReverse(fn func(e model.Entry, day time.Time) bool) error
+
+	// TrashEntry moves the entry from day into the trash, recording now as
+	// its deletion time. It returns nil if no such entry exists.
+	TrashEntry(day time.Time, id string, now time.Time) (*model.TrashedEntry, error)
+	// ListTrash returns all trashed entries, most recently deleted first.
+	ListTrash() ([]model.TrashedEntry, error)
+	// RestoreEntry moves a trashed entry back to the day of its start time.
+	// It returns nil if the ID is not in the trash.
+	RestoreEntry(id string) (*model.Entry, error)
+	// PurgeTrash permanently deletes entries trashed before cutoff, or all
+	// of them when cutoff is zero, and returns how many were purged.
+	PurgeTrash(cutoff time.Time) (int, error)
+
+	// LoadProjects returns the project registry.
+	LoadProjects() ([]model.Project, error)
+	// SaveProjects replaces the project registry.
+	SaveProjects(projects []model.Project) error
+	// RenameProject sets the project of every stored and trashed entry
+	// matching from (see model.SameProject) to to and returns the number
+	// of entries changed.
+	RenameProject(from, to string) (int, error)
+}
+
+// Backend names accepted by Open and the storage.backend config option.
+const (
+	BackendJSON   = "json"
+	BackendSQLite = "sqlite"
+)
+
+// Open returns the store for the named backend with its data under base.
+// An empty name selects the JSON day-file backend.
+func Open(backend, base string) (Store, error) {
+	switch backend {
+	case "", BackendJSON:
+		return NewJSONStore(base), nil
+	case BackendSQLite:
+		return OpenSQLite(sqliteFilePath(base))
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q (want %q or %q)", backend, BackendJSON, BackendSQLite)
+	}
+}
+
+// Located is an entry together with the day whose file stores it.
+type Located struct {
+	Entry model.Entry
+	Day   time.Time
+}
+
+// LoadAll returns the entries of every stored day in chronological order of
+// days, without probing days that have no entries.
+func LoadAll(s Store) ([]model.Entry, error) {
+	days, err := s.Days()
+	if err != nil {
+		return nil, err
+	}
+	var entries []model.Entry
+	for _, d := range days {
+		df, err := s.LoadDay(d)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, df.Entries...)
+	}
+	return entries, nil
+}
+
+// FindByPrefix returns all entries whose ID equals or starts with prefix,
+// searching every stored day. An exact ID match is returned on its own.
+func FindByPrefix(s Store, prefix string) ([]Located, error) {
+	days, err := s.Days()
+	if err != nil {
+		return nil, err
+	}
+	var matches []Located
+	for i := len(days) - 1; i >= 0; i-- {
+		df, err := s.LoadDay(days[i])
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range df.Entries {
+			if e.ID == prefix {
+				return []Located{{Entry: e, Day: days[i]}}, nil
+			}
+			if strings.HasPrefix(e.ID, prefix) {
+				matches = append(matches, Located{Entry: e, Day: days[i]})
+			}
+		}
+	}
+	return matches, nil
+}
+
+// LastEntry returns the most recently started entry and its day, or nil if
+// no entries exist.
+func LastEntry(s Store) (*model.Entry, time.Time, error) {
+	var last *model.Entry
+	var lastDay time.Time
+	err := s.EachReverse(func(e model.Entry, day time.Time) bool {
+		last, lastDay = &e, day
+		return false
+	})
+	return last, lastDay, err
+}
+
+// eachReverseByDay implements Store.EachReverse on top of Days and LoadDay.
+func eachReverseByDay(s Store, fn func(e model.Entry, day time.Time) bool) error {
+	days, err := s.Days()
+	if err != nil {
+		return err
+	}
+	for i := len(days) - 1; i >= 0; i-- {
+		df, err := s.LoadDay(days[i])
+		if err != nil {
+			return err
+		}
+		entries := append([]model.Entry(nil), df.Entries...)
+		sort.SliceStable(entries, func(a, b int) bool { return entries[a].Start.After(entries[b].Start) })
+		for _, e := range entries {
+			if !fn(e, days[i]) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// findActiveInAll implements Store.FindActiveEntry by scanning every stored
+// day, newest first, for an entry with end == nil.
+func findActiveInAll(s Store) (*model.Entry, time.Time, error) {
+	days, err := s.Days()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	for i := len(days) - 1; i >= 0; i-- {
+		df, err := s.LoadDay(days[i])
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		for j := len(df.Entries) - 1; j >= 0; j-- {
+			if df.Entries[j].End == nil {
+				return &df.Entries[j], days[i], nil
+			}
+		}
+	}
+	return nil, time.Time{}, nil
+}
+
+// OpenEntries returns every entry with end == nil in any stored day, newest
+// day first. More than one indicates inconsistent data.
+func OpenEntries(s Store) ([]Located, error) {
+	var open []Located
+	err := s.EachReverse(func(e model.Entry, day time.Time) bool {
+		if e.End == nil {
+			open = append(open, Located{Entry: e, Day: day})
+		}
+		return true
+	})
+	return open, err
+}
+
+// sortProjects returns a copy of projects sorted case-insensitively by name,
+// never nil.
+func sortProjects(projects []model.Project) []model.Project {
+	sorted := append([]model.Project{}, projects...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return strings.ToLower(sorted[i].Name) < strings.ToLower(sorted[j].Name)
+	})
+	return sorted
+}
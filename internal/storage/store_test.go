@@ -0,0 +1,156 @@
+package storage_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+)
+
+// backends lists every Store implementation covered by testStore.
+var backends = map[string]func(t *testing.T) storage.Store{
+	"json":   func(t *testing.T) storage.Store { return storage.NewJSONStore(t.TempDir()) },
+	"memory": func(t *testing.T) storage.Store { return storage.NewMemoryStore() },
+	"sqlite": func(t *testing.T) storage.Store {
+		s, err := storage.OpenSQLite(filepath.Join(t.TempDir(), "ttt.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { s.Close() })
+		return s
+	},
+}
+
+func TestStoreBackends(t *testing.T) {
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) { testStore(t, open(t)) })
+	}
+}
+
+// testStore checks the behaviour every Store must share.
+func testStore(t *testing.T, s storage.Store) {
+	d1 := time.Date(2026, 2, 26, 9, 0, 0, 0, time.Local)
+	d2 := time.Date(2026, 2, 27, 9, 0, 0, 0, time.Local)
+	end := d1.Add(time.Hour)
+
+	stamp, err := s.DayStamp(d1)
+	if err != nil || stamp != "" {
+		t.Errorf("DayStamp(empty) = %q, %v; want empty", stamp, err)
+	}
+
+	for _, e := range []model.Entry{
+		{ID: "a", Project: "ecm", Tags: []string{"x"}, Start: d1, End: &end, Source: "manual"},
+		{ID: "b", Project: "Other", Tags: []string{}, Start: d2, End: &end, Source: "manual"},
+		{ID: "20260227-100000-ccccc", Project: "ECM", Tags: []string{}, Start: d2.Add(time.Hour), Source: "manual"},
+	} {
+		if err := s.UpdateEntry(e.Start, e); err != nil {
+			t.Fatalf("UpdateEntry: %v", err)
+		}
+	}
+
+	// An open entry is found however long ago it was started.
+	active, day, err := s.FindActiveEntry()
+	if err != nil || active == nil || active.ID != "20260227-100000-ccccc" || day.Day() != 27 {
+		t.Errorf("FindActiveEntry = %+v on %v, %v; want c", active, day, err)
+	}
+
+	days, err := s.Days()
+	if err != nil || len(days) != 2 || days[0].Day() != 26 || days[1].Day() != 27 {
+		t.Errorf("Days = %v, %v; want 26th and 27th", days, err)
+	}
+
+	stamp, _ = s.DayStamp(d1)
+	task := "edited"
+	edited := model.Entry{ID: "a", Project: "ecm", Tags: []string{"x"}, Task: &task, Start: d1, End: &end, Source: "manual"}
+	if err := s.UpdateEntry(d1, edited); err != nil {
+		t.Fatalf("UpdateEntry(replace): %v", err)
+	}
+	if after, _ := s.DayStamp(d1); after == stamp {
+		t.Errorf("DayStamp unchanged after write: %q", after)
+	}
+	df, err := s.LoadDay(d1)
+	if err != nil || len(df.Entries) != 1 || df.Entries[0].Task == nil || *df.Entries[0].Task != task {
+		t.Errorf("LoadDay after replace = %+v, %v", df.Entries, err)
+	}
+	df.Entries[0].Tags[0] = "mutated"
+	if df, _ := s.LoadDay(d1); df.Entries[0].Tags[0] != "x" {
+		t.Error("LoadDay returned entries sharing storage with the store")
+	}
+
+	entries, err := s.LoadRange(d1, d2)
+	if err != nil || len(entries) != 3 {
+		t.Errorf("LoadRange = %d entries, %v; want 3", len(entries), err)
+	}
+
+	var order []string
+	if err := s.EachReverse(func(e model.Entry, _ time.Time) bool {
+		order = append(order, e.ID)
+		return true
+	}); err != nil || len(order) != 3 || order[0] != "20260227-100000-ccccc" || order[2] != "a" {
+		t.Errorf("EachReverse order = %v, %v; want c, b, a", order, err)
+	}
+
+	removed, err := s.RemoveEntry(d2, "b")
+	if err != nil || removed == nil || removed.ID != "b" {
+		t.Errorf("RemoveEntry = %+v, %v; want b", removed, err)
+	}
+	if removed, _ := s.RemoveEntry(d2, "b"); removed != nil {
+		t.Errorf("RemoveEntry(missing) = %+v, want nil", removed)
+	}
+
+	trashed, err := s.TrashEntry(d2, "20260227-100000-ccccc", d2)
+	if err != nil || trashed == nil {
+		t.Fatalf("TrashEntry = %v, %v", trashed, err)
+	}
+	if list, _ := s.ListTrash(); len(list) != 1 || list[0].Entry.ID != "20260227-100000-ccccc" {
+		t.Errorf("ListTrash = %+v, want c", list)
+	}
+
+	if err := s.SaveProjects([]model.Project{{Name: "ECM"}, {Name: "Admin"}}); err != nil {
+		t.Fatalf("SaveProjects: %v", err)
+	}
+	projects, err := s.LoadProjects()
+	if err != nil || len(projects) != 2 || projects[0].Name != "Admin" {
+		t.Errorf("LoadProjects = %+v, %v; want Admin first", projects, err)
+	}
+
+	n, err := s.RenameProject("ECM", "Core")
+	if err != nil || n != 2 {
+		t.Errorf("RenameProject = %d, %v; want 2", n, err)
+	}
+
+	restored, err := s.RestoreEntry("20260227-100000-ccccc")
+	if err != nil || restored == nil || restored.Project != "Core" {
+		t.Errorf("RestoreEntry = %+v, %v; want renamed c", restored, err)
+	}
+	if n, err := s.PurgeTrash(time.Time{}); err != nil || n != 0 {
+		t.Errorf("PurgeTrash = %d, %v; want 0", n, err)
+	}
+
+	all, err := storage.LoadAll(s)
+	if err != nil || len(all) != 2 {
+		t.Errorf("LoadAll = %d entries, %v; want 2", len(all), err)
+	}
+	for _, e := range all {
+		if e.Project != "Core" {
+			t.Errorf("entry %s project = %q, want Core", e.ID, e.Project)
+		}
+	}
+
+	now := time.Now()
+	if err := s.UpdateEntry(now, model.Entry{ID: "live", Project: "P", Tags: []string{}, Start: now, Source: "manual"}); err != nil {
+		t.Fatal(err)
+	}
+	active, _, err = s.FindActiveEntry()
+	if err != nil || active == nil || active.ID != "live" {
+		t.Errorf("FindActiveEntry = %+v, %v; want live", active, err)
+	}
+}
+
+func TestOpenUnknownBackend(t *testing.T) {
+	if _, err := storage.Open("nope", t.TempDir()); err == nil {
+		t.Error("Open(unknown) succeeded, want error")
+	}
+}
@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+)
+
+// trashDir returns the directory holding deleted entries, one file per entry.
+func trashDir(base string) string {
+	return filepath.Join(base, "trash")
+}
+
+// entryIDRe matches the IDs timecalc.GenerateID creates, e.g.
+// 20260227-093000-k3x9a.
+var entryIDRe = regexp.MustCompile(`^\d{8}-\d{6}-[a-z0-9]{5}$`)
+
+// InvalidIDError reports an entry ID that cannot name a trash file, such as
+// one containing a path.
+type InvalidIDError struct {
+	ID string
+}
+
+func (e *InvalidIDError) Error() string {
+	return fmt.Sprintf("invalid entry ID %q: expected the form YYYYMMDD-HHMMSS-xxxxx", e.ID)
+}
+
+// trashFilePath returns the trash file for the given entry ID. IDs not
+// shaped like generated ones are rejected, so a file outside the trash can
+// never be read, written or removed.
+func trashFilePath(base, id string) (string, error) {
+	if !entryIDRe.MatchString(id) {
+		return "", &InvalidIDError{ID: id}
+	}
+	return filepath.Join(trashDir(base), id+".json"), nil
+}
+
+// TrashEntry removes the entry from its day file and stores it in the trash
+// together with the deletion time. It returns nil if no such entry exists.
+func (s *JSONStore) TrashEntry(day time.Time, id string, now time.Time) (*model.TrashedEntry, error) {
+	path, err := trashFilePath(s.base, id)
+	if err != nil {
+		return nil, err
+	}
+	unlock, err := lock(s.base)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	df, err := s.LoadDay(day)
+	if err != nil {
+		return nil, err
+	}
+	var found *model.Entry
+	for i := range df.Entries {
+		if df.Entries[i].ID == id {
+			e := df.Entries[i]
+			found = &e
+			break
+		}
+	}
+	if found == nil {
+		return nil, nil
+	}
+
+	// Write the trash copy before removing the original so a failure in
+	// between can never lose the entry.
+	trashed := model.TrashedEntry{Entry: *found, DeletedAt: now}
+	if err := os.MkdirAll(trashDir(s.base), 0o700); err != nil {
+		return nil, fmt.Errorf("storage error creating trash directory: %w", err)
+	}
+	data, err := json.MarshalIndent(trashed, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("storage error marshalling JSON: %w", err)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.removeEntry(day, id); err != nil {
+		return nil, err
+	}
+	return &trashed, nil
+}
+
+// ListTrash returns all trashed entries, most recently deleted first.
+func (s *JSONStore) ListTrash() ([]model.TrashedEntry, error) {
+	files, err := os.ReadDir(trashDir(s.base))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage error reading trash: %w", err)
+	}
+
+	var out []model.TrashedEntry
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".json" {
+			continue
+		}
+		t, err := loadTrashed(filepath.Join(trashDir(s.base), f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].DeletedAt.After(out[j].DeletedAt) })
+	return out, nil
+}
+
+// RestoreEntry moves a trashed entry back into the day file of its start
+// time. It returns nil if the ID is not in the trash.
+func (s *JSONStore) RestoreEntry(id string) (*model.Entry, error) {
+	path, err := trashFilePath(s.base, id)
+	if err != nil {
+		return nil, err
+	}
+	unlock, err := lock(s.base)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	t, err := loadTrashed(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.updateEntry(t.Entry.Start, t.Entry); err != nil {
+		return nil, err
+	}
+	if err := os.Remove(path); err != nil {
+		return nil, fmt.Errorf("storage error removing trash file: %w", err)
+	}
+	return &t.Entry, nil
+}
+
+// PurgeTrash permanently deletes trashed entries deleted before cutoff, or
+// all of them when cutoff is zero. It returns the number of purged entries.
+func (s *JSONStore) PurgeTrash(cutoff time.Time) (int, error) {
+	unlock, err := lock(s.base)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	entries, err := s.ListTrash()
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, t := range entries {
+		if !cutoff.IsZero() && !t.DeletedAt.Before(cutoff) {
+			continue
+		}
+		path, err := trashFilePath(s.base, t.Entry.ID)
+		if err != nil {
+			return n, err
+		}
+		if err := os.Remove(path); err != nil {
+			return n, fmt.Errorf("storage error purging %s: %w", t.Entry.ID, err)
+		}
+		n++
+	}
+	return n, nil
+}
+
+func loadTrashed(path string) (model.TrashedEntry, error) {
+	var t model.TrashedEntry
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return t, err
+		}
+		return t, fmt.Errorf("storage error reading %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &t); err != nil {
+		return t, fmt.Errorf("corrupt JSON in %s: %w", path, err)
+	}
+	return t, nil
+}
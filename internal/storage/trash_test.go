@@ -0,0 +1,122 @@
+package storage_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/storage"
+)
+
+func TestTrashAndRestore(t *testing.T) {
+	base := t.TempDir()
+	store := storage.NewJSONStore(base)
+	day := time.Date(2026, 2, 27, 9, 0, 0, 0, time.UTC)
+	for _, id := range []string{"20260227-090000-keep1", "20260227-090000-drop1"} {
+		e := model.Entry{ID: id, Project: "P", Tags: []string{}, Start: day, Source: "manual"}
+		if err := store.UpdateEntry(day, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deletedAt := day.Add(time.Hour)
+	trashed, err := store.TrashEntry(day, "20260227-090000-drop1", deletedAt)
+	if err != nil {
+		t.Fatalf("TrashEntry: %v", err)
+	}
+	if trashed == nil || !trashed.DeletedAt.Equal(deletedAt) {
+		t.Fatalf("TrashEntry = %+v, want deletion time %v", trashed, deletedAt)
+	}
+
+	df, err := store.LoadDay(day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(df.Entries) != 1 || df.Entries[0].ID != "20260227-090000-keep1" {
+		t.Errorf("entries after delete = %+v, want only keep", df.Entries)
+	}
+
+	list, err := store.ListTrash()
+	if err != nil {
+		t.Fatalf("ListTrash: %v", err)
+	}
+	if len(list) != 1 || list[0].Entry.ID != "20260227-090000-drop1" {
+		t.Errorf("ListTrash = %+v, want drop", list)
+	}
+
+	restored, err := store.RestoreEntry("20260227-090000-drop1")
+	if err != nil || restored == nil {
+		t.Fatalf("RestoreEntry = %v, %v", restored, err)
+	}
+	df, err = store.LoadDay(day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(df.Entries) != 2 {
+		t.Errorf("entries after restore = %d, want 2", len(df.Entries))
+	}
+	if list, _ := store.ListTrash(); len(list) != 0 {
+		t.Errorf("trash after restore = %d entries, want 0", len(list))
+	}
+
+	missing, err := store.TrashEntry(day, "20260227-090000-nope1", deletedAt)
+	if err != nil || missing != nil {
+		t.Errorf("TrashEntry(missing) = %v, %v; want nil, nil", missing, err)
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	base := t.TempDir()
+	store := storage.NewJSONStore(base)
+	day := time.Date(2026, 2, 27, 9, 0, 0, 0, time.UTC)
+	for i, id := range []string{"20260227-090000-old01", "20260227-090000-new01"} {
+		e := model.Entry{ID: id, Project: "P", Tags: []string{}, Start: day, Source: "manual"}
+		if err := store.UpdateEntry(day, e); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.TrashEntry(day, id, day.AddDate(0, 0, i*10)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := store.PurgeTrash(day.AddDate(0, 0, 5))
+	if err != nil {
+		t.Fatalf("PurgeTrash: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("purged %d, want 1", n)
+	}
+	list, err := store.ListTrash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Entry.ID != "20260227-090000-new01" {
+		t.Errorf("remaining trash = %+v, want new", list)
+	}
+}
+
+func TestTrashRejectsPathIDs(t *testing.T) {
+	base := t.TempDir()
+	store := storage.NewJSONStore(base)
+	day := time.Date(2026, 2, 27, 9, 0, 0, 0, time.UTC)
+	e := model.Entry{ID: "20260227-090000-keep1", Project: "P", Tags: []string{}, Start: day, Source: "manual"}
+	if err := store.UpdateEntry(day, e); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"../2026/02/27", "..", "20260227-090000-keep1/..", `..\x`, "", "keep"} {
+		var invalid *storage.InvalidIDError
+		if restored, err := store.RestoreEntry(id); !errors.As(err, &invalid) || restored != nil {
+			t.Errorf("RestoreEntry(%q) = %v, %v; want an InvalidIDError", id, restored, err)
+		}
+		if _, err := store.TrashEntry(day, id, day); !errors.As(err, &invalid) {
+			t.Errorf("TrashEntry(%q) error = %v, want an InvalidIDError", id, err)
+		}
+	}
+
+	df, err := store.LoadDay(day)
+	if err != nil || len(df.Entries) != 1 {
+		t.Errorf("day file after invalid IDs = %+v, %v; want the entry untouched", df.Entries, err)
+	}
+}
@@ -54,6 +54,27 @@ func WeekRange(t time.Time) (time.Time, time.Time) {
 	return monday, sunday
 }
 
+// DayRange returns 00:00:00 and 23:59:59 of the day containing t.
+func DayRange(t time.Time) (time.Time, time.Time) {
+	return StartOfDay(t), EndOfDay(t)
+}
+
+// MonthRange returns the first and last day of the month containing t,
+// at 00:00:00 and 23:59:59 respectively.
+func MonthRange(t time.Time) (time.Time, time.Time) {
+	first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	last := first.AddDate(0, 1, -1)
+	return first, EndOfDay(last)
+}
+
+// YearRange returns January 1st 00:00:00 and December 31st 23:59:59 of the
+// year containing t.
+func YearRange(t time.Time) (time.Time, time.Time) {
+	first := time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location())
+	last := time.Date(t.Year(), time.December, 31, 0, 0, 0, 0, t.Location())
+	return first, EndOfDay(last)
+}
+
 // ISOWeekLabel returns a label like "2026-W09".
 func ISOWeekLabel(t time.Time) string {
 	year, week := t.ISOWeek()
@@ -61,6 +61,46 @@ func TestWeekRange(t *testing.T) {
 	}
 }
 
+func TestMonthRange(t *testing.T) {
+	tests := []struct {
+		in        time.Time
+		wantFirst time.Time
+		wantLast  time.Time
+	}{
+		{
+			time.Date(2026, 2, 27, 10, 0, 0, 0, time.UTC),
+			time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2026, 2, 28, 23, 59, 59, 0, time.UTC),
+		},
+		{
+			time.Date(2028, 2, 10, 0, 0, 0, 0, time.UTC),
+			time.Date(2028, 2, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2028, 2, 29, 23, 59, 59, 0, time.UTC),
+		},
+		{
+			time.Date(2026, 12, 31, 23, 0, 0, 0, time.UTC),
+			time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2026, 12, 31, 23, 59, 59, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		first, last := timecalc.MonthRange(tt.in)
+		if !first.Equal(tt.wantFirst) || !last.Equal(tt.wantLast) {
+			t.Errorf("MonthRange(%v) = %v, %v; want %v, %v", tt.in, first, last, tt.wantFirst, tt.wantLast)
+		}
+	}
+}
+
+func TestYearRange(t *testing.T) {
+	first, last := timecalc.YearRange(time.Date(2026, 7, 4, 12, 0, 0, 0, time.UTC))
+	if want := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC); !first.Equal(want) {
+		t.Errorf("YearRange first = %v, want %v", first, want)
+	}
+	if want := time.Date(2026, 12, 31, 23, 59, 59, 0, time.UTC); !last.Equal(want) {
+		t.Errorf("YearRange last = %v, want %v", last, want)
+	}
+}
+
 func TestISOWeekLabel(t *testing.T) {
 	fri := time.Date(2026, 2, 27, 10, 0, 0, 0, time.UTC)
 	got := timecalc.ISOWeekLabel(fri)
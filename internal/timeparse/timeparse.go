@@ -0,0 +1,276 @@
+// Package timeparse parses the human-friendly time, date and duration
+// expressions accepted by ttt's time flags. All expressions are interpreted
+// in the location of the reference time passed in.
+//
+// Supported forms:
+//
+//	Days:      today, yesterday, tomorrow, mon..sun, monday..sunday,
+//	           last <weekday>, YYYY-MM-DD
+//	Clocks:    14:30, 14:30:15, 9am, 9:30pm, 9 am, noon, midnight
+//	Times:     <day>, <clock>, <day> <clock>, <clock> <day>, now,
+//	           RFC3339, YYYY-MM-DDTHH:MM[:SS]
+//	Offsets:   <time>+<duration>, <time>-<duration>, -<duration> (from now)
+//	Durations: 1h30m, 90m, 1.5h, 1h 30m, 1:30, 1.5 (hours), 2d,
+//	           30 min, 2 hours
+//
+// A bare weekday refers to the most recent such day, today included;
+// "last <weekday>" excludes today.
+package timeparse
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// offsetRe matches a trailing "+<duration>" or "-<duration>" offset. A unit
+// is required so that dates (2026-02-27) and zone offsets (-01:00) are not
+// mistaken for offsets.
+var offsetRe = regexp.MustCompile(`^(.*?)\s*([+-])\s*((?:\d+(?:\.\d+)?\s*[a-z]+\s*)+)$`)
+
+// clockRe matches 24h and 12h clock times.
+var clockRe = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(?::(\d{2}))?(am|pm|a|p)?$`)
+
+// hoursMinutesRe matches h:mm durations.
+var hoursMinutesRe = regexp.MustCompile(`^(\d+):(\d{2})$`)
+
+// durationPartRe matches one number-and-unit component of a duration.
+var durationPartRe = regexp.MustCompile(`^(\d+(?:\.\d+)?)([a-z]+)`)
+
+// bareHoursRe matches a number of hours without a unit, e.g. 1.5. Unlike
+// strconv.ParseFloat it rejects exponents, signs, inf and nan.
+var bareHoursRe = regexp.MustCompile(`^\d+(\.\d+)?$`)
+
+// Day parses a date-only expression and returns midnight of that day.
+func Day(s string, now time.Time) (time.Time, error) {
+	d, ok := parseDay(strings.Fields(strings.ToLower(strings.TrimSpace(s))), now)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%q: expected a day such as today, yesterday, mon or YYYY-MM-DD", s)
+	}
+	return d, nil
+}
+
+// Time parses a point in time. Clock-only expressions refer to the day of now.
+func Time(s string, now time.Time) (time.Time, error) {
+	return TimeOn(s, now, now)
+}
+
+// TimeOn parses a point in time. Clock-only expressions and offsets without a
+// base refer to day; "now" always refers to now. Day-only expressions resolve
+// to midnight.
+func TimeOn(s string, day, now time.Time) (time.Time, error) {
+	in := strings.ToLower(strings.TrimSpace(s))
+	if in == "" {
+		return time.Time{}, fmt.Errorf("empty time")
+	}
+
+	if m := offsetRe.FindStringSubmatch(in); m != nil {
+		d, err := Duration(m[3])
+		if err == nil {
+			base := now
+			if strings.TrimSpace(m[1]) != "" {
+				if base, err = TimeOn(m[1], day, now); err != nil {
+					return time.Time{}, err
+				}
+			}
+			if m[2] == "-" {
+				d = -d
+			}
+			return base.Add(d), nil
+		}
+	}
+
+	if t, ok := parseAbsolute(s, now.Location()); ok {
+		return t, nil
+	}
+	if in == "now" {
+		return now, nil
+	}
+
+	tokens := strings.Fields(in)
+	// Try every split into a day part and a clock part, in both orders.
+	for i := 0; i <= len(tokens); i++ {
+		for _, order := range [][2][]string{{tokens[:i], tokens[i:]}, {tokens[i:], tokens[:i]}} {
+			dayPart, clockPart := order[0], order[1]
+			d := timecalc.StartOfDay(day)
+			if len(dayPart) > 0 {
+				var ok bool
+				if d, ok = parseDay(dayPart, now); !ok {
+					continue
+				}
+			}
+			if len(clockPart) == 0 {
+				if len(dayPart) == 0 {
+					continue
+				}
+				return d, nil
+			}
+			h, min, sec, ok := parseClock(strings.Join(clockPart, ""))
+			if !ok {
+				continue
+			}
+			return time.Date(d.Year(), d.Month(), d.Day(), h, min, sec, 0, d.Location()), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q: expected a time such as now, 14:30, 9am, \"yesterday 17:00\", \"mon 9am\" or now-15m", s)
+}
+
+// Duration parses a duration. Besides Go syntax (1h30m) it accepts spaces
+// between components, unit words (30 min, 2 hours), days (2d), h:mm (1:30)
+// and bare numbers, which are read as hours (1.5).
+func Duration(s string) (time.Duration, error) {
+	in := strings.ToLower(strings.TrimSpace(s))
+	if in == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+
+	if m := hoursMinutesRe.FindStringSubmatch(in); m != nil {
+		h, _ := strconv.Atoi(m[1])
+		mm, _ := strconv.Atoi(m[2])
+		return time.Duration(h)*time.Hour + time.Duration(mm)*time.Minute, nil
+	}
+	if bareHoursRe.MatchString(in) {
+		f, _ := strconv.ParseFloat(in, 64)
+		return time.Duration(f * float64(time.Hour)), nil
+	}
+
+	var total time.Duration
+	rest := strings.ReplaceAll(in, " ", "")
+	for rest != "" {
+		m := durationPartRe.FindStringSubmatch(rest)
+		if m == nil {
+			return 0, fmt.Errorf("%q: expected a duration such as 1h30m, 90m, 1.5h or 1:30", s)
+		}
+		n, _ := strconv.ParseFloat(m[1], 64)
+		unit, ok := durationUnit(m[2])
+		if !ok {
+			return 0, fmt.Errorf("%q: unknown unit %q", s, m[2])
+		}
+		total += time.Duration(n * float64(unit))
+		rest = rest[len(m[0]):]
+	}
+	return total, nil
+}
+
+func durationUnit(u string) (time.Duration, bool) {
+	switch u {
+	case "d", "day", "days":
+		return 24 * time.Hour, true
+	case "h", "hr", "hrs", "hour", "hours":
+		return time.Hour, true
+	case "m", "min", "mins", "minute", "minutes":
+		return time.Minute, true
+	case "s", "sec", "secs", "second", "seconds":
+		return time.Second, true
+	}
+	return 0, false
+}
+
+// parseAbsolute handles fully specified timestamps.
+func parseAbsolute(s string, loc *time.Location) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.In(loc), true
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseDay resolves day tokens to midnight of the referenced day.
+func parseDay(tokens []string, now time.Time) (time.Time, bool) {
+	today := timecalc.StartOfDay(now)
+	switch len(tokens) {
+	case 1:
+		switch tokens[0] {
+		case "today":
+			return today, true
+		case "yesterday":
+			return today.AddDate(0, 0, -1), true
+		case "tomorrow":
+			return today.AddDate(0, 0, 1), true
+		}
+		if wd, ok := weekdays[tokens[0]]; ok {
+			return today.AddDate(0, 0, -daysSince(now.Weekday(), wd)), true
+		}
+		if t, err := time.ParseInLocation("2006-01-02", tokens[0], now.Location()); err == nil {
+			return t, true
+		}
+	case 2:
+		if tokens[0] == "last" {
+			if wd, ok := weekdays[tokens[1]]; ok {
+				back := daysSince(now.Weekday(), wd)
+				if back == 0 {
+					back = 7
+				}
+				return today.AddDate(0, 0, -back), true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// daysSince returns how many days ago the most recent wd was, 0 if today.
+func daysSince(today, wd time.Weekday) int {
+	return (int(today) - int(wd) + 7) % 7
+}
+
+// parseClock parses a clock time into hours, minutes and seconds.
+func parseClock(s string) (int, int, int, bool) {
+	switch s {
+	case "noon":
+		return 12, 0, 0, true
+	case "midnight":
+		return 0, 0, 0, true
+	}
+	m := clockRe.FindStringSubmatch(s)
+	if m == nil {
+		return 0, 0, 0, false
+	}
+	h, _ := strconv.Atoi(m[1])
+	min, sec := 0, 0
+	if m[2] != "" {
+		min, _ = strconv.Atoi(m[2])
+	}
+	if m[3] != "" {
+		sec, _ = strconv.Atoi(m[3])
+	}
+	switch m[4] {
+	case "":
+		// A bare hour without minutes or am/pm is too ambiguous.
+		if m[2] == "" || h > 23 {
+			return 0, 0, 0, false
+		}
+	default:
+		if h < 1 || h > 12 {
+			return 0, 0, 0, false
+		}
+		if h == 12 {
+			h = 0
+		}
+		if strings.HasPrefix(m[4], "p") {
+			h += 12
+		}
+	}
+	if min > 59 || sec > 59 {
+		return 0, 0, 0, false
+	}
+	return h, min, sec, true
+}
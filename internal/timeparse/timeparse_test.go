@@ -0,0 +1,146 @@
+package timeparse_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/timeparse"
+)
+
+// now is Friday 2026-02-27 15:04:05 UTC.
+var now = time.Date(2026, 2, 27, 15, 4, 5, 0, time.UTC)
+
+func at(day, h, m int) time.Time {
+	return time.Date(2026, 2, day, h, m, 0, 0, time.UTC)
+}
+
+func TestTime(t *testing.T) {
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{"now", now},
+		{"14:30", at(27, 14, 30)},
+		{"08:45:10", time.Date(2026, 2, 27, 8, 45, 10, 0, time.UTC)},
+		{"9am", at(27, 9, 0)},
+		{"9:30pm", at(27, 21, 30)},
+		{"9 AM", at(27, 9, 0)},
+		{"12am", at(27, 0, 0)},
+		{"12pm", at(27, 12, 0)},
+		{"noon", at(27, 12, 0)},
+		{"yesterday", at(26, 0, 0)},
+		{"yesterday 14:30", at(26, 14, 30)},
+		{"14:30 yesterday", at(26, 14, 30)},
+		{"mon 9am", at(23, 9, 0)},
+		{"friday 10:00", at(27, 10, 0)},
+		{"last fri 10:00", at(20, 10, 0)},
+		{"2026-02-20 17:10", at(20, 17, 10)},
+		{"2026-02-20T17:10", at(20, 17, 10)},
+		{"2026-02-20T17:10:00+01:00", at(20, 16, 10)},
+		{"now-15m", now.Add(-15 * time.Minute)},
+		{"now - 1h30m", now.Add(-90 * time.Minute)},
+		{"-20m", now.Add(-20 * time.Minute)},
+		{"yesterday 17:00+30m", at(26, 17, 30)},
+	}
+	for _, tt := range tests {
+		got, err := timeparse.Time(tt.input, now)
+		if err != nil {
+			t.Errorf("Time(%q): %v", tt.input, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("Time(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestTimeInvalid(t *testing.T) {
+	for _, input := range []string{"", "9", "25:00", "13pm", "14:75", "someday", "mon tue", "now-15x"} {
+		if got, err := timeparse.Time(input, now); err == nil {
+			t.Errorf("Time(%q) = %v, want error", input, got)
+		}
+	}
+}
+
+func TestTimeOn(t *testing.T) {
+	day := at(20, 0, 0)
+	got, err := timeparse.TimeOn("09:15", day, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := at(20, 9, 15); !got.Equal(want) {
+		t.Errorf("TimeOn(clock) = %v, want %v", got, want)
+	}
+
+	// Explicit days and "now" ignore the reference day.
+	got, err = timeparse.TimeOn("yesterday 09:15", day, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := at(26, 9, 15); !got.Equal(want) {
+		t.Errorf("TimeOn(yesterday) = %v, want %v", got, want)
+	}
+}
+
+func TestDay(t *testing.T) {
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{"today", at(27, 0, 0)},
+		{"Yesterday", at(26, 0, 0)},
+		{"fri", at(27, 0, 0)},
+		{"last friday", at(20, 0, 0)},
+		{"sun", at(22, 0, 0)},
+		{"2026-02-01", at(1, 0, 0)},
+	}
+	for _, tt := range tests {
+		got, err := timeparse.Day(tt.input, now)
+		if err != nil {
+			t.Errorf("Day(%q): %v", tt.input, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("Day(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+
+	if _, err := timeparse.Day("yesterday 14:30", now); err == nil {
+		t.Error("Day with clock: expected error, got nil")
+	}
+}
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		input string
+		want  time.Duration
+	}{
+		{"1h30m", 90 * time.Minute},
+		{"90m", 90 * time.Minute},
+		{"1.5h", 90 * time.Minute},
+		{"1.5", 90 * time.Minute},
+		{"2", 2 * time.Hour},
+		{"1:30", 90 * time.Minute},
+		{"1h 30m", 90 * time.Minute},
+		{"30 min", 30 * time.Minute},
+		{"2 hours", 2 * time.Hour},
+		{"45s", 45 * time.Second},
+		{"30d", 30 * 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		got, err := timeparse.Duration(tt.input)
+		if err != nil {
+			t.Errorf("Duration(%q): %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Duration(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{"", "abc", "10 parsecs", "-1", "1h-", "1e1", "inf", "nan", "+2", ".5", "0x10"} {
+		if got, err := timeparse.Duration(input); err == nil {
+			t.Errorf("Duration(%q) = %v, want error", input, got)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Package timesheet compares the time worked per day with a daily target
+// derived from the contracted weekly hours and keeps the overtime balance.
+package timesheet
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timecalc"
+)
+
+// Targets holds the target in seconds per weekday, indexed by time.Weekday.
+type Targets [7]int64
+
+// weekdayNames are the short weekday names used in the config, indexed by
+// time.Weekday.
+var weekdayNames = [7]string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// ParseWeekday accepts a short or full English weekday name in any case,
+// e.g. "mon" or "Monday".
+func ParseWeekday(s string) (time.Weekday, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if name == weekdayNames[d] || name == strings.ToLower(d.String()) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown weekday %q: expected one of %s", s, strings.Join(weekdayNames[:], ", "))
+}
+
+// NewTargets derives the daily targets from the weekly hours. Overrides fix
+// the target of single weekdays, which need not be working days; the hours
+// left after them are split evenly across the other working days.
+func NewTargets(weeklyHours float64, workingDays []string, overrides map[string]float64) (Targets, error) {
+	var t Targets
+	if weeklyHours < 0 {
+		return t, fmt.Errorf("weekly hours must not be negative, got %g", weeklyHours)
+	}
+
+	var fixed [7]bool
+	remaining := weeklyHours
+	for name, hours := range overrides {
+		d, err := ParseWeekday(name)
+		if err != nil {
+			return t, err
+		}
+		if hours < 0 || hours > 24 {
+			return t, fmt.Errorf("override for %s must be between 0 and 24 hours, got %g", name, hours)
+		}
+		fixed[d] = true
+		t[d] = seconds(hours)
+		remaining -= hours
+	}
+	if remaining < -1e-9 {
+		return t, fmt.Errorf("overrides add up to more than the %g weekly hours", weeklyHours)
+	}
+
+	var split []time.Weekday
+	seen := map[time.Weekday]bool{}
+	for _, name := range workingDays {
+		d, err := ParseWeekday(name)
+		if err != nil {
+			return t, err
+		}
+		if !fixed[d] && !seen[d] {
+			split = append(split, d)
+		}
+		seen[d] = true
+	}
+	if len(split) == 0 {
+		if remaining > 1e-9 {
+			return t, fmt.Errorf("%g of the weekly hours are left for no working day", remaining)
+		}
+		return t, nil
+	}
+	for _, d := range split {
+		t[d] = seconds(remaining / float64(len(split)))
+	}
+	return t, nil
+}
+
+func seconds(hours float64) int64 {
+	return int64(math.Round(hours * 3600))
+}
+
+// Flag marks a day that needs attention.
+type Flag string
+
+const (
+	// NoEntries marks a past day with a target but nothing tracked.
+	NoEntries Flag = "no_entries"
+	// DayOff marks a day without a target on which time was tracked.
+	DayOff Flag = "day_off"
+	// Running marks the day of the running timer, whose time counts until
+	// now.
+	Running Flag = "running"
+	// Upcoming marks a day after today. It is listed with its target but
+	// left out of the balance.
+	Upcoming Flag = "upcoming"
+)
+
+// Day is one row of a timesheet. Durations are in seconds.
+type Day struct {
+	Date   time.Time
+	Worked int64
+	Target int64
+	// Balance is Worked minus Target, the overtime of the day.
+	Balance int64
+	// Running is the balance of the sheet up to and including this day.
+	Running int64
+	Entries int
+	Flags   []Flag
+}
+
+// Sheet is the timesheet of a range of days. Its totals leave out upcoming
+// days.
+type Sheet struct {
+	Days    []Day
+	Worked  int64
+	Target  int64
+	Balance int64
+}
+
+// Build lists every day from from to to with the entries stored under it.
+// Stopped entries count with their duration; the running one counts until
+// now.
+func Build(entries []model.Entry, from, to time.Time, targets Targets, now time.Time) Sheet {
+	// Key by date: stored times may carry another location than from.
+	byDay := map[string][]model.Entry{}
+	for _, e := range entries {
+		d := e.Start.Format("2006-01-02")
+		byDay[d] = append(byDay[d], e)
+	}
+
+	var s Sheet
+	today := timecalc.StartOfDay(now)
+	for d := timecalc.StartOfDay(from); !d.After(to); d = d.AddDate(0, 0, 1) {
+		key := d.Format("2006-01-02")
+		day := Day{Date: d, Target: targets[d.Weekday()], Entries: len(byDay[key])}
+		for _, e := range byDay[key] {
+			switch {
+			case e.End == nil:
+				day.Worked += model.WorkedSeconds(e.Start, now, e.Breaks)
+				day.Flags = append(day.Flags, Running)
+			case e.DurationSeconds != nil:
+				day.Worked += *e.DurationSeconds
+			}
+		}
+
+		if d.After(today) && day.Entries == 0 {
+			day.Flags = append(day.Flags, Upcoming)
+			day.Running = s.Balance
+			s.Days = append(s.Days, day)
+			continue
+		}
+		switch {
+		case day.Entries == 0 && day.Target > 0 && d.Before(today):
+			day.Flags = append(day.Flags, NoEntries)
+		case day.Entries > 0 && day.Target == 0:
+			day.Flags = append(day.Flags, DayOff)
+		}
+		day.Balance = day.Worked - day.Target
+		s.Worked += day.Worked
+		s.Target += day.Target
+		s.Balance += day.Balance
+		day.Running = s.Balance
+		s.Days = append(s.Days, day)
+	}
+	return s
+}
@@ -0,0 +1,121 @@
+package timesheet_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/model"
+	"github.com/Tiliavir/trivial-time-tracker/internal/timesheet"
+)
+
+var weekdays = []string{"mon", "tue", "wed", "thu", "fri"}
+
+// monday is the first day of the week used in the tests.
+var monday = time.Date(2026, 2, 23, 0, 0, 0, 0, time.Local)
+
+// at returns the time h:m on the given day of the test week, 0 being Monday.
+func at(day, h, m int) time.Time {
+	return monday.AddDate(0, 0, day).Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute)
+}
+
+func entry(from, to time.Time) model.Entry {
+	dur := int64(to.Sub(from).Seconds())
+	return model.Entry{Project: "P", Start: from, End: &to, DurationSeconds: &dur}
+}
+
+const h = int64(3600)
+
+func TestNewTargets(t *testing.T) {
+	tests := []struct {
+		name      string
+		weekly    float64
+		days      []string
+		overrides map[string]float64
+		want      timesheet.Targets
+	}{
+		{"even split", 40, weekdays, nil, timesheet.Targets{0, 8 * h, 8 * h, 8 * h, 8 * h, 8 * h, 0}},
+		{"short friday", 38, weekdays, map[string]float64{"Fri": 6}, timesheet.Targets{0, 8 * h, 8 * h, 8 * h, 8 * h, 6 * h, 0}},
+		{"override on a day off", 40, weekdays, map[string]float64{"saturday": 5}, timesheet.Targets{0, 7 * h, 7 * h, 7 * h, 7 * h, 7 * h, 5 * h}},
+		{"part time", 20, []string{"mon", "Wednesday", "mon"}, nil, timesheet.Targets{0, 10 * h, 0, 10 * h, 0, 0, 0}},
+		{"uneven split", 40, []string{"mon", "tue", "wed"}, nil, timesheet.Targets{0, 48000, 48000, 48000, 0, 0, 0}},
+		{"no target", 0, nil, nil, timesheet.Targets{}},
+	}
+	for _, tt := range tests {
+		got, err := timesheet.NewTargets(tt.weekly, tt.days, tt.overrides)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: targets = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNewTargetsErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		weekly    float64
+		days      []string
+		overrides map[string]float64
+	}{
+		{"unknown day", 40, []string{"mon", "funday"}, nil},
+		{"unknown override", 40, weekdays, map[string]float64{"someday": 4}},
+		{"negative weekly", -1, weekdays, nil},
+		{"negative override", 40, weekdays, map[string]float64{"fri": -2}},
+		{"overrides exceed week", 10, weekdays, map[string]float64{"mon": 8, "tue": 8}},
+		{"hours without days", 40, nil, nil},
+	}
+	for _, tt := range tests {
+		if _, err := timesheet.NewTargets(tt.weekly, tt.days, tt.overrides); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	targets, err := timesheet.NewTargets(40, weekdays, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bEnd := at(3, 12, 30)
+	running := model.Entry{Project: "P", Start: at(3, 9, 0), Breaks: []model.Break{{Start: at(3, 12, 0), End: &bEnd}}}
+	entries := []model.Entry{
+		entry(at(0, 8, 0), at(0, 12, 0)),
+		entry(at(0, 13, 0), at(0, 18, 0)), // Monday: 9h
+		// Tuesday: nothing
+		entry(at(2, 9, 0), at(2, 15, 0)),  // Wednesday: 6h
+		running,                           // Thursday: 9:00 to 14:00 with a 30m break
+		entry(at(5, 10, 0), at(5, 11, 0)), // Saturday: a day off, tracked ahead of time
+	}
+	now := at(3, 14, 0)
+	s := timesheet.Build(entries, monday, at(6, 23, 59), targets, now)
+
+	want := []struct {
+		worked, balance, running int64
+		flags                    string
+	}{
+		{9 * h, 1 * h, 1 * h, "[]"},
+		{0, -8 * h, -7 * h, "[no_entries]"},
+		{6 * h, -2 * h, -9 * h, "[]"},
+		{4*h + h/2, -3*h - h/2, -12*h - h/2, "[running]"},
+		{0, 0, -12*h - h/2, "[upcoming]"},
+		{1 * h, 1 * h, -11*h - h/2, "[day_off]"},
+		{0, 0, -11*h - h/2, "[upcoming]"},
+	}
+	if len(s.Days) != len(want) {
+		t.Fatalf("got %d days, want %d", len(s.Days), len(want))
+	}
+	for i, w := range want {
+		d := s.Days[i]
+		if d.Worked != w.worked || d.Balance != w.balance || d.Running != w.running || fmt.Sprint(d.Flags) != w.flags {
+			t.Errorf("%s: worked %d, balance %d, running %d, flags %v; want %d, %d, %d, %s",
+				d.Date.Format("Mon"), d.Worked, d.Balance, d.Running, d.Flags, w.worked, w.balance, w.running, w.flags)
+		}
+	}
+	// Friday and Sunday are upcoming and count towards no total.
+	if s.Worked != 20*h+h/2 || s.Target != 32*h || s.Balance != -11*h-h/2 {
+		t.Errorf("totals: worked %d, target %d, balance %d", s.Worked, s.Target, s.Balance)
+	}
+}
@@ -0,0 +1,219 @@
+// Package workspace locates the data directory and config file of ttt. Each
+// named workspace has its own data tree and config, so unrelated tracking
+// (e.g. client work and personal projects) never mixes.
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Default is the name of the workspace stored directly in the root
+// directories, which is the only one that exists until others are created.
+const Default = "default"
+
+// Environment variables consulted by DefaultRoots and Select.
+const (
+	// EnvHome overrides the data directory, like the --data-dir flag.
+	EnvHome = "TTT_HOME"
+	// EnvWorkspace selects a workspace, like the --workspace flag.
+	EnvWorkspace = "TTT_WORKSPACE"
+)
+
+// Workspace is the location of one workspace.
+type Workspace struct {
+	Name string
+	// DataDir holds the day files, the trash, the project registry and the
+	// search index.
+	DataDir string
+	// ConfigFile is the path of its config.json.
+	ConfigFile string
+}
+
+// Roots are the directories holding the default workspace. Named workspaces
+// live below them in workspaces/<name>.
+type Roots struct {
+	Data   string
+	Config string
+}
+
+// DefaultRoots returns the directories ttt uses. The first of these wins:
+//
+//   - dataDir, the value of --data-dir, and then $TTT_HOME: data and config
+//     both live in that directory
+//   - ~/.ttt, when it exists
+//   - $XDG_DATA_HOME/ttt and $XDG_CONFIG_HOME/ttt, each when set
+//   - ~/.ttt
+func DefaultRoots(dataDir string) (Roots, error) {
+	if dataDir == "" {
+		dataDir = os.Getenv(EnvHome)
+	}
+	if dataDir != "" {
+		abs, err := filepath.Abs(dataDir)
+		if err != nil {
+			return Roots{}, fmt.Errorf("invalid data directory %s: %w", dataDir, err)
+		}
+		return Roots{Data: abs, Config: abs}, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return Roots{}, fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	legacy := filepath.Join(home, ".ttt")
+	if _, err := os.Stat(legacy); err == nil {
+		return Roots{Data: legacy, Config: legacy}, nil
+	}
+
+	r := Roots{Data: legacy, Config: legacy}
+	if xdg := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(xdg) {
+		r.Data = filepath.Join(xdg, "ttt")
+	}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(xdg) {
+		r.Config = filepath.Join(xdg, "ttt")
+	}
+	return r, nil
+}
+
+// validName matches workspace names, which become directory names.
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// CheckName reports whether name can be used for a new workspace.
+func CheckName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid workspace name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	if strings.EqualFold(name, Default) {
+		return fmt.Errorf("%q is the built-in workspace", Default)
+	}
+	return nil
+}
+
+// activeFile returns the file recording the workspace chosen with Use.
+func (r Roots) activeFile() string {
+	return filepath.Join(r.Config, "active_workspace")
+}
+
+// workspacesDir returns the directory holding the data of named workspaces.
+func (r Roots) workspacesDir() string {
+	return filepath.Join(r.Data, "workspaces")
+}
+
+// location returns the paths of the named workspace without checking that
+// it exists.
+func (r Roots) location(name string) Workspace {
+	if name == Default {
+		return Workspace{Name: Default, DataDir: r.Data, ConfigFile: filepath.Join(r.Config, "config.json")}
+	}
+	return Workspace{
+		Name:       name,
+		DataDir:    filepath.Join(r.workspacesDir(), name),
+		ConfigFile: filepath.Join(r.Config, "workspaces", name, "config.json"),
+	}
+}
+
+// Active returns the name of the workspace chosen with Use, or Default.
+func (r Roots) Active() string {
+	data, err := os.ReadFile(r.activeFile())
+	if err != nil {
+		return Default
+	}
+	if name := strings.TrimSpace(string(data)); name != "" {
+		return name
+	}
+	return Default
+}
+
+// Selected returns the name of the workspace to use: name, the value of
+// --workspace, if given, then $TTT_WORKSPACE, then the one chosen with Use.
+func (r Roots) Selected(name string) string {
+	if name == "" {
+		name = os.Getenv(EnvWorkspace)
+	}
+	if name == "" {
+		name = r.Active()
+	}
+	return name
+}
+
+// Select opens the workspace named by Selected. Named workspaces must have
+// been created first.
+func (r Roots) Select(name string) (Workspace, error) {
+	return r.Open(r.Selected(name))
+}
+
+// Open returns an existing workspace.
+func (r Roots) Open(name string) (Workspace, error) {
+	if name == Default {
+		return r.location(Default), nil
+	}
+	if err := CheckName(name); err != nil {
+		return Workspace{}, err
+	}
+	w := r.location(name)
+	if _, err := os.Stat(w.DataDir); err != nil {
+		return Workspace{}, fmt.Errorf("workspace %q does not exist; create it with: ttt workspace create %s", name, name)
+	}
+	return w, nil
+}
+
+// Create makes the data directory of a new workspace. Its config file is
+// written with defaults on first use.
+func (r Roots) Create(name string) (Workspace, error) {
+	if err := CheckName(name); err != nil {
+		return Workspace{}, err
+	}
+	w := r.location(name)
+	if _, err := os.Stat(w.DataDir); err == nil {
+		return Workspace{}, fmt.Errorf("workspace %q already exists", name)
+	}
+	if err := os.MkdirAll(w.DataDir, 0o700); err != nil {
+		return Workspace{}, fmt.Errorf("creating workspace %q: %w", name, err)
+	}
+	return w, nil
+}
+
+// Use makes name the workspace selected when neither --workspace nor
+// $TTT_WORKSPACE is given.
+func (r Roots) Use(name string) error {
+	if _, err := r.Open(name); err != nil {
+		return err
+	}
+	if name == Default {
+		if err := os.Remove(r.activeFile()); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("resetting workspace: %w", err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(r.Config, 0o700); err != nil {
+		return fmt.Errorf("saving workspace: %w", err)
+	}
+	if err := os.WriteFile(r.activeFile(), []byte(name+"\n"), 0o600); err != nil {
+		return fmt.Errorf("saving workspace: %w", err)
+	}
+	return nil
+}
+
+// List returns the names of all workspaces, Default first.
+func (r Roots) List() ([]string, error) {
+	names := []string{Default}
+	dirs, err := os.ReadDir(r.workspacesDir())
+	if os.IsNotExist(err) {
+		return names, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading workspaces: %w", err)
+	}
+	var named []string
+	for _, d := range dirs {
+		if d.IsDir() && CheckName(d.Name()) == nil {
+			named = append(named, d.Name())
+		}
+	}
+	sort.Strings(named)
+	return append(names, named...), nil
+}
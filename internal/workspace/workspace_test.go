@@ -0,0 +1,93 @@
+package workspace_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Tiliavir/trivial-time-tracker/internal/workspace"
+)
+
+func TestDefaultRoots(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(workspace.EnvHome, "")
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, "data"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "config"))
+
+	r, err := workspace.DefaultRoots("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := workspace.Roots{Data: filepath.Join(home, "data", "ttt"), Config: filepath.Join(home, "config", "ttt")}
+	if r != want {
+		t.Errorf("with XDG: %+v, want %+v", r, want)
+	}
+
+	legacy := filepath.Join(home, ".ttt")
+	if err := os.Mkdir(legacy, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if r, _ := workspace.DefaultRoots(""); r != (workspace.Roots{Data: legacy, Config: legacy}) {
+		t.Errorf("with existing ~/.ttt: %+v, want it for both", r)
+	}
+
+	env := filepath.Join(home, "env")
+	t.Setenv(workspace.EnvHome, env)
+	if r, _ := workspace.DefaultRoots(""); r != (workspace.Roots{Data: env, Config: env}) {
+		t.Errorf("with $%s: %+v, want %s for both", workspace.EnvHome, r, env)
+	}
+	flag := filepath.Join(home, "flag")
+	if r, _ := workspace.DefaultRoots(flag); r != (workspace.Roots{Data: flag, Config: flag}) {
+		t.Errorf("with --data-dir: %+v, want %s for both", r, flag)
+	}
+}
+
+func TestWorkspaces(t *testing.T) {
+	t.Setenv(workspace.EnvWorkspace, "")
+	dir := t.TempDir()
+	r := workspace.Roots{Data: filepath.Join(dir, "data"), Config: filepath.Join(dir, "config")}
+
+	w, err := r.Select("")
+	if err != nil || w.Name != workspace.Default || w.DataDir != r.Data {
+		t.Fatalf("Select() = %+v, %v; want the default workspace", w, err)
+	}
+	if _, err := r.Select("acme"); err == nil {
+		t.Error("Select(acme) before creating it succeeded")
+	}
+	for _, bad := range []string{"", "default", "../x", "a/b"} {
+		if _, err := r.Create(bad); err == nil {
+			t.Errorf("Create(%q) succeeded", bad)
+		}
+	}
+
+	if _, err := r.Create("acme"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Create("acme"); err == nil {
+		t.Error("second Create(acme) succeeded")
+	}
+	if err := r.Use("acme"); err != nil {
+		t.Fatal(err)
+	}
+	w, err = r.Select("")
+	if err != nil || w.DataDir != filepath.Join(r.Data, "workspaces", "acme") ||
+		w.ConfigFile != filepath.Join(r.Config, "workspaces", "acme", "config.json") {
+		t.Errorf("Select() after Use(acme) = %+v, %v", w, err)
+	}
+	if w, _ := r.Select(workspace.Default); w.Name != workspace.Default {
+		t.Errorf("Select(default) = %+v, want the default workspace", w)
+	}
+	t.Setenv(workspace.EnvWorkspace, workspace.Default)
+	if got := r.Selected(""); got != workspace.Default {
+		t.Errorf("Selected with $%s=default = %q", workspace.EnvWorkspace, got)
+	}
+
+	if names, err := r.List(); err != nil || !reflect.DeepEqual(names, []string{"default", "acme"}) {
+		t.Errorf("List() = %v, %v", names, err)
+	}
+	if err := r.Use(workspace.Default); err != nil || r.Active() != workspace.Default {
+		t.Errorf("Use(default): %v, active %q", err, r.Active())
+	}
+}